@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+/*
+	When an archive category is configured, "!endround" archives the race channels instead of
+	deleting them. The message log of each channel is saved to the database, the channel is moved to
+	the archive category with read-only permissions, and the race is marked as archived (instead of
+	being deleted along with its casts).
+*/
+
+var (
+	// The Discord category that race channels are moved to by "!endround". (Blank means that the
+	// channels are deleted instead.)
+	archiveCategoryID string
+)
+
+const (
+	// The most messages that Discord will return at once.
+	archiveMessagesPerPage = 100
+)
+
+func archiveInit() {
+	if v, err := archiveRead(); err != nil {
+		log.Fatal(err.Error())
+		return
+	} else {
+		archiveCategoryID = v
+	}
+}
+
+// Read the archive category from the environment variables and make sure that it exists.
+func archiveRead() (string, error) {
+	categoryID := strings.TrimSpace(os.Getenv("DISCORD_ARCHIVE_CATEGORY_ID"))
+	if categoryID != "" {
+		if channel, err := discordSession.Channel(categoryID); err != nil {
+			return "", errors.New("Failed to find the \"DISCORD_ARCHIVE_CATEGORY_ID\" category of \"" + categoryID + "\": " + err.Error())
+		} else if channel.Type != discordgo.ChannelTypeGuildCategory {
+			return "", errors.New("The \"DISCORD_ARCHIVE_CATEGORY_ID\" environment variable of \"" + categoryID + "\" is not a category.")
+		}
+	}
+
+	return categoryID, nil
+}
+
+// Save the message log of the race channel, move it to the archive category, and mark the race as
+// archived. Returns the number of messages that were saved.
+func archiveRaceChannel(channel *discordgo.Channel, race *Race) (int, error) {
+	var messages []*RaceMessage
+	if v, err := archiveGetMessages(channel.ID); err != nil {
+		return 0, errors.New("Failed to get the messages: " + err.Error())
+	} else {
+		messages = v
+	}
+	if err := modals.RaceMessages.Insert(channel.ID, messages); err != nil {
+		return 0, errors.New("Failed to save the messages to the database: " + err.Error())
+	}
+
+	// Everyone can read the channel, but nobody except for the bot can talk in it anymore.
+	// (The racers and the casters are listed explicitly so that they can still find it if the
+	// archive category is hidden.)
+	permissionsRead := int64(discordgo.PermissionViewChannel |
+		discordgo.PermissionReadMessageHistory)
+	permissionsWrite := int64(discordgo.PermissionSendMessages |
+		discordgo.PermissionAddReactions)
+	permissions := []*discordgo.PermissionOverwrite{
+		{
+			ID:   discordEveryoneRoleID,
+			Type: discordgo.PermissionOverwriteTypeRole,
+			Deny: permissionsWrite,
+		},
+		{
+			ID:    discordBotRoleID,
+			Type:  discordgo.PermissionOverwriteTypeRole,
+			Allow: permissionsRead | permissionsWrite,
+		},
+		{
+			ID:    discordCasterRoleID,
+			Type:  discordgo.PermissionOverwriteTypeRole,
+			Allow: permissionsRead,
+			Deny:  permissionsWrite,
+		},
+	}
+	for _, racer := range []*User{race.Racer1, race.Racer2} {
+		if racer == nil {
+			continue
+		}
+		permissions = append(permissions, &discordgo.PermissionOverwrite{
+			ID:    racer.DiscordID,
+			Type:  discordgo.PermissionOverwriteTypeMember,
+			Allow: permissionsRead,
+			Deny:  permissionsWrite,
+		})
+	}
+	if _, err := discordSession.ChannelEditComplex(channel.ID, &discordgo.ChannelEdit{
+		PermissionOverwrites: permissions,
+		ParentID:             archiveCategoryID,
+	}); err != nil {
+		return 0, errors.New("Failed to move the channel to the archive category: " + err.Error())
+	}
+
+	if err := modals.Races.SetArchived(channel.ID); err != nil {
+		return 0, errors.New("Failed to set the race as archived in the database: " + err.Error())
+	}
+
+	return len(messages), nil
+}
+
+// Page through the whole history of the channel, from the newest message to the oldest.
+func archiveGetMessages(channelID string) ([]*RaceMessage, error) {
+	messages := make([]*RaceMessage, 0)
+	beforeID := ""
+	for {
+		var page []*discordgo.Message
+		if v, err := discordSession.ChannelMessages(channelID, archiveMessagesPerPage, beforeID, "", ""); err != nil {
+			return nil, err
+		} else {
+			page = v
+		}
+
+		for _, message := range page {
+			messages = append(messages, archiveGetRaceMessage(message))
+		}
+		if len(page) < archiveMessagesPerPage {
+			break
+		}
+		beforeID = page[len(page)-1].ID
+	}
+
+	return messages, nil
+}
+
+func archiveGetRaceMessage(message *discordgo.Message) *RaceMessage {
+	raceMessage := &RaceMessage{
+		MessageID: message.ID,
+		Content:   message.Content,
+	}
+	if message.Author != nil {
+		raceMessage.AuthorDiscordID = message.Author.ID
+		raceMessage.AuthorUsername = message.Author.Username
+	}
+
+	attachmentURLs := make([]string, 0)
+	for _, attachment := range message.Attachments {
+		attachmentURLs = append(attachmentURLs, attachment.URL)
+	}
+	raceMessage.Attachments = strings.Join(attachmentURLs, "\n")
+
+	// Messages without a valid timestamp are saved with the time that they were archived.
+	if v, err := message.Timestamp.Parse(); err != nil {
+		raceMessage.DatetimeSent = time.Now()
+	} else {
+		raceMessage.DatetimeSent = v
+	}
+
+	return raceMessage
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BracketProvider is the website (or file) that holds the brackets for the tournaments. The bot
+// reads the open matches from it and reports the scores back to it.
+type BracketProvider interface {
+	// Get every tournament that the provider knows about.
+	GetTournaments() ([]*BracketTournament, error)
+
+	// Get the matches that are ready to be played, with the participant names filled in.
+	GetOpenMatches(tournament Tournament) ([]*BracketMatch, error)
+
+	// Report the score of a match. The score is in the format of "#-#", with the wins of player 1
+	// first. The winner is the participant ID of the player that won the match.
+	ReportScore(tournament Tournament, matchID string, score string, winnerID float64) error
+
+	// Get the participants of a tournament, from the first seed to the last.
+	GetParticipants(tournament Tournament) ([]*BracketParticipant, error)
+
+	// Set the seeds of a tournament. The participant IDs are in order, starting with the first
+	// seed. This can only be done before the tournament has started.
+	SetSeeds(tournament Tournament, participantIDs []float64) error
+}
+
+type BracketTournament struct {
+	ID   float64
+	URL  string // The suffix of the URL for this tournament.
+	Name string
+}
+
+type BracketParticipant struct {
+	ID        float64 // The "participant" ID.
+	Name      string
+	Seed      int // Starting at 1.
+	FinalRank int // Starting at 1, or 0 if the tournament is not over yet.
+}
+
+type BracketMatch struct {
+	ID          string
+	Round       string // Negative for the losers bracket of a double elimination tournament.
+	GroupID     float64
+	Group       string  // The letter of the group, e.g. "A". (Blank if it is not in a group stage.)
+	Identifier  string  // The letter(s) of the match on the bracket, e.g. "A" or "AB".
+	Player1ID   float64 // The "participant" ID. (In a group stage, this is the group player ID.)
+	Player1Name string
+	Player2ID   float64 // The "participant" ID. (In a group stage, this is the group player ID.)
+	Player2Name string
+}
+
+var (
+	bracketProvider BracketProvider
+)
+
+func bracketInit() {
+	// Read the bracket configuration from the environment variables.
+	// (It defaults to Challonge, since that was the only provider before there was a choice.)
+	providerName := os.Getenv("BRACKET_PROVIDER")
+	if len(providerName) == 0 {
+		providerName = "challonge"
+	}
+
+	if providerName == "challonge" {
+		challongeInit()
+		bracketProvider = &ChallongeProvider{}
+	} else if providerName == "local" {
+		localBracketInit()
+		bracketProvider = &LocalProvider{}
+	} else {
+		log.Fatal("The \"BRACKET_PROVIDER\" environment variable is set to \"" + providerName + "\", which is an invalid value.")
+		return
+	}
+
+	tournamentInit()
+}
+
+// Get the part of the bracket that a match is in, e.g. "Group A", "Losers Round 2", or "Round 3".
+func bracketGetStage(round string, group string) string {
+	if group != "" {
+		return "Group " + group
+	}
+	if roundNum, err := strconv.Atoi(round); err == nil && roundNum < 0 {
+		return "Losers Round " + strconv.Itoa(-roundNum)
+	}
+
+	return "Round " + round
+}
+
+// Get the prefix for the channel names of the matches in a group or in the losers bracket, so that
+// the channels are sorted by stage, e.g. "group-a-" or "losers-".
+func bracketGetChannelPrefix(round string, group string) string {
+	if group != "" {
+		return "group-" + strings.ToLower(group) + "-"
+	}
+	if roundNum, err := strconv.Atoi(round); err == nil && roundNum < 0 {
+		return "losers-"
+	}
+
+	return ""
+}
+
+// Get the name for a tournament category from the stages of its open matches, e.g.
+// "Round 3 - seeded", "Group Stage - seeded", or "Round 3, Losers Round 2 - seeded".
+func bracketGetCategoryName(stages []string, ruleset Ruleset) string {
+	allGroups := len(stages) > 1
+	for _, stage := range stages {
+		if !strings.HasPrefix(stage, "Group ") {
+			allGroups = false
+			break
+		}
+	}
+
+	name := strings.Join(stages, ", ")
+	if allGroups {
+		name = "Group Stage"
+	}
+
+	// Discord category names are limited to 100 characters.
+	suffix := " - " + string(ruleset)
+	if len(name)+len(suffix) > 100 {
+		name = stages[0] + " and more"
+	}
+
+	return name + suffix
+}
+
+// Groups are lettered in the order of their IDs, like they are on Challonge.
+func bracketGetGroupLetters(groupIDs []float64) map[float64]string {
+	sortedIDs := make([]float64, 0)
+	for _, groupID := range groupIDs {
+		found := false
+		for _, sortedID := range sortedIDs {
+			if sortedID == groupID {
+				found = true
+				break
+			}
+		}
+		if !found && groupID != 0 {
+			sortedIDs = append(sortedIDs, groupID)
+		}
+	}
+	sort.Float64s(sortedIDs)
+
+	letters := make(map[float64]string)
+	for i, groupID := range sortedIDs {
+		letter := ""
+		for n := i; n >= 0; n = n/26 - 1 {
+			letter = string(rune('A'+n%26)) + letter
+		}
+		letters[groupID] = letter
+	}
+
+	return letters
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+)
+
+// LocalProvider implements the BracketProvider interface with a JSON file on disk. This is useful
+// for events that are run from a spreadsheet or a website that does not have an API. Admins
+// update the file by hand when a new match opens up, and the bot writes the scores back to it.
+type LocalProvider struct{}
+
+// The type of element in the array in the local bracket file.
+type LocalTournament struct {
+	ID           float64            `json:"id"`
+	URL          string             `json:"url"`
+	Name         string             `json:"name"`
+	Participants []LocalParticipant `json:"participants"`
+	Matches      []LocalMatch       `json:"matches"`
+}
+
+// The participants are listed in the order of their seeds.
+type LocalParticipant struct {
+	ID        float64 `json:"id"`
+	Name      string  `json:"name"`
+	FinalRank int     `json:"final_rank,omitempty"` // Filled in by hand once the tournament is over.
+}
+
+type LocalMatch struct {
+	ID         float64 `json:"id"`
+	Round      int     `json:"round"`                // Negative for the losers bracket, like on Challonge.
+	GroupID    float64 `json:"group_id,omitempty"`   // Only for the matches in a group stage.
+	Identifier string  `json:"identifier,omitempty"` // e.g. "A" or "AB"
+	State      string  `json:"state"`                // Either "pending", "open", or "complete", like on Challonge.
+	Player1ID  float64 `json:"player1_id"`
+	Player2ID  float64 `json:"player2_id"`
+	ScoresCSV  string  `json:"scores_csv"`
+	WinnerID   float64 `json:"winner_id"`
+}
+
+var (
+	localBracketPath  string
+	localBracketMutex = new(sync.Mutex)
+)
+
+func localBracketInit() {
+	// Read the local bracket configuration from the environment variables.
+	localBracketPath = os.Getenv("BRACKET_LOCAL_PATH")
+	if len(localBracketPath) == 0 {
+		localBracketPath = path.Join(projectPath, "brackets.json")
+	}
+
+	if _, err := os.Stat(localBracketPath); os.IsNotExist(err) {
+		log.Fatal("The local bracket file at \"" + localBracketPath + "\" does not exist.")
+	} else if err != nil {
+		log.Fatal("Failed to check if the \""+localBracketPath+"\" file exists:", err)
+	}
+}
+
+func (*LocalProvider) GetTournaments() ([]*BracketTournament, error) {
+	var localTournaments []*LocalTournament
+	if v, err := localBracketRead(); err != nil {
+		return nil, err
+	} else {
+		localTournaments = v
+	}
+
+	bracketTournaments := make([]*BracketTournament, 0)
+	for _, localTournament := range localTournaments {
+		bracketTournaments = append(bracketTournaments, &BracketTournament{
+			ID:   localTournament.ID,
+			URL:  localTournament.URL,
+			Name: localTournament.Name,
+		})
+	}
+
+	return bracketTournaments, nil
+}
+
+func (*LocalProvider) GetOpenMatches(tournament Tournament) ([]*BracketMatch, error) {
+	var localTournament *LocalTournament
+	if _, v, err := localBracketGetTournament(tournament); err != nil {
+		return nil, err
+	} else {
+		localTournament = v
+	}
+
+	groupIDs := make([]float64, 0)
+	for _, match := range localTournament.Matches {
+		groupIDs = append(groupIDs, match.GroupID)
+	}
+	groupLetters := bracketGetGroupLetters(groupIDs)
+
+	matches := make([]*BracketMatch, 0)
+	for _, match := range localTournament.Matches {
+		if match.State != "open" {
+			continue
+		}
+
+		matches = append(matches, &BracketMatch{
+			ID:          floatToString(match.ID),
+			Round:       strconv.Itoa(match.Round),
+			GroupID:     match.GroupID,
+			Group:       groupLetters[match.GroupID],
+			Identifier:  match.Identifier,
+			Player1ID:   match.Player1ID,
+			Player1Name: localTournament.getParticipantName(match.Player1ID),
+			Player2ID:   match.Player2ID,
+			Player2Name: localTournament.getParticipantName(match.Player2ID),
+		})
+	}
+
+	return matches, nil
+}
+
+func (*LocalProvider) ReportScore(tournament Tournament, matchID string, score string, winnerID float64) error {
+	localBracketMutex.Lock()
+	defer localBracketMutex.Unlock()
+
+	var localTournaments []*LocalTournament
+	var localTournament *LocalTournament
+	if v1, v2, err := localBracketGetTournament(tournament); err != nil {
+		return err
+	} else {
+		localTournaments = v1
+		localTournament = v2
+	}
+
+	found := false
+	for i, match := range localTournament.Matches {
+		if floatToString(match.ID) == matchID {
+			found = true
+			localTournament.Matches[i].State = "complete"
+			localTournament.Matches[i].ScoresCSV = score
+			localTournament.Matches[i].WinnerID = winnerID
+			break
+		}
+	}
+	if !found {
+		return errors.New("Failed to find match \"" + matchID + "\" in the local bracket for tournament \"" + tournament.Name + "\".")
+	}
+
+	return localBracketWrite(localTournaments)
+}
+
+func (*LocalProvider) GetParticipants(tournament Tournament) ([]*BracketParticipant, error) {
+	var localTournament *LocalTournament
+	if _, v, err := localBracketGetTournament(tournament); err != nil {
+		return nil, err
+	} else {
+		localTournament = v
+	}
+
+	participants := make([]*BracketParticipant, 0)
+	for i, participant := range localTournament.Participants {
+		participants = append(participants, &BracketParticipant{
+			ID:        participant.ID,
+			Name:      participant.Name,
+			Seed:      i + 1,
+			FinalRank: participant.FinalRank,
+		})
+	}
+
+	return participants, nil
+}
+
+func (*LocalProvider) SetSeeds(tournament Tournament, participantIDs []float64) error {
+	localBracketMutex.Lock()
+	defer localBracketMutex.Unlock()
+
+	var localTournaments []*LocalTournament
+	var localTournament *LocalTournament
+	if v1, v2, err := localBracketGetTournament(tournament); err != nil {
+		return err
+	} else {
+		localTournaments = v1
+		localTournament = v2
+	}
+
+	if participants, err := localTournament.getSeededParticipants(participantIDs); err != nil {
+		return err
+	} else {
+		localTournament.Participants = participants
+	}
+
+	return localBracketWrite(localTournaments)
+}
+
+// Get the participants in the order of the provided IDs. Like on Challonge, the seeds cannot be
+// changed once a match has been completed.
+func (localTournament *LocalTournament) getSeededParticipants(participantIDs []float64) ([]LocalParticipant, error) {
+	for _, match := range localTournament.Matches {
+		if match.State == "complete" {
+			return nil, errors.New("The seeds of tournament \"" + localTournament.Name + "\" cannot be changed, since it has already started.")
+		}
+	}
+
+	if len(participantIDs) != len(localTournament.Participants) {
+		return nil, errors.New("There are " + strconv.Itoa(len(localTournament.Participants)) + " participants in tournament \"" + localTournament.Name + "\", but " + strconv.Itoa(len(participantIDs)) + " seeds were provided.")
+	}
+
+	participants := make([]LocalParticipant, 0)
+	for _, participantID := range participantIDs {
+		found := false
+		for _, participant := range localTournament.Participants {
+			if participant.ID == participantID {
+				found = true
+				participants = append(participants, participant)
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("Failed to find participant " + floatToString(participantID) + " in tournament \"" + localTournament.Name + "\".")
+		}
+	}
+
+	return participants, nil
+}
+
+func (localTournament *LocalTournament) getParticipantName(participantID float64) string {
+	for _, participant := range localTournament.Participants {
+		if participant.ID == participantID {
+			return participant.Name
+		}
+	}
+
+	return "Unknown-" + floatToString(participantID)
+}
+
+func localBracketRead() ([]*LocalTournament, error) {
+	var raw []byte
+	if v, err := ioutil.ReadFile(localBracketPath); err != nil {
+		return nil, err
+	} else {
+		raw = v
+	}
+
+	localTournaments := make([]*LocalTournament, 0)
+	if err := json.Unmarshal(raw, &localTournaments); err != nil {
+		return nil, errors.New("Failed to unmarshal the local bracket JSON: " + err.Error())
+	}
+
+	return localTournaments, nil
+}
+
+func localBracketWrite(localTournaments []*LocalTournament) error {
+	var raw []byte
+	if v, err := json.MarshalIndent(localTournaments, "", "  "); err != nil {
+		return err
+	} else {
+		raw = v
+	}
+
+	return ioutil.WriteFile(localBracketPath, raw, 0644)
+}
+
+// Returns all of the tournaments in the file (so that they can be written back) and the one that
+// matches the provided tournament.
+func localBracketGetTournament(tournament Tournament) ([]*LocalTournament, *LocalTournament, error) {
+	var localTournaments []*LocalTournament
+	if v, err := localBracketRead(); err != nil {
+		return nil, nil, err
+	} else {
+		localTournaments = v
+	}
+
+	for _, localTournament := range localTournaments {
+		if localTournament.ID == tournament.ChallongeID {
+			return localTournaments, localTournament, nil
+		}
+	}
+
+	return nil, nil, errors.New("Failed to find the \"" + tournament.Name + "\" tournament in the local bracket file.")
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+/*
+	The bracket poller checks the bracket every so often and creates a channel for every match that
+	has opened up since the last check (e.g. when a loser from the other side of the bracket
+	finishes their match). It runs the same round start as the "!startround" command, which skips
+	the matches that already have a channel.
+*/
+
+var (
+	// How often the bracket is checked for new matches. (0 means that the poller is disabled.)
+	bracketPollInterval time.Duration
+)
+
+const (
+	// Checking more often than this would run into the Challonge rate limit.
+	bracketPollMinInterval = time.Minute
+)
+
+func bracketPollInit() {
+	if v, err := bracketPollRead(); err != nil {
+		log.Fatal(err.Error())
+		return
+	} else {
+		bracketPollInterval = v
+	}
+
+	if err := bracketPollSchedule(); err != nil {
+		log.Fatal("Failed to schedule the bracket poll: " + err.Error())
+		return
+	}
+}
+
+// Read the poll interval from the environment variables.
+func bracketPollRead() (time.Duration, error) {
+	var interval time.Duration
+	if intervalString := strings.TrimSpace(os.Getenv("BRACKET_POLL_INTERVAL")); intervalString != "" {
+		if v, err := time.ParseDuration(intervalString); err != nil {
+			return 0, errors.New("The \"BRACKET_POLL_INTERVAL\" environment variable has an invalid duration of \"" + intervalString + "\": " + err.Error())
+		} else {
+			interval = v
+		}
+		if interval < 0 {
+			return 0, errors.New("The \"BRACKET_POLL_INTERVAL\" environment variable is negative.")
+		}
+		if interval > 0 && interval < bracketPollMinInterval {
+			return 0, errors.New("The \"BRACKET_POLL_INTERVAL\" environment variable must be at least " + getDurationString(bracketPollMinInterval) + ".")
+		}
+	}
+
+	return interval, nil
+}
+
+// Keep the existing poll timer if it is due within one interval; otherwise, replace it. (This
+// also removes the timer if the poller was disabled.)
+func bracketPollSchedule() error {
+	now := time.Now()
+	alreadyScheduled := false
+	for _, timer := range schedulerGetAll() {
+		if timer.Action != TimerActionBracketPoll {
+			continue
+		}
+
+		if bracketPollInterval > 0 && !alreadyScheduled && !timer.DatetimeFire.After(now.Add(bracketPollInterval)) {
+			alreadyScheduled = true
+			continue
+		}
+		if err := schedulerCancel(timer.ID); err != nil {
+			return err
+		}
+	}
+
+	if bracketPollInterval == 0 || alreadyScheduled {
+		return nil
+	}
+	_, err := schedulerAdd(TimerActionBracketPoll, "", "", now.Add(bracketPollInterval))
+	return err
+}
+
+func bracketPollTimerFired(timer *ScheduledTimer) {
+	if bracketPollInterval == 0 {
+		return
+	}
+
+	// The round start only sends messages to the new match channels, so the channel of the message
+	// is only used if something goes wrong while announcing a match.
+	m := &discordgo.MessageCreate{
+		Message: &discordgo.Message{
+			ChannelID: discordGeneralChannelID,
+		},
+	}
+	for _, tournament := range tournaments {
+		// Swiss and round-robin rounds are paired as soon as the previous round is over, so they
+		// are always started by an admin.
+		if pairingIsNative(tournament) {
+			continue
+		}
+		startRound(m, tournament, StartRoundOptions{
+			Automatic: true,
+		})
+	}
+
+	// Schedule the next check. (The timer that fired has already been removed.)
+	if err := bracketPollSchedule(); err != nil {
+		log.Error("Failed to schedule the next bracket poll: " + err.Error())
+	}
+}
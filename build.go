@@ -56,6 +56,16 @@ func getLibraryPath() string {
 	return libPath
 }
 
+func buildExists(name string) bool {
+	for _, build := range builds {
+		if build.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
 func getBuildObjectFromBuildName(name string) Build {
 	for _, build := range builds {
 		if build.Name == name {
@@ -5,31 +5,19 @@ import (
 )
 
 func buildsBanStart(race *Race, msg string) {
-	// Update the state.
-	race.State = RaceStateBanningBuilds
-	if err := modals.Races.SetState(race.ChannelID, race.State); err != nil {
+	numBuildBans := tournaments[race.ChallongeURL].NumBuildBans
+
+	// Update the state and initialize the number of bans.
+	draft := raceGetDraftSnapshot(race)
+	draft.State = RaceStateBanningBuilds
+	draft.Racer1Bans = numBuildBans
+	draft.Racer2Bans = numBuildBans
+	if err := raceTransitionDraft(race, RaceTriggerCharactersFinished, draft); err != nil {
 		msg := "Failed to set the state for race \"" + race.Name() + "\": " + err.Error()
 		log.Error(msg)
 		discordSend(race.ChannelID, msg)
 		return
 	}
-	log.Info("Race \""+race.Name()+"\" is now in state:", race.State)
-
-	// Initialize the number of bans.
-	race.Racer1Bans = numBuildBans
-	if err := modals.Races.SetBans(race.ChannelID, 1, race.Racer1Bans); err != nil {
-		msg := "Failed to set the bans for racer 1 on race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		discordSend(race.ChannelID, msg)
-		return
-	}
-	race.Racer2Bans = numBuildBans
-	if err := modals.Races.SetBans(race.ChannelID, 2, race.Racer2Bans); err != nil {
-		msg := "Failed to set the bans for racer 2 on race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		discordSend(race.ChannelID, msg)
-		return
-	}
 
 	msg += "**Build Ban Phase**\n\n"
 	msg += "- Each racer gets to ban " + strconv.Itoa(numBuildBans) + " builds.\n"
@@ -49,14 +37,12 @@ func buildsBanStart(race *Race, msg string) {
 
 func buildsPickStart(race *Race, msg string) {
 	// Set the state.
-	race.State = RaceStatePickingBuilds
-	if err := modals.Races.SetState(race.ChannelID, race.State); err != nil {
+	if err := raceTransition(race, RaceTriggerBansFinished, RaceStatePickingBuilds); err != nil {
 		msg := "Failed to set the state for race \"" + race.Name() + "\": " + err.Error()
 		log.Error(msg)
 		discordSend(race.ChannelID, msg)
 		return
 	}
-	log.Info("Race \""+race.Name()+"\" is now in state:", race.State)
 
 	msg += "**Build Pick Phase**\n\n"
 	msg += "- " + strconv.Itoa(tournaments[race.ChallongeURL].BestOf) + " builds need to be picked.\n"
@@ -75,27 +61,21 @@ func buildsPickStart(race *Race, msg string) {
 }
 
 func buildsVetoStart(race *Race, msg string) {
-	race.State = RaceStateVetoBuilds
-	if err := modals.Races.SetState(race.ChannelID, race.State); err != nil {
-		msg := "Failed to set the state for race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		discordSend(race.ChannelID, msg)
-		return
-	}
-	log.Info("Race \""+race.Name()+"\" is now in state:", race.State)
+	numBuildVetos := tournaments[race.ChallongeURL].NumBuildVetos
 
-	// Initialize the number of vetos.
-	race.Racer1Vetos = numBuildVetos
-	if err := modals.Races.SetVetos(race.ChannelID, 1, numBuildVetos); err != nil {
-		msg := "Failed to set the vetos for \"" + race.Racer1.Username + "\" on race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		return
-	}
-	race.Racer2Vetos = numBuildVetos
-	if err := modals.Races.SetVetos(race.ChannelID, 2, numBuildVetos); err != nil {
-		msg := "Failed to set the vetos for \"" + race.Racer2.Username + "\" on race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		return
+	// Update the state and initialize the number of vetos. (They are saved along with the first
+	// build.)
+	draft := raceGetDraftSnapshot(race)
+	draft.State = RaceStateVetoBuilds
+	draft.Racer1Vetos = numBuildVetos
+	draft.Racer2Vetos = numBuildVetos
+	draft.NumVoted = 2 // Set it to 2 so that it gives a new build.
+
+	// The person who starts the vetos for the builds is the opposite of the person who got to
+	// start the vetos for the character.
+	draft.ActiveRacer = race.FirstPicker + 1
+	if draft.ActiveRacer > 2 {
+		draft.ActiveRacer = 1
 	}
 
 	msg += "**Build Ban Phase**\n\n"
@@ -105,61 +85,47 @@ func buildsVetoStart(race *Race, msg string) {
 	}
 	msg += ".\n"
 	msg += "- Use the `!yes` and `!no` commands to answer the questions.\n\n"
-
-	// The person who starts the vetos for the builds is the opposite of the person who got to
-	// start the vetos for the character.
-	newActiveRacer := race.FirstPicker + 1
-	if newActiveRacer > 2 {
-		newActiveRacer = 1
-	}
-
-	race.ActiveRacer = newActiveRacer
-	if err := modals.Races.SetActiveRacer(race.ChannelID, race.ActiveRacer); err != nil {
-		msg := "Failed to set the active racer for race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		discordSend(race.ChannelID, msg)
-		return
-	}
-
-	race.NumVoted = 2 // Set it to 2 so that it gives a new build.
-	buildsRound(race, msg)
+	buildsRound(race, RaceTriggerCharactersFinished, draft, msg)
 }
 
-func buildsRound(race *Race, msg string) {
-	if race.NumVoted == 2 {
-		// Both racers have voted, so get a new build.
-		race.NumVoted = 0
-		if err := modals.Races.SetNumVoted(race.ChannelID, race.NumVoted); err != nil {
-			msg := "Failed to set the NumVoted for race \"" + race.Name() + "\": " + err.Error()
-			log.Error(msg)
-			discordSend(race.ChannelID, msg)
-			return
+// Draw a new build once both racers have voted and skip the turns of the racers that are out of
+// vetos. (This is the same as the "charactersRound" function.)
+func buildsRound(race *Race, trigger RaceTrigger, draft *RaceDraftSnapshot, msg string) {
+	changed := draft.State != race.State
+	finished := false
+	for {
+		if draft.NumVoted == 2 {
+			// Both racers have voted, so get a new build.
+			changed = true
+			draft.NumVoted = 0
+			if len(draft.Builds) >= tournaments[race.ChallongeURL].BestOf {
+				finished = true
+				break
+			}
+
+			msg += assignRandomBuild(draft)
 		}
 
-		if len(race.Builds) == tournaments[race.ChallongeURL].BestOf {
-			matchSetInProgressAndPrintSummary(race, msg)
-			return
+		if !draftIsOutOfVetos(draft) {
+			break
 		}
 
-		msg += assignRandomBuild(race)
+		log.Info("Skipping racer " + strconv.Itoa(draft.ActiveRacer) + "'s turn, since they do not have a veto.")
+		changed = true
+		draftNoVeto(draft)
 	}
 
-	if (race.Racer1Vetos == 0 && race.Racer2Vetos == 0) || // Both racer have used all of their vetos.
-		(race.ActiveRacer == 1 && race.Racer1Vetos == 0) || // It is racer 1's turn and they have already used their vetos.
-		(race.ActiveRacer == 2 && race.Racer2Vetos == 0) { // It is racer 2's turn and they have already used their vetos.
-
-		log.Info("Skipping racer " + strconv.Itoa(race.ActiveRacer) + "'s turn, since they do not have a veto.")
-
-		race.NumVoted++
-		if err := modals.Races.SetNumVoted(race.ChannelID, race.NumVoted); err != nil {
-			msg := "Failed to set the NumVoted for race \"" + race.Name() + "\": " + err.Error()
+	if changed {
+		if err := raceTransitionDraft(race, trigger, draft); err != nil {
+			msg := "Failed to set the builds for race \"" + race.Name() + "\": " + err.Error()
 			log.Error(msg)
 			discordSend(race.ChannelID, msg)
 			return
 		}
+	}
 
-		incrementActiveRacer(race)
-		buildsRound(race, msg)
+	if finished {
+		matchSetInProgressAndPrintSummary(race, msg)
 		return
 	}
 
@@ -178,15 +144,16 @@ func buildsEnd(race *Race, msg string) {
 	matchSetInProgressAndPrintSummary(race, msg)
 }
 
-func assignRandomBuild(race *Race) string {
+// Draw a random build for the next character from the remaining builds of the draft. Returns the
+// message that announces it.
+func assignRandomBuild(draft *RaceDraftSnapshot) string {
 	// Get a random build.
-	randomBuildName, randomBuildIndex := getRandomArrayElement(race.BuildsRemaining)
-	randomBuildNum := randomBuildIndex + 1
+	randomBuildName, randomBuildIndex := getRandomArrayElement(draft.BuildsRemaining)
 
 	// Check to see if the item synergizes.
 	build := getBuildObjectFromBuildName(randomBuildName)
-	roundNum := len(race.Builds) + 1
-	characterName := race.Characters[roundNum-1]
+	roundNum := len(draft.Builds) + 1
+	characterName := draft.Characters[roundNum-1]
 	synergizes := true
 	for _, bannedCharacter := range build.BannedCharacters {
 		if bannedCharacter.Name == characterName {
@@ -197,24 +164,12 @@ func assignRandomBuild(race *Race) string {
 	if !synergizes {
 		// Get a new random build.
 		log.Info("The randomly selected build of \"" + randomBuildName + "\" does not synergize with \"" + characterName + "\". Trying again...")
-		return assignRandomBuild(race)
+		return assignRandomBuild(draft)
 	}
 
-	// Add it to the builds.
-	race.Builds = append(race.Builds, randomBuildName)
-	if err := modals.Races.SetBuilds(race.ChannelID, race.Builds); err != nil {
-		msg := "Failed to set the builds for race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		return msg
-	}
-
-	// Remove it from the available builds.
-	race.BuildsRemaining = deleteFromSlice(race.BuildsRemaining, randomBuildNum)
-	if err := modals.Races.SetBuildsRemaining(race.ChannelID, race.BuildsRemaining); err != nil {
-		msg := "Failed to set the builds for race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		return msg
-	}
+	// Move it from the available builds to the builds.
+	draft.Builds = append(draft.Builds, randomBuildName)
+	draft.BuildsRemaining = deleteFromSlice(draft.BuildsRemaining, randomBuildIndex)
 
 	msg := "**Round " + strconv.Itoa(roundNum) + "**:\n"
 	msg += "- Character: *" + characterName + "*\n"
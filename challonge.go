@@ -7,24 +7,20 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
-type Tournament struct {
-	Name              string
-	ChallongeURL      string
-	ChallongeID       float64
-	Ruleset           Ruleset
-	DiscordCategoryID string
-	BestOf            int
-}
+// ChallongeProvider implements the BracketProvider interface with the Challonge API:
+// https://api.challonge.com/v1
+type ChallongeProvider struct{}
 
 var (
 	challongeUsername string
 	challongeAPIKey   string
-	tournaments       = make(map[string]Tournament) // Indexed by Challonge URL suffix.
+	challongeAPIURL   string
 
 	// We don't want to use the default http.Client structure because it has no default timeout set.
 	myHTTPClient = &http.Client{
@@ -34,6 +30,12 @@ var (
 
 func challongeInit() {
 	// Read the Challonge configuration from the environment variables.
+	challongeAPIURL = os.Getenv("CHALLONGE_API_URL")
+	if len(challongeAPIURL) == 0 {
+		challongeAPIURL = "https://api.challonge.com/v1"
+	}
+	challongeAPIURL = strings.TrimSuffix(challongeAPIURL, "/")
+
 	challongeUsername = os.Getenv("CHALLONGE_USERNAME")
 	if len(challongeUsername) == 0 {
 		log.Fatal("The \"CHALLONGE_USERNAME\" environment variable is blank. Set it in the \".env\" file.")
@@ -45,93 +47,163 @@ func challongeInit() {
 		log.Fatal("The \"CHALLONGE_API_KEY\" environment variable is blank. Set it in the \".env\" file.")
 		return
 	}
+}
 
-	tournamentURLsString := os.Getenv("TOURNAMENT_CHALLONGE_URLS")
-	if len(tournamentURLsString) == 0 {
-		log.Fatal("The \"TOURNAMENT_CHALLONGE_URLS\" environment variable is blank. Set it in the \".env\" file.")
-		return
+func (*ChallongeProvider) GetTournaments() ([]*BracketTournament, error) {
+	// Get all of the Challonge user's tournaments.
+	apiURL := challongeAPIURL + "/tournaments.json?"
+	apiURL += "api_key=" + challongeAPIKey
+	var raw []byte
+	if v, err := challongeGetJSON("GET", apiURL, nil); err != nil {
+		return nil, err
+	} else {
+		raw = v
 	}
-	tournamentURLs := strings.Split(tournamentURLsString, ",")
 
-	tournamentRulesetsString := os.Getenv("TOURNAMENT_RULESETS")
-	if len(tournamentRulesetsString) == 0 {
-		log.Fatal("The \"TOURNAMENT_RULESETS\" environment variable is blank. Set it in the \".env\" file.")
-		return
+	jsonTournaments := make([]interface{}, 0)
+	if err := json.Unmarshal(raw, &jsonTournaments); err != nil {
+		return nil, errors.New("Failed to unmarshal the Challonge JSON: " + err.Error())
 	}
-	tournamentRulesetsStringSlice := strings.Split(tournamentRulesetsString, ",")
-	tournamentRulesets := make([]Ruleset, 0)
-	for _, ruleset := range tournamentRulesetsStringSlice {
-		if ruleset != "seeded" && ruleset != "unseeded" && ruleset != "team" {
-			log.Fatal("The \"TOURNAMENT_RULESETS\" environment variable is set to \"" + ruleset + "\", which is an invalid value.")
-			return
-		}
-		tournamentRulesets = append(tournamentRulesets, Ruleset(ruleset))
+
+	bracketTournaments := make([]*BracketTournament, 0)
+	for _, v := range jsonTournaments {
+		vMap := v.(map[string]interface{})
+		jsonTournament := vMap["tournament"].(map[string]interface{})
+		bracketTournaments = append(bracketTournaments, &BracketTournament{
+			ID:   jsonTournament["id"].(float64),
+			URL:  jsonTournament["url"].(string),
+			Name: jsonTournament["name"].(string),
+		})
 	}
 
-	tournamentDiscordCategoryIDsString := os.Getenv("TOURNAMENT_DISCORD_CATEGORY_IDS")
-	if len(tournamentDiscordCategoryIDsString) == 0 {
-		log.Fatal("The \"TOURNAMENT_DISCORD_CATEGORY_IDS\" environment variable is blank. Set it in the \".env\" file.")
-		return
+	return bracketTournaments, nil
+}
+
+func (*ChallongeProvider) GetOpenMatches(tournament Tournament) ([]*BracketMatch, error) {
+	// Get the tournament from Challonge.
+	apiURL := challongeAPIURL + "/tournaments/" + floatToString(tournament.ChallongeID) + ".json?"
+	apiURL += "api_key=" + challongeAPIKey + "&include_participants=1&include_matches=1"
+	var raw []byte
+	if v, err := challongeGetJSON("GET", apiURL, nil); err != nil {
+		return nil, err
+	} else {
+		raw = v
 	}
-	tournamentDiscordCategoryIDs := strings.Split(tournamentDiscordCategoryIDsString, ",")
 
-	tournamentBestOfString := os.Getenv("TOURNAMENT_BEST_OF")
-	if len(tournamentBestOfString) == 0 {
-		log.Fatal("The \"TOURNAMENT_BEST_OF\" environment variable is blank. Set it in the \".env\" file.")
-		return
+	vMap := make(map[string]interface{})
+	if err := json.Unmarshal(raw, &vMap); err != nil {
+		return nil, errors.New("Failed to unmarshal the Challonge JSON: " + err.Error())
 	}
-	tournamentBestOfStrings := strings.Split(tournamentBestOfString, ",")
+	jsonTournament := vMap["tournament"].(map[string]interface{})
 
-	// Validate that all of the "best of" values are numbers.
-	tournamentBestOf := make([]int, 0)
-	for _, bestOfString := range tournamentBestOfStrings {
-		if v, err := strconv.Atoi(bestOfString); err != nil {
-			log.Fatal("One of the values in the \"TOURNAMENT_BEST_OF\" environment variable is not a number.")
-			return
-		} else {
-			tournamentBestOf = append(tournamentBestOf, v)
+	// The group ID is null for the matches that are not in a group stage. The group letters come
+	// from every match, since some groups might not have any open matches.
+	jsonMatches := make([]map[string]interface{}, 0)
+	groupIDs := make([]float64, 0)
+	for _, v := range jsonTournament["matches"].([]interface{}) {
+		vMap := v.(map[string]interface{})
+		match := vMap["match"].(map[string]interface{})
+		jsonMatches = append(jsonMatches, match)
+		if groupID, ok := match["group_id"].(float64); ok {
+			groupIDs = append(groupIDs, groupID)
 		}
 	}
+	groupLetters := bracketGetGroupLetters(groupIDs)
 
-	// Get all of the Challonge user's tournaments.
-	apiURL := "https://api.challonge.com/v1/tournaments.json?"
+	// Get all of the open matches.
+	matches := make([]*BracketMatch, 0)
+	for _, match := range jsonMatches {
+		if match["state"] != "open" {
+			continue
+		}
+
+		player1ID := match["player1_id"].(float64)
+		player2ID := match["player2_id"].(float64)
+		groupID, _ := match["group_id"].(float64)
+		identifier, _ := match["identifier"].(string)
+		matches = append(matches, &BracketMatch{
+			ID:          floatToString(match["id"].(float64)),
+			Round:       floatToString(match["round"].(float64)),
+			GroupID:     groupID,
+			Group:       groupLetters[groupID],
+			Identifier:  identifier,
+			Player1ID:   player1ID,
+			Player1Name: challongeGetParticipantName(jsonTournament, player1ID),
+			Player2ID:   player2ID,
+			Player2Name: challongeGetParticipantName(jsonTournament, player2ID),
+		})
+	}
+
+	return matches, nil
+}
+
+func (*ChallongeProvider) ReportScore(tournament Tournament, matchID string, score string, winnerID float64) error {
+	// Update the match on Challonge:
+	// https://api.challonge.com/v1/documents/matches/update
+	challongeTournamentID := floatToString(tournament.ChallongeID)
+	apiURL := challongeAPIURL + "/tournaments/" + challongeTournamentID + "/matches/" + matchID + ".json"
+	apiURL += "?api_key=" + challongeAPIKey
+	apiURL += "&match[scores_csv]=" + score
+	apiURL += "&match[winner_id]=" + floatToString(winnerID)
+	_, err := challongeGetJSON("PUT", apiURL, nil)
+	return err
+}
+
+func (*ChallongeProvider) GetParticipants(tournament Tournament) ([]*BracketParticipant, error) {
+	// https://api.challonge.com/v1/documents/participants/index
+	apiURL := challongeAPIURL + "/tournaments/" + floatToString(tournament.ChallongeID) + "/participants.json?"
 	apiURL += "api_key=" + challongeAPIKey
 	var raw []byte
 	if v, err := challongeGetJSON("GET", apiURL, nil); err != nil {
-		log.Fatal("Failed to get the tournament from Challonge:", err)
-		return
+		return nil, err
 	} else {
 		raw = v
 	}
 
-	jsonTournaments := make([]interface{}, 0)
-	if err := json.Unmarshal(raw, &jsonTournaments); err != nil {
-		log.Fatal("Failed to unmarshal the Challonge JSON:", err)
-	}
-
-	// Figure out the ID for all the tournaments listed in the environment variable.
-	for i, tournamentURL := range tournamentURLs {
-		found := false
-		for _, v := range jsonTournaments {
-			vMap := v.(map[string]interface{})
-			jsonTournament := vMap["tournament"].(map[string]interface{})
-			if jsonTournament["url"] == tournamentURL {
-				found = true
-				tournaments[tournamentURL] = Tournament{
-					Name:              jsonTournament["name"].(string),
-					ChallongeURL:      tournamentURL,
-					ChallongeID:       jsonTournament["id"].(float64),
-					Ruleset:           tournamentRulesets[i],
-					DiscordCategoryID: tournamentDiscordCategoryIDs[i],
-					BestOf:            tournamentBestOf[i],
-				}
-				break
-			}
+	jsonParticipants := make([]interface{}, 0)
+	if err := json.Unmarshal(raw, &jsonParticipants); err != nil {
+		return nil, errors.New("Failed to unmarshal the Challonge JSON: " + err.Error())
+	}
+
+	participants := make([]*BracketParticipant, 0)
+	for _, v := range jsonParticipants {
+		vMap := v.(map[string]interface{})
+		participant := vMap["participant"].(map[string]interface{})
+		bracketParticipant := &BracketParticipant{
+			ID:   participant["id"].(float64),
+			Name: participant["name"].(string),
+			Seed: int(participant["seed"].(float64)),
 		}
-		if !found {
-			log.Fatal("Failed to find the \"" + tournamentURL + "\" tournament in this Challonge user's tournament list.")
+
+		// The final rank is null until the tournament has been finalized.
+		if finalRank, ok := participant["final_rank"].(float64); ok {
+			bracketParticipant.FinalRank = int(finalRank)
 		}
+
+		participants = append(participants, bracketParticipant)
 	}
+	sort.SliceStable(participants, func(i, j int) bool {
+		return participants[i].Seed < participants[j].Seed
+	})
+
+	return participants, nil
+}
+
+func (*ChallongeProvider) SetSeeds(tournament Tournament, participantIDs []float64) error {
+	// Challonge only lets us set one seed at a time, and it moves the other participants down to
+	// make room. Thus, setting the seeds from first to last results in exactly the right order:
+	// https://api.challonge.com/v1/documents/participants/update
+	challongeTournamentID := floatToString(tournament.ChallongeID)
+	for i, participantID := range participantIDs {
+		apiURL := challongeAPIURL + "/tournaments/" + challongeTournamentID + "/participants/" + floatToString(participantID) + ".json"
+		apiURL += "?api_key=" + challongeAPIKey
+		apiURL += "&participant[seed]=" + strconv.Itoa(i+1)
+		if _, err := challongeGetJSON("PUT", apiURL, nil); err != nil {
+			return errors.New("Failed to set the seed of participant " + floatToString(participantID) + ": " + err.Error())
+		}
+	}
+
+	return nil
 }
 
 func challongeGetJSON(method string, apiURL string, data io.Reader) ([]byte, error) {
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ChallongeMockServer is an in-process fake of the parts of the Challonge API that the bot uses.
+// It is seeded from a fixture in the same format as the local bracket file (see
+// "install/brackets_example.json") and it keeps the score updates in memory. Like Challonge, a
+// completed match sends its winner (or its loser) on to the matches that are waiting for it.
+type ChallongeMockServer struct {
+	server      *httptest.Server
+	apiKey      string
+	tournaments []*LocalTournament
+	prereqs     map[float64][2]ChallongeMockPrereq // Indexed by the ID of the match that waits.
+	mutex       *sync.Mutex
+}
+
+// ChallongeMockPrereq is the match that a player slot of a pending match is waiting for, e.g. the
+// semifinal that decides one of the players of the final. A match ID of 0 means that the slot is
+// not waiting for anything.
+type ChallongeMockPrereq struct {
+	MatchID float64
+	Loser   bool // The loser of the match goes on instead of the winner. (e.g. in a losers bracket)
+}
+
+func NewChallongeMockServer(fixture []*LocalTournament, apiKey string) *ChallongeMockServer {
+	mock := &ChallongeMockServer{
+		apiKey:      apiKey,
+		tournaments: fixture,
+		prereqs:     make(map[float64][2]ChallongeMockPrereq),
+		mutex:       new(sync.Mutex),
+	}
+	mock.server = httptest.NewServer(http.HandlerFunc(mock.handle))
+	return mock
+}
+
+// URL returns the base API URL of the mock server, which is the equivalent of
+// "https://api.challonge.com/v1".
+func (mock *ChallongeMockServer) URL() string {
+	return mock.server.URL
+}
+
+func (mock *ChallongeMockServer) Close() {
+	mock.server.Close()
+}
+
+// SetPrereqs sets the matches that decide the two players of a pending match. The fixture should
+// leave those players blank.
+func (mock *ChallongeMockServer) SetPrereqs(matchID float64, player1 ChallongeMockPrereq, player2 ChallongeMockPrereq) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	mock.prereqs[matchID] = [2]ChallongeMockPrereq{player1, player2}
+}
+
+// GetMatch returns a copy of the match with the provided ID, so that the score updates can be
+// inspected.
+func (mock *ChallongeMockServer) GetMatch(tournamentID float64, matchID float64) (LocalMatch, bool) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	tournament := mock.getTournament(floatToString(tournamentID))
+	if tournament == nil {
+		return LocalMatch{}, false
+	}
+
+	for _, match := range tournament.Matches {
+		if match.ID == matchID {
+			return match, true
+		}
+	}
+
+	return LocalMatch{}, false
+}
+
+func (mock *ChallongeMockServer) handle(w http.ResponseWriter, r *http.Request) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	if r.URL.Query().Get("api_key") != mock.apiKey {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// e.g. "tournaments.json", "tournaments/123.json", or "tournaments/123/matches/456.json"
+	route := strings.Split(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), ".json"), "/")
+
+	if r.Method == "GET" && len(route) == 1 && route[0] == "tournaments" {
+		mock.handleTournaments(w)
+	} else if r.Method == "GET" && len(route) == 2 && route[0] == "tournaments" {
+		mock.handleTournament(w, r, route[1])
+	} else if r.Method == "PUT" && len(route) == 4 && route[0] == "tournaments" && route[2] == "matches" {
+		mock.handleMatchUpdate(w, r, route[1], route[3])
+	} else if r.Method == "GET" && len(route) == 3 && route[0] == "tournaments" && route[2] == "participants" {
+		mock.handleParticipants(w, route[1])
+	} else if r.Method == "PUT" && len(route) == 4 && route[0] == "tournaments" && route[2] == "participants" {
+		mock.handleParticipantUpdate(w, r, route[1], route[3])
+	} else {
+		http.Error(w, "Not Found", http.StatusNotFound)
+	}
+}
+
+func (mock *ChallongeMockServer) handleTournaments(w http.ResponseWriter) {
+	jsonTournaments := make([]interface{}, 0)
+	for _, tournament := range mock.tournaments {
+		jsonTournaments = append(jsonTournaments, map[string]interface{}{
+			"tournament": map[string]interface{}{
+				"id":   tournament.ID,
+				"url":  tournament.URL,
+				"name": tournament.Name,
+			},
+		})
+	}
+
+	mock.writeJSON(w, jsonTournaments)
+}
+
+func (mock *ChallongeMockServer) handleTournament(w http.ResponseWriter, r *http.Request, tournamentID string) {
+	tournament := mock.getTournament(tournamentID)
+	if tournament == nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	jsonTournament := map[string]interface{}{
+		"id":   tournament.ID,
+		"url":  tournament.URL,
+		"name": tournament.Name,
+	}
+
+	if r.URL.Query().Get("include_participants") == "1" {
+		jsonParticipants := make([]interface{}, 0)
+		for i, participant := range tournament.Participants {
+			jsonParticipants = append(jsonParticipants, map[string]interface{}{
+				"participant": challongeMockGetJSONParticipant(participant, i+1),
+			})
+		}
+		jsonTournament["participants"] = jsonParticipants
+	}
+
+	if r.URL.Query().Get("include_matches") == "1" {
+		jsonMatches := make([]interface{}, 0)
+		for _, match := range tournament.Matches {
+			jsonMatches = append(jsonMatches, map[string]interface{}{
+				"match": challongeMockGetJSONMatch(match),
+			})
+		}
+		jsonTournament["matches"] = jsonMatches
+	}
+
+	mock.writeJSON(w, map[string]interface{}{
+		"tournament": jsonTournament,
+	})
+}
+
+func (mock *ChallongeMockServer) handleMatchUpdate(w http.ResponseWriter, r *http.Request, tournamentID string, matchID string) {
+	tournament := mock.getTournament(tournamentID)
+	if tournament == nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	for i, match := range tournament.Matches {
+		if floatToString(match.ID) != matchID {
+			continue
+		}
+
+		// Challonge requires the winner to be one of the two players in the match.
+		winnerIDString := r.URL.Query().Get("match[winner_id]")
+		if winnerIDString != "" {
+			var winnerID float64
+			if v, err := strconv.ParseFloat(winnerIDString, 64); err != nil {
+				http.Error(w, "Unprocessable Entity", http.StatusUnprocessableEntity)
+				return
+			} else {
+				winnerID = v
+			}
+			if winnerID != match.Player1ID && winnerID != match.Player2ID {
+				http.Error(w, "Unprocessable Entity", http.StatusUnprocessableEntity)
+				return
+			}
+			tournament.Matches[i].WinnerID = winnerID
+			tournament.Matches[i].State = "complete"
+		}
+
+		scoresCSV := r.URL.Query().Get("match[scores_csv]")
+		if scoresCSV != "" {
+			tournament.Matches[i].ScoresCSV = scoresCSV
+		}
+
+		if tournament.Matches[i].State == "complete" {
+			mock.advance(tournament, tournament.Matches[i])
+		}
+
+		mock.writeJSON(w, map[string]interface{}{
+			"match": challongeMockGetJSONMatch(tournament.Matches[i]),
+		})
+		return
+	}
+
+	http.Error(w, "Not Found", http.StatusNotFound)
+}
+
+// Move the winner and the loser of a completed match on to the matches that are waiting for them.
+// A pending match opens once both of its players are known.
+func (mock *ChallongeMockServer) advance(tournament *LocalTournament, completedMatch LocalMatch) {
+	loserID := completedMatch.Player1ID
+	if completedMatch.WinnerID == completedMatch.Player1ID {
+		loserID = completedMatch.Player2ID
+	}
+
+	for i, match := range tournament.Matches {
+		prereqs, ok := mock.prereqs[match.ID]
+		if !ok || match.State == "complete" {
+			continue
+		}
+
+		for j, prereq := range prereqs {
+			if prereq.MatchID != completedMatch.ID {
+				continue
+			}
+
+			playerID := completedMatch.WinnerID
+			if prereq.Loser {
+				playerID = loserID
+			}
+			if j == 0 {
+				tournament.Matches[i].Player1ID = playerID
+			} else {
+				tournament.Matches[i].Player2ID = playerID
+			}
+		}
+
+		if tournament.Matches[i].Player1ID != 0 && tournament.Matches[i].Player2ID != 0 {
+			tournament.Matches[i].State = "open"
+		}
+	}
+}
+
+func (mock *ChallongeMockServer) handleParticipants(w http.ResponseWriter, tournamentID string) {
+	tournament := mock.getTournament(tournamentID)
+	if tournament == nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	jsonParticipants := make([]interface{}, 0)
+	for i, participant := range tournament.Participants {
+		jsonParticipants = append(jsonParticipants, map[string]interface{}{
+			"participant": challongeMockGetJSONParticipant(participant, i+1),
+		})
+	}
+
+	mock.writeJSON(w, jsonParticipants)
+}
+
+func (mock *ChallongeMockServer) handleParticipantUpdate(w http.ResponseWriter, r *http.Request, tournamentID string, participantID string) {
+	tournament := mock.getTournament(tournamentID)
+	if tournament == nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	index := -1
+	for i, participant := range tournament.Participants {
+		if floatToString(participant.ID) == participantID {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	seedString := r.URL.Query().Get("participant[seed]")
+	if seedString != "" {
+		// Challonge moves the other participants to make room for the new seed, and it does not
+		// allow the seeds to change once the tournament has started.
+		seed, err := strconv.Atoi(seedString)
+		if err != nil || seed < 1 || seed > len(tournament.Participants) {
+			http.Error(w, "Unprocessable Entity", http.StatusUnprocessableEntity)
+			return
+		}
+		for _, match := range tournament.Matches {
+			if match.State == "complete" {
+				http.Error(w, "Unprocessable Entity", http.StatusUnprocessableEntity)
+				return
+			}
+		}
+
+		participant := tournament.Participants[index]
+		participants := append([]LocalParticipant{}, tournament.Participants[:index]...)
+		participants = append(participants, tournament.Participants[index+1:]...)
+		participants = append(participants[:seed-1], append([]LocalParticipant{participant}, participants[seed-1:]...)...)
+		tournament.Participants = participants
+		index = seed - 1
+	}
+
+	mock.writeJSON(w, map[string]interface{}{
+		"participant": challongeMockGetJSONParticipant(tournament.Participants[index], index+1),
+	})
+}
+
+func (mock *ChallongeMockServer) getTournament(tournamentID string) *LocalTournament {
+	for _, tournament := range mock.tournaments {
+		// Challonge allows looking up tournaments by either the ID or the URL suffix.
+		if floatToString(tournament.ID) == tournamentID || tournament.URL == tournamentID {
+			return tournament
+		}
+	}
+
+	return nil
+}
+
+func (mock *ChallongeMockServer) writeJSON(w http.ResponseWriter, v interface{}) {
+	var raw []byte
+	if v, err := json.Marshal(v); err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	} else {
+		raw = v
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(raw); err != nil {
+		log.Error("Failed to write the mock Challonge response:", err)
+	}
+}
+
+func challongeMockGetJSONParticipant(participant LocalParticipant, seed int) map[string]interface{} {
+	jsonParticipant := map[string]interface{}{
+		"id":               participant.ID,
+		"name":             participant.Name,
+		"seed":             seed,
+		"final_rank":       nil,
+		"group_player_ids": make([]interface{}, 0),
+	}
+	if participant.FinalRank != 0 {
+		jsonParticipant["final_rank"] = participant.FinalRank
+	}
+
+	return jsonParticipant
+}
+
+func challongeMockGetJSONMatch(match LocalMatch) map[string]interface{} {
+	// Challonge uses null for the players of a match that is not ready yet and for the winner of a
+	// match that is not complete yet.
+	jsonMatch := map[string]interface{}{
+		"id":         match.ID,
+		"round":      match.Round,
+		"group_id":   nil,
+		"identifier": match.Identifier,
+		"state":      match.State,
+		"player1_id": nil,
+		"player2_id": nil,
+		"scores_csv": match.ScoresCSV,
+		"winner_id":  nil,
+	}
+	if match.GroupID != 0 {
+		jsonMatch["group_id"] = match.GroupID
+	}
+	if match.Player1ID != 0 {
+		jsonMatch["player1_id"] = match.Player1ID
+	}
+	if match.Player2ID != 0 {
+		jsonMatch["player2_id"] = match.Player2ID
+	}
+	if match.WinnerID != 0 {
+		jsonMatch["winner_id"] = match.WinnerID
+	}
+
+	return jsonMatch
+}
@@ -57,33 +57,21 @@ var (
 )
 
 func charactersBanStart(race *Race) {
+	numCharacterBans := tournaments[race.ChallongeURL].NumCharacterBans
+
 	msg := matchBeginningAlert(race)
 
-	// Update the state.
-	race.State = RaceStateBanningCharacters
-	if err := modals.Races.SetState(race.ChannelID, race.State); err != nil {
+	// Update the state and initialize the number of bans.
+	draft := raceGetDraftSnapshot(race)
+	draft.State = RaceStateBanningCharacters
+	draft.Racer1Bans = numCharacterBans
+	draft.Racer2Bans = numCharacterBans
+	if err := raceTransitionDraft(race, RaceTriggerMatchStart, draft); err != nil {
 		msg := "Failed to set the state for race \"" + race.Name() + "\": " + err.Error()
 		log.Error(msg)
 		discordSend(race.ChannelID, msg)
 		return
 	}
-	log.Info("Race \""+race.Name()+"\" is now in state:", race.State)
-
-	// Initialize the number of bans.
-	race.Racer1Bans = numCharacterBans
-	if err := modals.Races.SetBans(race.ChannelID, 1, race.Racer1Bans); err != nil {
-		msg := "Failed to set the bans for racer 1 on race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		discordSend(race.ChannelID, msg)
-		return
-	}
-	race.Racer2Bans = numCharacterBans
-	if err := modals.Races.SetBans(race.ChannelID, 2, race.Racer2Bans); err != nil {
-		msg := "Failed to set the bans for racer 2 on race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		discordSend(race.ChannelID, msg)
-		return
-	}
 
 	msg += "**Character Ban Phase**\n\n"
 	msg += "- Each racer gets to ban " + strconv.Itoa(numCharacterBans) + " characters.\n"
@@ -103,14 +91,12 @@ func charactersBanStart(race *Race) {
 
 func charactersPickStart(race *Race, msg string) {
 	// Set the state.
-	race.State = RaceStatePickingCharacters
-	if err := modals.Races.SetState(race.ChannelID, race.State); err != nil {
+	if err := raceTransition(race, RaceTriggerBansFinished, RaceStatePickingCharacters); err != nil {
 		msg := "Failed to set the state for race \"" + race.Name() + "\": " + err.Error()
 		log.Error(msg)
 		discordSend(race.ChannelID, msg)
 		return
 	}
-	log.Info("Race \""+race.Name()+"\" is now in state:", race.State)
 
 	msg += "**Character Pick Phase**\n\n"
 	msg += "- " + strconv.Itoa(tournaments[race.ChallongeURL].BestOf) + " characters need to be picked.\n"
@@ -129,29 +115,15 @@ func charactersPickStart(race *Race, msg string) {
 }
 
 func charactersVetoStart(race *Race) {
-	// Update the state.
-	race.State = RaceStateVetoCharacters
-	if err := modals.Races.SetState(race.ChannelID, race.State); err != nil {
-		msg := "Failed to set the state for race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		discordSend(race.ChannelID, msg)
-		return
-	}
-	log.Info("Race \""+race.Name()+"\" is now in state:", race.State)
+	numCharacterVetos := tournaments[race.ChallongeURL].NumCharacterVetos
 
-	// Initialize the number of vetos.
-	race.Racer1Vetos = numCharacterVetos
-	if err := modals.Races.SetVetos(race.ChannelID, 1, numCharacterVetos); err != nil {
-		msg := "Failed to set the vetos for \"" + race.Racer1.Username + "\" on race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		return
-	}
-	race.Racer2Vetos = numCharacterVetos
-	if err := modals.Races.SetVetos(race.ChannelID, 2, numCharacterVetos); err != nil {
-		msg := "Failed to set the vetos for \"" + race.Racer2.Username + "\" on race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		return
-	}
+	// Update the state and initialize the number of vetos. (They are saved along with the first
+	// character.)
+	draft := raceGetDraftSnapshot(race)
+	draft.State = RaceStateVetoCharacters
+	draft.Racer1Vetos = numCharacterVetos
+	draft.Racer2Vetos = numCharacterVetos
+	draft.NumVoted = 2 // Set it to 2 so that it gives a new character.
 
 	msg := matchBeginningAlert(race)
 
@@ -162,45 +134,48 @@ func charactersVetoStart(race *Race) {
 	}
 	msg += ".\n"
 	msg += "- Use the `!yes` and `!no` commands to answer the questions.\n\n"
-	race.NumVoted = 2 // Set it to 2 so that it gives a new character.
-	charactersRound(race, msg)
+	charactersRound(race, RaceTriggerMatchStart, draft, msg)
 }
 
-func charactersRound(race *Race, msg string) {
-	if race.NumVoted == 2 {
-		// Both racers have voted, so get a new character.
-		race.NumVoted = 0
-		if err := modals.Races.SetNumVoted(race.ChannelID, race.NumVoted); err != nil {
-			msg := "Failed to set the NumVoted for race \"" + race.Name() + "\": " + err.Error()
-			log.Error(msg)
-			discordSend(race.ChannelID, msg)
-			return
+// Draw a new character once both racers have voted and skip the turns of the racers that are out
+// of vetos. The draft is saved with the trigger in a single update, so a character is never shown
+// to the racers without being saved.
+func charactersRound(race *Race, trigger RaceTrigger, draft *RaceDraftSnapshot, msg string) {
+	changed := draft.State != race.State
+	finished := false
+	for {
+		if draft.NumVoted == 2 {
+			// Both racers have voted, so get a new character.
+			changed = true
+			draft.NumVoted = 0
+			if len(draft.Characters) >= tournaments[race.ChallongeURL].BestOf {
+				finished = true
+				break
+			}
+
+			msg += assignRandomCharacter(draft)
 		}
 
-		if len(race.Characters) == tournaments[race.ChallongeURL].BestOf {
-			charactersEnd(race, msg)
-			return
+		if !draftIsOutOfVetos(draft) {
+			break
 		}
 
-		msg += assignRandomCharacter(race)
+		log.Info("Skipping racer " + strconv.Itoa(draft.ActiveRacer) + "'s turn, since they do not have a veto.")
+		changed = true
+		draftNoVeto(draft)
 	}
 
-	if (race.Racer1Vetos == 0 && race.Racer2Vetos == 0) || // Both racers have used all of their vetos.
-		(race.ActiveRacer == 1 && race.Racer1Vetos == 0) || // It is racer 1's turn and they have already used their vetos.
-		(race.ActiveRacer == 2 && race.Racer2Vetos == 0) { // It is racer 2's turn and they have already used their vetos.
-
-		log.Info("Skipping racer " + strconv.Itoa(race.ActiveRacer) + "'s turn, since they do not have a veto.")
-
-		race.NumVoted++
-		if err := modals.Races.SetNumVoted(race.ChannelID, race.NumVoted); err != nil {
-			msg := "Failed to set the NumVoted for race \"" + race.Name() + "\": " + err.Error()
+	if changed {
+		if err := raceTransitionDraft(race, trigger, draft); err != nil {
+			msg := "Failed to set the characters for race \"" + race.Name() + "\": " + err.Error()
 			log.Error(msg)
 			discordSend(race.ChannelID, msg)
 			return
 		}
+	}
 
-		incrementActiveRacer(race)
-		charactersRound(race, msg)
+	if finished {
+		charactersEnd(race, msg)
 		return
 	}
 
@@ -222,9 +197,9 @@ func charactersEnd(race *Race, msg string) {
 
 	ruleset := tournaments[race.ChallongeURL].Ruleset
 	if ruleset == "seeded" {
-		if tournamentType == TournamentTypeBanPick {
+		if tournaments[race.ChallongeURL].Type == TournamentTypeBanPick {
 			buildsBanStart(race, msg)
-		} else if tournamentType == TournamentTypeVeto {
+		} else if tournaments[race.ChallongeURL].Type == TournamentTypeVeto {
 			buildsVetoStart(race, msg)
 		} else {
 			msg := "Unknown tournament type for tournament: " + race.TournamentName
@@ -239,28 +214,17 @@ func charactersEnd(race *Race, msg string) {
 	}
 }
 
-func assignRandomCharacter(race *Race) string {
+// Draw a random character from the remaining characters of the draft. Returns the message that
+// announces it.
+func assignRandomCharacter(draft *RaceDraftSnapshot) string {
 	// Get a random character.
-	randomCharacter, randomCharacterIndex := getRandomArrayElement(race.CharactersRemaining)
-	randomCharacterNum := randomCharacterIndex + 1
-
-	// Add it to the characters.
-	race.Characters = append(race.Characters, randomCharacter)
-	if err := modals.Races.SetCharacters(race.ChannelID, race.Characters); err != nil {
-		msg := "Failed to set the characters for race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		return msg
-	}
+	randomCharacter, randomCharacterIndex := getRandomArrayElement(draft.CharactersRemaining)
 
-	// Remove it from the available characters.
-	race.CharactersRemaining = deleteFromSlice(race.CharactersRemaining, randomCharacterNum)
-	if err := modals.Races.SetCharactersRemaining(race.ChannelID, race.CharactersRemaining); err != nil {
-		msg := "Failed to set the characters for race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		return msg
-	}
+	// Move it from the available characters to the characters.
+	draft.Characters = append(draft.Characters, randomCharacter)
+	draft.CharactersRemaining = deleteFromSlice(draft.CharactersRemaining, randomCharacterIndex)
 
-	roundNum := len(race.Characters)
+	roundNum := len(draft.Characters)
 	msg := "**Round " + strconv.Itoa(roundNum) + "**:\n"
 	msg += "- Character: *" + randomCharacter + "*\n\n"
 	return msg
@@ -27,6 +27,18 @@ func commandHelpGetMsg() string {
 	msg += "!randombuild             Get a random build\n"
 	msg += "!getnext                 Get the time of the next scheduled match\n"
 	msg += "!schedule                Get a list of all of the currently scheduled matches\n"
+	msg += "!results                 Get the results of every completed match\n"
+	msg += "!results [round]         Get the results of the matches in a round\n"
+	msg += "!results @user           Get the results of a racer's matches\n"
+	msg += "!stats characters        Get the bans, picks, vetos, and win rates of each character\n"
+	msg += "!stats builds            Get the bans, picks, vetos, and win rates of each build\n"
+	msg += "!stats @user             Get the record of a racer\n"
+	msg += "                         (add \"season [season]\" or \"tournament [url]\" to filter)\n"
+	msg += "!rating                  Get your rating\n"
+	msg += "!rating @user            Get the rating of a racer\n"
+	msg += "!leaderboard             Get the racers with the highest ratings\n"
+	msg += "!standings [season]      Get the points of each racer in a season\n"
+	msg += "!table [url]             Get the standings of a Swiss or round-robin tournament\n"
 	msg += "```\n"
 	msg += "Match commands (in a match channel):\n"
 	msg += "```\n"
@@ -47,6 +59,11 @@ func commandHelpGetMsg() string {
 	msg += "!no                      Do not veto the selected thing\n"
 	msg += "!score                   Report the score after the match has completed\n"
 	msg += "                         (with your number first)\n"
+	msg += "!game [num] win|loss     Report the result of a game\n"
+	msg += "                         (with an optional winning time, e.g. 12:34)\n"
+	msg += "!scoreok                 Confirm the score that your opponent reported\n"
+	msg += "!scoredispute            Dispute the score that your opponent reported\n"
+	msg += "!history                 Get a list of everything that has happened in the match\n"
 	msg += "```"
 	/*
 		msg += "Admin-only commands:\n"
@@ -59,8 +76,10 @@ func commandHelpGetMsg() string {
 		msg += "!setcasternotok          Deny permission on behalf of a racer\n"
 		msg += "!setcasteralwaysok       Enable a user's default caster approval for them\n"
 		msg += "!setcasteralwaysnotok    Disable a user's default caster approval for them\n"
-		msg += "!startround              Start the current round of the tournament\n"
-		msg += "!endround                Delete all of the channels for this round\n"
+		msg += "!startround              Create the channels for the open matches that do not have one\n"
+		msg += "!endround                Archive (or delete) all of the channels for this round\n"
+		msg += "                         (the results of completed matches are kept)\n"
+		msg += "!endround delete         Delete all of the channels for this round, even with an archive\n"
 		msg += "!checkround              Do a dry run of "!startround"\n"
 		msg += "!forcetime               Force a scheduled time\n"
 		msg += "!forcetimeok             Force the scheduled time to be ok\n"
@@ -69,6 +88,11 @@ func commandHelpGetMsg() string {
 		msg += "!forcepick [num]         Force the current racer to pick\n"
 		msg += "!forceyes                Force the current racer to veto\n"
 		msg += "!forceno                 Force the current racer to not veto\n"
+		msg += "!forcescore [score]      Submit the final score (with racer 1's number first)\n"
+		msg += "!undo                    Revert the last ban, pick, or veto\n"
+		msg += "!reload                  Reload the tournament configuration\n"
+		msg += "!seed [url]              Seed the bracket by the ratings of the participants\n"
+		msg += "!timers                  List, cancel, or reschedule the pending timers\n"
 		msg += "!join                    Print out the URL to join another server\n"
 		msg += "!getstate                Get the current state of the match\n"
 		msg += "!getchannelid [name]     Get the ID of the specified Discord channel\n"
@@ -104,6 +128,20 @@ func commandInit() {
 	commandHandlerMap["randitem"] = commandRandomBuild
 	commandHandlerMap["getnext"] = commandGetNext
 	commandHandlerMap["schedule"] = commandSchedule
+	commandHandlerMap["results"] = commandResults
+	commandHandlerMap["result"] = commandResults
+	commandHandlerMap["stats"] = commandStats
+	commandHandlerMap["stat"] = commandStats
+	commandHandlerMap["statistics"] = commandStats
+	commandHandlerMap["rating"] = commandRating
+	commandHandlerMap["elo"] = commandRating
+	commandHandlerMap["leaderboard"] = commandLeaderboard
+	commandHandlerMap["leaderboards"] = commandLeaderboard
+	commandHandlerMap["top"] = commandLeaderboard
+	commandHandlerMap["standings"] = commandStandings
+	commandHandlerMap["standing"] = commandStandings
+	commandHandlerMap["table"] = commandTable
+	commandHandlerMap["tiebreaks"] = commandTable
 
 	// Match commands
 	commandHandlerMap["time"] = commandTime
@@ -123,7 +161,15 @@ func commandInit() {
 	commandHandlerMap["pick"] = commandPick
 	commandHandlerMap["yes"] = commandYes
 	commandHandlerMap["no"] = commandNo
+	commandHandlerMap["game"] = commandGame
+	commandHandlerMap["games"] = commandGame
 	commandHandlerMap["score"] = commandScore
+	commandHandlerMap["scoreok"] = commandScoreOk
+	commandHandlerMap["okscore"] = commandScoreOk
+	commandHandlerMap["scoredispute"] = commandScoreDispute
+	commandHandlerMap["disputescore"] = commandScoreDispute
+	commandHandlerMap["dispute"] = commandScoreDispute
+	commandHandlerMap["history"] = commandHistory
 	commandHandlerMap["status"] = commandStatus
 
 	// Admin-only commands
@@ -163,6 +209,13 @@ func commandInit() {
 	commandHandlerMap["yesforce"] = commandForceYes
 	commandHandlerMap["forceno"] = commandForceNo
 	commandHandlerMap["noforce"] = commandForceNo
+	commandHandlerMap["forcescore"] = commandForceScore
+	commandHandlerMap["scoreforce"] = commandForceScore
+	commandHandlerMap["undo"] = commandUndo
+	commandHandlerMap["reload"] = commandReload
+	commandHandlerMap["timers"] = commandTimers
+	commandHandlerMap["seed"] = commandSeed
+	commandHandlerMap["timer"] = commandTimers
 	commandHandlerMap["join"] = commandJoin
 	commandHandlerMap["getstate"] = commandGetState
 	commandHandlerMap["getchannelid"] = commandGetChannelID
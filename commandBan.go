@@ -8,6 +8,10 @@ import (
 )
 
 func commandBan(m *discordgo.MessageCreate, args []string) {
+	commandBanWithCause(m, args, nil)
+}
+
+func commandBanWithCause(m *discordgo.MessageCreate, args []string, cause *RaceEventCause) {
 	if len(args) == 0 {
 		commandBanPrint(m)
 		return
@@ -39,9 +43,7 @@ func commandBan(m *discordgo.MessageCreate, args []string) {
 	}
 
 	// Check to see if this race is in the banning phase.
-	if race.State != RaceStateBanningCharacters &&
-		race.State != RaceStateBanningBuilds {
-
+	if !raceCanTrigger(race, RaceTriggerBan) {
 		discordSend(m.ChannelID, "You can only ban something once the match has started.")
 		return
 	}
@@ -86,45 +88,20 @@ func commandBan(m *discordgo.MessageCreate, args []string) {
 		return
 	}
 
-	// Ban the thing.
-	thing := thingsRemaining[choice]
-	thingsRemaining = deleteFromSlice(thingsRemaining, choice)
+	// Remember how the draft looked beforehand, in case an admin needs to undo this.
+	snapshot := raceGetDraftSnapshot(race)
 
-	if race.State == RaceStateBanningCharacters {
-		race.CharactersRemaining = thingsRemaining
-		if err := modals.Races.SetCharactersRemaining(race.ChannelID, race.CharactersRemaining); err != nil {
-			msg := "Failed to set the characters remaining for race \"" + race.Name() + "\": " + err.Error()
-			log.Error(msg)
-			discordSend(m.ChannelID, msg)
-			return
-		}
-	} else if race.State == RaceStateBanningBuilds {
-		race.BuildsRemaining = thingsRemaining
-		if err := modals.Races.SetBuildsRemaining(race.ChannelID, race.BuildsRemaining); err != nil {
-			msg := "Failed to set the builds remaining for race \"" + race.Name() + "\": " + err.Error()
-			log.Error(msg)
-			discordSend(m.ChannelID, msg)
-			return
-		}
-	}
-
-	// Decrement their bans.
-	var bansLeft int
-	if racerNum == 1 {
-		race.Racer1Bans--
-		bansLeft = race.Racer1Bans
-	} else if racerNum == 2 {
-		race.Racer2Bans--
-		bansLeft = race.Racer2Bans
-	}
-	if err := modals.Races.SetBans(race.ChannelID, racerNum, bansLeft); err != nil {
-		msg := "Failed to set the bans for racer " + strconv.Itoa(racerNum) + " on race \"" + race.Name() + "\": " + err.Error()
+	// Ban the thing and pass the turn to the other racer.
+	draft := raceGetDraftSnapshot(race)
+	thing := draftBan(draft, racerNum, choice)
+	if err := raceTransitionDraft(race, RaceTriggerBan, draft); err != nil {
+		msg := "Failed to ban \"" + thing + "\" for race \"" + race.Name() + "\": " + err.Error()
 		log.Error(msg)
 		discordSend(m.ChannelID, msg)
 		return
 	}
 
-	incrementActiveRacer(race)
+	raceEventRecordDraft(race, m.Author, RaceEventTypeBan, racerNum, thing, snapshot, cause)
 
 	msg := m.Author.Mention() + " banned **" + thing + "**.\n"
 	totalBansLeft := race.Racer1Bans + race.Racer2Bans
@@ -144,6 +121,9 @@ func commandBan(m *discordgo.MessageCreate, args []string) {
 			discordSend(race.ChannelID, msg)
 		}
 	}
+
+	// Start the timer for the next turn (or stop it if the draft is over).
+	turnSchedule(race)
 }
 
 func commandBanPrint(m *discordgo.MessageCreate) {
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestCommandBan(t *testing.T) {
+	g := testSetup(t, Tournament{
+		Ruleset:          RulesetUnseeded,
+		Type:             TournamentTypeBanPick,
+		BestOf:           3,
+		NumCharacterBans: 1,
+	})
+	race := g.StartMatch(t, g.AddRace(t, RaceStateScheduled))
+	if race.State != RaceStateBanningCharacters {
+		t.Fatalf("got a state of %s after the match started, want %s", race.State, RaceStateBanningCharacters)
+	}
+	first := g.Racer(race.ActiveRacer)
+	second := g.Racer(3 - race.ActiveRacer)
+	firstBan := race.CharactersRemaining[0]
+	secondBan := race.CharactersRemaining[2] // The second choice once the first one is gone.
+
+	tests := []struct {
+		name    string
+		member  *discordgo.Member
+		content string
+		want    string
+	}{
+		{"no arguments", first, "!ban", "Ban something with: `!ban [number]`"},
+		{"not a racer", g.Admin, "!ban 1", "Only \"Dea1h\" and \"Cyber_1\" can ban something."},
+		{"not their turn", second, "!ban 1", "It is not your turn."},
+		{"not a number", first, "!ban one", "\"one\" is not a number."},
+		{"not a valid choice", first, "!ban 100", "\"100\" is not a valid choice."},
+		{"first ban", first, "!ban 1", first.User.Mention() + " banned **" + firstBan + "**.\n" + second.User.Mention() + ", you're next!"},
+		{"last ban", second, "!ban 2", second.User.Mention() + " banned **" + secondBan + "**.\n\n**Character Pick Phase**"},
+		{"after the bans", first, "!ban 1", "You can only ban something once the match has started."},
+	}
+
+	for _, test := range tests {
+		g.Send(race.ChannelID, test.member, test.content)
+		if got := g.LastMessage(race.ChannelID); !strings.Contains(got, test.want) {
+			t.Errorf("%s: got a message of %q, want it to contain %q", test.name, got, test.want)
+		}
+	}
+
+	race = testGetRace(t, race.ChannelID)
+	if race.State != RaceStatePickingCharacters {
+		t.Errorf("got a state of %s after the bans, want %s", race.State, RaceStatePickingCharacters)
+	}
+	if race.Racer1Bans != 0 || race.Racer2Bans != 0 {
+		t.Errorf("got %d and %d bans left, want 0 and 0", race.Racer1Bans, race.Racer2Bans)
+	}
+	if stringInSlice(firstBan, race.CharactersRemaining) || stringInSlice(secondBan, race.CharactersRemaining) {
+		t.Errorf("the banned characters are still remaining: %v", race.CharactersRemaining)
+	}
+	if g.Racer(race.ActiveRacer) != first {
+		t.Errorf("got an active racer of %d after both bans, want the first racer again", race.ActiveRacer)
+	}
+}
+
+func TestCommandBanOutsideOfRace(t *testing.T) {
+	g := testSetup(t, Tournament{
+		Ruleset: RulesetUnseeded,
+		Type:    TournamentTypeBanPick,
+		BestOf:  1,
+	})
+
+	g.Send(discordGeneralChannelID, g.Racer1, "!ban 1")
+	want := "You can only use that command in a race channel."
+	if got := g.LastMessage(discordGeneralChannelID); got != want {
+		t.Errorf("got a message of %q, want %q", got, want)
+	}
+}
+
+func TestCommandForceBan(t *testing.T) {
+	g := testSetup(t, Tournament{
+		Ruleset:          RulesetUnseeded,
+		Type:             TournamentTypeBanPick,
+		BestOf:           3,
+		NumCharacterBans: 1,
+	})
+	race := g.StartMatch(t, g.AddRace(t, RaceStateScheduled))
+
+	// A forced ban that does not go through is not recorded.
+	g.Send(race.ChannelID, g.Admin, "!forceban 100")
+	if events := testGetEvents(t, race.ChannelID); len(events) != 0 {
+		t.Errorf("got %d events after an invalid forced ban, want 0", len(events))
+	}
+
+	g.Send(race.ChannelID, g.Admin, "!forceban 1")
+	events := testGetEvents(t, race.ChannelID)
+	if len(events) != 2 || events[0].Type != RaceEventTypeForceBan || events[1].Type != RaceEventTypeBan {
+		t.Fatalf("got the events %v after a forced ban, want the force event and then the ban", events)
+	}
+	if events[0].RacerNum != race.ActiveRacer || events[1].RacerNum != race.ActiveRacer {
+		t.Errorf("got the events on behalf of racers %d and %d, want %d", events[0].RacerNum, events[1].RacerNum, race.ActiveRacer)
+	}
+}
@@ -58,7 +58,7 @@ func commandCast(m *discordgo.MessageCreate, args []string) {
 	}
 
 	// Check to see if this race is in progress.
-	if race.State == RaceStateInProgress {
+	if race.State == RaceStateInProgress || race.State == RaceStateScorePending {
 		discordSend(m.ChannelID, "The match has already begun. You should not be bothering the players at this point.")
 		return
 	}
@@ -72,7 +72,7 @@ func commandCast(m *discordgo.MessageCreate, args []string) {
 	// Check to see if this is a valid language.
 	valid := false
 	var languageFull string
-	for k, v := range languageMap {
+	for k, v := range tournaments[race.ChallongeURL].Languages {
 		if strings.ToLower(k) == language || strings.ToLower(v) == language {
 			valid = true
 			language = k
@@ -82,7 +82,7 @@ func commandCast(m *discordgo.MessageCreate, args []string) {
 	}
 	if !valid {
 		msg := "That is not a valid language. Valid languages are:\n"
-		for k, v := range languageMap {
+		for k, v := range tournaments[race.ChallongeURL].Languages {
 			msg += "- " + k + " / " + v + "\n"
 		}
 		discordSend(m.ChannelID, msg)
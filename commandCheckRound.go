@@ -11,6 +11,8 @@ func commandCheckRound(m *discordgo.MessageCreate, args []string) {
 
 	// Go through all of the tournaments.
 	for _, tournament := range tournaments {
-		startRound(m, tournament, true)
+		startRound(m, tournament, StartRoundOptions{
+			DryRun: true,
+		})
 	}
 }
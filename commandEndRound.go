@@ -2,6 +2,8 @@ package main
 
 import (
 	"database/sql"
+	"strconv"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -25,6 +27,17 @@ func commandEndRound(m *discordgo.MessageCreate, args []string) {
 		return
 	}
 
+	// The channels are archived if there is an archive category, unless the admin asks for them to
+	// be deleted.
+	archive := archiveCategoryID != ""
+	if len(args) == 1 && strings.ToLower(args[0]) == "delete" {
+		archive = false
+	} else if len(args) != 0 {
+		msg := "The format of this command is: `!endround` or `!endround delete`"
+		discordSend(m.ChannelID, msg)
+		return
+	}
+
 	// Get all of the channels.
 	var channels []*discordgo.Channel
 	if v, err := discordSession.GuildChannels(discordGuildID); err != nil {
@@ -49,9 +62,52 @@ func commandEndRound(m *discordgo.MessageCreate, args []string) {
 			continue
 		}
 
-		// Delete it from the database.
 		deletedChannels = true
-		if err := modals.Races.Delete(channel.ID); err != nil {
+		var race *Race
+		if v, err := getRace(channel.ID); err == sql.ErrNoRows {
+			// This channel does not have a race, so there is nothing to archive or delete.
+		} else if err != nil {
+			msg := "Failed to get the race from the database: " + err.Error()
+			log.Error(msg)
+			discordSend(m.ChannelID, msg)
+			return
+		} else {
+			race = v
+		}
+		if err := schedulerCancelAll(channel.ID, ""); err != nil {
+			msg := "Failed to cancel the timers for the \"" + channel.Name + "\" channel: " + err.Error()
+			log.Error(msg)
+			discordSend(m.ChannelID, msg)
+			return
+		}
+
+		// Archive it instead of deleting it. (The race is kept in the database along with its
+		// casts, so only channels that have a race are archived.)
+		if archive && race != nil {
+			var numMessages int
+			if v, err := archiveRaceChannel(channel, race); err != nil {
+				msg := "Failed to archive the \"" + channel.Name + "\" channel: " + err.Error()
+				log.Error(msg)
+				discordSend(m.ChannelID, msg)
+				return
+			} else {
+				numMessages = v
+			}
+
+			msg := "Archived channel \"" + channel.Name + "\". (" + strconv.Itoa(numMessages) + " message" + getPlural(numMessages) + " saved.)"
+			discordSend(m.ChannelID, msg)
+			log.Info(msg)
+			continue
+		}
+
+		// Delete it from the database.
+		// (Completed races are kept so that the "!results" command can show them.)
+		keptResult := false
+		if race == nil {
+			// Do nothing.
+		} else if race.State == RaceStateCompleted {
+			keptResult = true
+		} else if err := modals.Races.Delete(channel.ID); err != nil {
 			msg := "Failed to delete the race from the database: " + err.Error()
 			log.Error(msg)
 			discordSend(m.ChannelID, msg)
@@ -67,6 +123,9 @@ func commandEndRound(m *discordgo.MessageCreate, args []string) {
 		}
 
 		msg := "Deleted channel \"" + channel.Name + "\"."
+		if keptResult {
+			msg += " (The result of the match was kept.)"
+		}
 		discordSend(m.ChannelID, msg)
 		log.Info(msg)
 	}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestCommandEndRoundArchive(t *testing.T) {
+	g := testSetup(t, Tournament{
+		Ruleset: RulesetUnseeded,
+		Type:    TournamentTypeBanPick,
+		BestOf:  1,
+	})
+	archiveCategoryID = g.Session.AddChannel("Archive", discordgo.ChannelTypeGuildCategory, "").ID
+	race := g.AddRace(t, RaceStateCompleted)
+	discordSend(race.ChannelID, "gg")
+	discordSend(race.ChannelID, "gg wp")
+
+	g.Send(discordGeneralChannelID, g.Admin, "!endround")
+	want := "Archived channel \"" + race.ChannelName + "\". (2 messages saved.)"
+	if got := g.LastMessage(discordGeneralChannelID); got != want {
+		t.Errorf("got a message of %q, want %q", got, want)
+	}
+
+	// The channel is moved to the archive category and the racers can no longer talk in it.
+	var channel *discordgo.Channel
+	if v, err := g.Session.Channel(race.ChannelID); err != nil {
+		t.Fatalf("The race channel was deleted instead of archived: %v", err)
+	} else {
+		channel = v
+	}
+	if channel.ParentID != archiveCategoryID {
+		t.Errorf("got a parent of %s, want the archive category of %s", channel.ParentID, archiveCategoryID)
+	}
+	for _, member := range []*discordgo.Member{g.Racer1, g.Racer2} {
+		found := false
+		for _, overwrite := range channel.PermissionOverwrites {
+			if overwrite.ID != member.User.ID {
+				continue
+			}
+			found = true
+			if overwrite.Deny&discordgo.PermissionSendMessages == 0 {
+				t.Errorf("\"%s\" can still send messages in the archived channel", member.User.Username)
+			}
+		}
+		if !found {
+			t.Errorf("there is no permission overwrite for \"%s\"", member.User.Username)
+		}
+	}
+
+	race = testGetRace(t, race.ChannelID)
+	if !race.Archived {
+		t.Errorf("the race was not marked as archived")
+	}
+	var numMessages int
+	if err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM tournament_race_messages
+		WHERE race_id = (SELECT id FROM tournament_races WHERE channel_id = ?)
+	`, race.ChannelID).Scan(&numMessages); err != nil {
+		t.Errorf("Failed to count the archived messages: %v", err)
+	} else if numMessages != 2 {
+		t.Errorf("got %d archived messages, want 2", numMessages)
+	}
+}
+
+func TestCommandEndRoundDelete(t *testing.T) {
+	g := testSetup(t, Tournament{
+		Ruleset: RulesetUnseeded,
+		Type:    TournamentTypeBanPick,
+		BestOf:  1,
+	})
+	archiveCategoryID = g.Session.AddChannel("Archive", discordgo.ChannelTypeGuildCategory, "").ID
+	race := g.AddRace(t, RaceStateInProgress)
+
+	g.Send(race.ChannelID, g.Admin, "!endround")
+	want := "You cannot use this command in a race channel."
+	if got := g.LastMessage(race.ChannelID); got != want {
+		t.Errorf("got a message of %q in the race channel, want %q", got, want)
+	}
+
+	g.Send(discordGeneralChannelID, g.Racer1, "!endround")
+	want = "Only admins can perform this command."
+	if got := g.LastMessage(discordGeneralChannelID); got != want {
+		t.Errorf("got a message of %q for a racer, want %q", got, want)
+	}
+
+	g.Send(discordGeneralChannelID, g.Admin, "!endround delete")
+	want = "Deleted channel \"" + race.ChannelName + "\"."
+	if got := g.LastMessage(discordGeneralChannelID); got != want {
+		t.Errorf("got a message of %q, want %q", got, want)
+	}
+	if _, err := g.Session.Channel(race.ChannelID); err == nil {
+		t.Errorf("the race channel was not deleted")
+	}
+	if _, err := getRace(race.ChannelID); err != sql.ErrNoRows {
+		t.Errorf("got an error of %v when getting the deleted race, want %v", err, sql.ErrNoRows)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"database/sql"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -52,6 +53,13 @@ func commandForceNo(m *discordgo.MessageCreate, args []string) {
 		return
 	}
 
+	// The action itself will be recorded under the racer's name, so record who forced it. (This
+	// is only recorded if the action goes through.)
+	cause := &RaceEventCause{
+		Actor: m.Author,
+		Type:  RaceEventTypeForceNo,
+		Value: strings.Join(args, " "),
+	}
 	m.Author = discordUser
-	commandNo(m, args)
+	commandNoWithCause(m, args, cause)
 }
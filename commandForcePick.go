@@ -2,6 +2,7 @@ package main
 
 import (
 	"database/sql"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -57,8 +58,15 @@ func commandForcePick(m *discordgo.MessageCreate, args []string) {
 		return
 	}
 
+	// The action itself will be recorded under the racer's name, so record who forced it. (This
+	// is only recorded if the action goes through.)
+	cause := &RaceEventCause{
+		Actor: m.Author,
+		Type:  RaceEventTypeForcePick,
+		Value: strings.Join(args, " "),
+	}
 	m.Author = discordUser
-	commandPick(m, args)
+	commandPickWithCause(m, args, cause)
 }
 
 func commandForcePickPrint(m *discordgo.MessageCreate) {
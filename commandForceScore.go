@@ -0,0 +1,72 @@
+package main
+
+import (
+	"database/sql"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func commandForceScore(m *discordgo.MessageCreate, args []string) {
+	if !isAdmin(m) {
+		return
+	}
+
+	if len(args) != 1 {
+		commandForceScorePrint(m)
+		return
+	}
+
+	// Check to see if this is a race channel (and get the race from the database).
+	var race *Race
+	if v, err := getRace(m.ChannelID); err == sql.ErrNoRows {
+		discordSend(m.ChannelID, "You can only use that command in a race channel.")
+		return
+	} else if err != nil {
+		msg := "Failed to get the race from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		race = v
+	}
+
+	// Check to see if this race is in progress (or has a score waiting to be confirmed).
+	if !raceCanTrigger(race, RaceTriggerScoreForced) {
+		discordSend(m.ChannelID, "You can only force the score once the racers have finished picking characters and builds.")
+		return
+	}
+
+	// Unlike the "!score" command, racer 1's wins always come first.
+	var p1Wins, p2Wins int
+	if v1, v2, ok := scoreParse(race, args[0]); !ok {
+		commandForceScorePrint(m)
+		return
+	} else {
+		p1Wins = v1
+		p2Wins = v2
+	}
+
+	if err := scoreSet(race, p1Wins, p2Wins, 0); err != nil {
+		msg := "Failed to set the score for race \"" + race.Name() + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	}
+
+	if err := scoreSubmit(race, RaceTriggerScoreForced); err != nil {
+		log.Error(err.Error())
+		discordSend(m.ChannelID, err.Error())
+		return
+	}
+
+	raceEventRecord(race, m.Author, RaceEventTypeForceScore, 0, race.Score.String, nil)
+
+	discordSend(m.ChannelID, scoreGetSubmittedMsg(race))
+}
+
+func commandForceScorePrint(m *discordgo.MessageCreate) {
+	msg := "Set the final score of the match with: `!forcescore [score]`\n"
+	msg += "e.g. `!forcescore 3-2`\n"
+	msg += "The number of wins for racer 1 (the first name in the channel) should come first."
+	discordSend(m.ChannelID, msg)
+}
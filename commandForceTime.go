@@ -2,6 +2,7 @@ package main
 
 import (
 	"database/sql"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -52,6 +53,13 @@ func commandForceTime(m *discordgo.MessageCreate, args []string) {
 		return
 	}
 
+	// The action itself will be recorded under the racer's name, so record who forced it. (This
+	// is only recorded if the action goes through.)
+	cause := &RaceEventCause{
+		Actor: m.Author,
+		Type:  RaceEventTypeForceTime,
+		Value: strings.Join(args, " "),
+	}
 	m.Author = discordUser
-	commandTime(m, args)
+	commandTimeWithCause(m, args, cause)
 }
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func commandGame(m *discordgo.MessageCreate, args []string) {
+	// Check to see if this is a race channel (and get the race from the database).
+	var race *Race
+	if v, err := getRace(m.ChannelID); err == sql.ErrNoRows {
+		discordSend(m.ChannelID, "You can only use that command in a race channel.")
+		return
+	} else if err != nil {
+		msg := "Failed to get the race from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		race = v
+	}
+
+	var games []*RaceGame
+	if v, err := modals.RaceGames.GetAll(race.ChannelID); err != nil {
+		msg := "Failed to get the games from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		games = v
+	}
+
+	// With no arguments, list the games that have been reported so far.
+	if len(args) == 0 {
+		if len(games) == 0 {
+			commandGamePrint(m)
+			return
+		}
+
+		discordSend(m.ChannelID, commandGameGetSummary(race, games))
+		return
+	}
+
+	if len(args) != 2 && len(args) != 3 {
+		commandGamePrint(m)
+		return
+	}
+
+	// Check to see if this person is one of the two racers.
+	racerNum := raceGetRacerNum(race, m.Author.ID)
+	if racerNum == 0 {
+		discordSend(m.ChannelID, "Only \""+race.Racer1.Username+"\" and \""+race.Racer2.Username+"\" can report a game.")
+		return
+	}
+
+	// Check to see if a score was already reported.
+	if race.State == RaceStateScorePending {
+		discordSend(m.ChannelID, "A score of \""+race.Score.String+"\" was already reported and is waiting to be confirmed with `!scoreok` or disputed with `!scoredispute`.")
+		return
+	}
+
+	// Check to see if this race is in progress.
+	if !raceCanTrigger(race, RaceTriggerGameReported) {
+		discordSend(m.ChannelID, "You can only report a game once you have finished picking characters and builds.")
+		return
+	}
+
+	// Check to see if this is a valid game number.
+	var gameNum int
+	if v, err := strconv.Atoi(args[0]); err != nil {
+		discordSend(m.ChannelID, "\""+args[0]+"\" is not a number.")
+		return
+	} else if v < 1 || v > tournaments[race.ChallongeURL].BestOf || v > len(race.Characters) {
+		discordSend(m.ChannelID, "\""+args[0]+"\" is not a valid game number.")
+		return
+	} else {
+		gameNum = v
+	}
+
+	// Check to see if they won or lost.
+	var winner int
+	result := strings.ToLower(args[1])
+	if result == "win" || result == "won" || result == "w" {
+		winner = racerNum
+	} else if result == "loss" || result == "lose" || result == "lost" || result == "l" {
+		winner = 1
+		if racerNum == 1 {
+			winner = 2
+		}
+	} else {
+		commandGamePrint(m)
+		return
+	}
+
+	game := &RaceGame{
+		GameNum:   gameNum,
+		Winner:    winner,
+		Character: race.Characters[gameNum-1],
+	}
+	if gameNum <= len(race.Builds) {
+		game.Build = race.Builds[gameNum-1]
+	}
+	if len(args) == 3 {
+		if v, err := gameParseTime(args[2]); err != nil {
+			discordSend(m.ChannelID, err.Error())
+			return
+		} else {
+			game.WinningTime = sql.NullInt64{
+				Int64: int64(v),
+				Valid: true,
+			}
+		}
+	}
+
+	if err := modals.RaceGames.Set(race.ChannelID, m.Author.ID, game); err != nil {
+		msg := "Failed to set the game for race \"" + race.Name() + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	}
+
+	value := "game " + strconv.Itoa(gameNum) + ": " + result
+	if game.WinningTime.Valid {
+		value += " in " + gameFormatTime(int(game.WinningTime.Int64))
+	}
+	raceEventRecord(race, m.Author, RaceEventTypeGameReported, racerNum, value, nil)
+
+	// Replace the old result of this game, if any.
+	newGames := make([]*RaceGame, 0)
+	for _, oldGame := range games {
+		if oldGame.GameNum != gameNum {
+			newGames = append(newGames, oldGame)
+		}
+	}
+	newGames = append(newGames, game)
+	games = newGames
+
+	// The match is over once someone has won enough games.
+	msg := commandGameGetSummary(race, games) + "\n"
+	p1Wins, p2Wins := gameGetWins(games)
+	winsNeeded := tournaments[race.ChallongeURL].BestOf/2 + 1
+	if p1Wins >= winsNeeded || p2Wins >= winsNeeded {
+		scoreReport(race, m.Author, racerNum, p1Wins, p2Wins, msg)
+		return
+	}
+
+	discordSend(m.ChannelID, msg)
+}
+
+func commandGameGetSummary(race *Race, games []*RaceGame) string {
+	// Show the games in order, even if they were reported out of order.
+	sortedGames := make([]*RaceGame, 0)
+	for i := 1; i <= tournaments[race.ChallongeURL].BestOf; i++ {
+		for _, game := range games {
+			if game.GameNum == i {
+				sortedGames = append(sortedGames, game)
+			}
+		}
+	}
+
+	p1Wins, p2Wins := gameGetWins(games)
+	msg := "**Games:**\n"
+	for _, game := range sortedGames {
+		msg += "- " + gameGetDescription(race, game) + "\n"
+	}
+	msg += "Current score: `" + race.Racer1.Username + "` " + strconv.Itoa(p1Wins) + "-" + strconv.Itoa(p2Wins) + " `" + race.Racer2.Username + "`\n"
+
+	return msg
+}
+
+func commandGamePrint(m *discordgo.MessageCreate) {
+	msg := "Report the result of a game with: `!game [game number] win|loss [winning time]`\n"
+	msg += "e.g. `!game 1 win 12:34` or `!game 2 loss`\n"
+	msg += "(The winning time is optional.)\n"
+	msg += "Once someone has won enough games, the score of the match will be reported automatically."
+	discordSend(m.ChannelID, msg)
+}
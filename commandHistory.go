@@ -0,0 +1,67 @@
+package main
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func commandHistory(m *discordgo.MessageCreate, args []string) {
+	// Create the user in the database if it does not already exist.
+	var user *User
+	if v, err := userGet(m.Author); err != nil {
+		msg := "Failed to get the user from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		user = v
+	}
+
+	// Check to see if this is a race channel (and get the race from the database).
+	var race *Race
+	if v, err := getRace(m.ChannelID); err == sql.ErrNoRows {
+		discordSend(m.ChannelID, "You can only use that command in a race channel.")
+		return
+	} else if err != nil {
+		msg := "Failed to get the race from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		race = v
+	}
+
+	var events []*RaceEvent
+	if v, err := modals.RaceEvents.GetAll(race.ChannelID); err != nil {
+		msg := "Failed to get the history for race \"" + race.Name() + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		events = v
+	}
+
+	if len(events) == 0 {
+		discordSend(m.ChannelID, "Nothing has happened in this match yet.")
+		return
+	}
+
+	// Show the timestamps in the timezone of the person who asked.
+	timezone := user.GetTimezone()
+	loc, _ := time.LoadLocation(timezone)
+
+	msg := "History for match **" + race.Name() + "** (" + getTimezoneShort(timezone) + "):\n"
+	msg += "```\n"
+	for _, event := range events {
+		msg += event.DatetimeCreated.In(loc).Format("2006-01-02 15:04:05") + "  "
+		msg += raceEventGetDescription(race, event)
+		if event.Undone {
+			msg += " (undone)"
+		}
+		msg += "\n"
+	}
+	msg += "```"
+	discordSend(m.ChannelID, msg)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	leaderboardDefaultLength = 20
+)
+
+func commandLeaderboard(m *discordgo.MessageCreate, args []string) {
+	if len(args) > 1 {
+		commandLeaderboardPrint(m)
+		return
+	}
+
+	length := leaderboardDefaultLength
+	if len(args) == 1 {
+		if v, err := strconv.Atoi(args[0]); err != nil || v <= 0 {
+			discordSend(m.ChannelID, "\""+args[0]+"\" is not a valid number.")
+			return
+		} else {
+			length = v
+		}
+	}
+
+	var leaderboard []*User
+	if v, err := modals.Users.GetLeaderboard(); err != nil {
+		msg := "Failed to get the leaderboard from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		leaderboard = v
+	}
+
+	if len(leaderboard) == 0 {
+		discordSend(m.ChannelID, "Nobody has played a rated match yet.")
+		return
+	}
+	if length > len(leaderboard) {
+		length = len(leaderboard)
+	}
+
+	msg := "Leaderboard (top " + strconv.Itoa(length) + " of " + strconv.Itoa(len(leaderboard)) + "):\n"
+	for i, user := range leaderboard[:length] {
+		// Usernames are put in code blocks since underscores in usernames can mess up the formatting.
+		msg += strconv.Itoa(i+1) + ". `" + user.Username + "` - " + ratingGetDescription(user)
+		msg += " (" + strconv.Itoa(user.RatedMatches) + " match" + getPluralEs(user.RatedMatches) + ")\n"
+	}
+	discordSend(m.ChannelID, msg)
+}
+
+func commandLeaderboardPrint(m *discordgo.MessageCreate) {
+	msg := "Get the racers with the highest ratings with: `!leaderboard [length]`\n"
+	msg += "e.g. `!leaderboard 50`"
+	discordSend(m.ChannelID, msg)
+}
@@ -7,6 +7,10 @@ import (
 )
 
 func commandNo(m *discordgo.MessageCreate, args []string) {
+	commandNoWithCause(m, args, nil)
+}
+
+func commandNoWithCause(m *discordgo.MessageCreate, args []string, cause *RaceEventCause) {
 	// Check to see if this is a race channel (and get the race from the database).
 	var race *Race
 	if v, err := getRace(m.ChannelID); err == sql.ErrNoRows {
@@ -33,7 +37,7 @@ func commandNo(m *discordgo.MessageCreate, args []string) {
 	}
 
 	// Check to see if this race is in the vetoing phase.
-	if race.State != RaceStateVetoCharacters && race.State != RaceStateVetoBuilds {
+	if !raceCanTrigger(race, RaceTriggerVeto) {
 		discordSend(m.ChannelID, "You can only veto something once the match has started.")
 		return
 	}
@@ -44,19 +48,34 @@ func commandNo(m *discordgo.MessageCreate, args []string) {
 		return
 	}
 
-	// Set the number of people who have voted on this build.
-	race.NumVoted++
-	if err := modals.Races.SetNumVoted(race.ChannelID, race.NumVoted); err != nil {
-		msg := "Failed to set the NumVoted for race \"" + race.Name() + "\": " + err.Error()
+	// Remember how the draft looked beforehand, in case an admin needs to undo this.
+	snapshot := raceGetDraftSnapshot(race)
+
+	var thing string
+	if race.State == RaceStateVetoCharacters && len(race.Characters) > 0 {
+		thing = race.Characters[len(race.Characters)-1]
+	} else if race.State == RaceStateVetoBuilds && len(race.Builds) > 0 {
+		thing = race.Builds[len(race.Builds)-1]
+	}
+
+	// Keep the thing and pass the turn to the other racer.
+	draft := raceGetDraftSnapshot(race)
+	draftNoVeto(draft)
+	if err := raceTransitionDraft(race, RaceTriggerVeto, draft); err != nil {
+		msg := "Failed to set the vote for race \"" + race.Name() + "\": " + err.Error()
 		log.Error(msg)
 		discordSend(m.ChannelID, msg)
 		return
 	}
 
-	incrementActiveRacer(race)
+	raceEventRecordDraft(race, m.Author, RaceEventTypeNoVeto, racerNum, thing, snapshot, cause)
+
 	if race.State == RaceStateVetoCharacters {
-		charactersRound(race, "")
+		charactersRound(race, RaceTriggerVeto, raceGetDraftSnapshot(race), "")
 	} else if race.State == RaceStateVetoBuilds {
-		buildsRound(race, "")
+		buildsRound(race, RaceTriggerVeto, raceGetDraftSnapshot(race), "")
 	}
+
+	// Start the timer for the next turn (or stop it if the draft is over).
+	turnSchedule(race)
 }
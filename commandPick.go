@@ -9,6 +9,10 @@ import (
 )
 
 func commandPick(m *discordgo.MessageCreate, args []string) {
+	commandPickWithCause(m, args, nil)
+}
+
+func commandPickWithCause(m *discordgo.MessageCreate, args []string, cause *RaceEventCause) {
 	if len(args) == 0 {
 		commandPickPrint(m)
 		return
@@ -40,9 +44,7 @@ func commandPick(m *discordgo.MessageCreate, args []string) {
 	}
 
 	// Check to see if this race is in the picking phase.
-	if race.State != RaceStatePickingCharacters &&
-		race.State != RaceStatePickingBuilds {
-
+	if !raceCanTrigger(race, RaceTriggerPick) {
 		discordSend(m.ChannelID, "You can only pick something once the banning phase has finished.")
 		return
 	}
@@ -66,13 +68,11 @@ func commandPick(m *discordgo.MessageCreate, args []string) {
 	// at 1.
 	choice--
 
-	var thingsRemaining, things []string
+	var thingsRemaining []string
 	if race.State == RaceStatePickingCharacters {
 		thingsRemaining = race.CharactersRemaining
-		things = race.Characters
 	} else if race.State == RaceStatePickingBuilds {
 		thingsRemaining = race.BuildsRemaining
-		things = race.Builds
 	}
 
 	// Check to see if this is a valid index.
@@ -81,47 +81,26 @@ func commandPick(m *discordgo.MessageCreate, args []string) {
 		return
 	}
 
-	// Pick the thing.
-	thing := thingsRemaining[choice]
-	thingsRemaining = deleteFromSlice(thingsRemaining, choice)
-	things = append(things, thing)
+	// Remember how the draft looked beforehand, in case an admin needs to undo this.
+	snapshot := raceGetDraftSnapshot(race)
 
-	if race.State == RaceStatePickingCharacters {
-		race.CharactersRemaining = thingsRemaining
-		if err := modals.Races.SetCharactersRemaining(race.ChannelID, race.CharactersRemaining); err != nil {
-			msg := "Failed to set the characters remaining for race \"" + race.Name() + "\": " + err.Error()
-			log.Error(msg)
-			discordSend(m.ChannelID, msg)
-			return
-		}
+	// Pick the thing and pass the turn to the other racer.
+	draft := raceGetDraftSnapshot(race)
+	thing := draftPick(draft, choice)
+	if err := raceTransitionDraft(race, RaceTriggerPick, draft); err != nil {
+		msg := "Failed to pick \"" + thing + "\" for race \"" + race.Name() + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	}
 
-		race.Characters = things
-		if err := modals.Races.SetCharacters(race.ChannelID, race.Characters); err != nil {
-			msg := "Failed to set the characters for race \"" + race.Name() + "\": " + err.Error()
-			log.Error(msg)
-			discordSend(m.ChannelID, msg)
-			return
-		}
-	} else if race.State == RaceStatePickingBuilds {
-		race.BuildsRemaining = thingsRemaining
-		if err := modals.Races.SetBuildsRemaining(race.ChannelID, race.BuildsRemaining); err != nil {
-			msg := "Failed to set the builds remaining for race \"" + race.Name() + "\": " + err.Error()
-			log.Error(msg)
-			discordSend(m.ChannelID, msg)
-			return
-		}
+	raceEventRecordDraft(race, m.Author, RaceEventTypePick, racerNum, thing, snapshot, cause)
 
-		race.Builds = things
-		if err := modals.Races.SetBuilds(race.ChannelID, race.Builds); err != nil {
-			msg := "Failed to set the builds for race \"" + race.Name() + "\": " + err.Error()
-			log.Error(msg)
-			discordSend(m.ChannelID, msg)
-			return
-		}
+	things := race.Characters
+	if race.State == RaceStatePickingBuilds {
+		things = race.Builds
 	}
 
-	incrementActiveRacer(race)
-
 	msg := m.Author.Mention() + " picked **" + thing + "**.\n"
 	picksLeft := tournaments[race.ChallongeURL].BestOf - len(things)
 	fmt.Println("------------------------------------------------------")
@@ -146,6 +125,9 @@ func commandPick(m *discordgo.MessageCreate, args []string) {
 			discordSend(race.ChannelID, msg)
 		}
 	}
+
+	// Start the timer for the next turn (or stop it if the draft is over).
+	turnSchedule(race)
 }
 
 func commandPickPrint(m *discordgo.MessageCreate) {
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestCommandPick(t *testing.T) {
+	g := testSetup(t, Tournament{
+		Ruleset:          RulesetUnseeded,
+		Type:             TournamentTypeBanPick,
+		BestOf:           3,
+		NumCharacterBans: 1,
+	})
+	race := g.StartMatch(t, g.AddRace(t, RaceStateScheduled))
+	first := g.Racer(race.ActiveRacer)
+	second := g.Racer(3 - race.ActiveRacer)
+
+	g.Send(race.ChannelID, first, "!pick 1")
+	want := "You can only pick something once the banning phase has finished."
+	if got := g.LastMessage(race.ChannelID); got != want {
+		t.Fatalf("got a message of %q before the bans were finished, want %q", got, want)
+	}
+
+	g.Send(race.ChannelID, first, "!ban 1")
+	g.Send(race.ChannelID, second, "!ban 1")
+	race = testGetRace(t, race.ChannelID)
+	if race.State != RaceStatePickingCharacters {
+		t.Fatalf("got a state of %s after the bans, want %s", race.State, RaceStatePickingCharacters)
+	}
+	picks := []string{
+		race.CharactersRemaining[0],
+		race.CharactersRemaining[1],
+		race.CharactersRemaining[3],
+	}
+
+	tests := []struct {
+		name    string
+		member  *discordgo.Member
+		content string
+		want    string
+	}{
+		{"no arguments", first, "!pick", "Pick something with: `!pick [number]`"},
+		{"not a racer", g.Admin, "!pick 1", "Only \"Dea1h\" and \"Cyber_1\" can ban something."},
+		{"not their turn", second, "!pick 1", "It is not your turn."},
+		{"not a number", first, "!pick one", "\"one\" is not a number."},
+		{"not a valid choice", first, "!pick 0", "\"0\" is not a valid choice."},
+		{"first pick", first, "!pick 1", first.User.Mention() + " picked **" + picks[0] + "**.\n" + second.User.Mention() + ", you're next!"},
+		{"second pick", second, "!pick 1", second.User.Mention() + " picked **" + picks[1] + "**.\n" + first.User.Mention() + ", you're next!"},
+		{"last pick", first, "!pick 2", "Match Summary"},
+		{"after the picks", second, "!pick 1", "You can only pick something once the banning phase has finished."},
+	}
+
+	for _, test := range tests {
+		g.Send(race.ChannelID, test.member, test.content)
+		if got := g.LastMessage(race.ChannelID); !strings.Contains(got, test.want) {
+			t.Errorf("%s: got a message of %q, want it to contain %q", test.name, got, test.want)
+		}
+	}
+
+	race = testGetRace(t, race.ChannelID)
+	if race.State != RaceStateInProgress {
+		t.Errorf("got a state of %s after the picks, want %s", race.State, RaceStateInProgress)
+	}
+	if strings.Join(race.Characters, ",") != strings.Join(picks, ",") {
+		t.Errorf("got the characters %v, want %v", race.Characters, picks)
+	}
+	for _, pick := range picks {
+		if stringInSlice(pick, race.CharactersRemaining) {
+			t.Errorf("the picked character \"%s\" is still remaining", pick)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func commandRating(m *discordgo.MessageCreate, args []string) {
+	if len(args) > 1 {
+		commandRatingPrint(m)
+		return
+	}
+
+	// The argument can be a mention of another racer.
+	discordUser := m.Author
+	if len(args) == 1 {
+		if !strings.HasPrefix(args[0], "<@") || len(m.Mentions) == 0 {
+			commandRatingPrint(m)
+			return
+		}
+		discordUser = m.Mentions[0]
+	}
+
+	var user *User
+	if v, err := userGet(discordUser); err != nil {
+		msg := "Failed to get the user from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		user = v
+	}
+
+	if user.RatedMatches == 0 {
+		discordSend(m.ChannelID, "**"+user.Username+"** has not played any rated matches yet.")
+		return
+	}
+
+	var leaderboard []*User
+	if v, err := modals.Users.GetLeaderboard(); err != nil {
+		msg := "Failed to get the leaderboard from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		leaderboard = v
+	}
+
+	rank := 0
+	for i, leaderboardUser := range leaderboard {
+		if leaderboardUser.DiscordID == user.DiscordID {
+			rank = i + 1
+			break
+		}
+	}
+
+	msg := "The rating for **" + user.Username + "** is **" + ratingGetDescription(user) + "**"
+	msg += " after " + strconv.Itoa(user.RatedMatches) + " match" + getPluralEs(user.RatedMatches)
+	if rank > 0 {
+		msg += " (rank " + strconv.Itoa(rank) + " of " + strconv.Itoa(len(leaderboard)) + ")"
+	}
+	msg += "."
+	discordSend(m.ChannelID, msg)
+}
+
+func getPluralEs(amount int) string {
+	if amount == 1 {
+		return ""
+	}
+
+	return "es"
+}
+
+func commandRatingPrint(m *discordgo.MessageCreate) {
+	msg := "Get a rating with:\n"
+	msg += "`!rating` - Your own rating\n"
+	msg += "`!rating @user` - The rating of another racer"
+	discordSend(m.ChannelID, msg)
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+func commandReload(m *discordgo.MessageCreate, args []string) {
+	if !isAdmin(m) {
+		return
+	}
+
+	if msg, applied, err := reload(); err != nil {
+		msg := reloadGetErrorMsg(applied, err)
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+	} else {
+		log.Info(msg)
+		discordSend(m.ChannelID, msg)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func commandResults(m *discordgo.MessageCreate, args []string) {
+	if len(args) > 1 {
+		commandResultsPrint(m)
+		return
+	}
+
+	// The argument can either be a round number or a mention of a racer.
+	var round, discordID, description string
+	if len(args) == 0 {
+		description = "All results"
+	} else if strings.HasPrefix(args[0], "<@") {
+		if len(m.Mentions) == 0 {
+			commandResultsPrint(m)
+			return
+		}
+		discordID = m.Mentions[0].ID
+		description = "Results for **" + m.Mentions[0].Username + "**"
+	} else {
+		round = args[0]
+		description = "Results for round **" + round + "**"
+	}
+
+	var results []*RaceResult
+	if v, err := modals.Races.GetAllCompleted(round, discordID); err != nil {
+		msg := "Failed to get the results from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		results = v
+	}
+
+	if len(results) == 0 {
+		discordSend(m.ChannelID, description+": there are no completed matches yet.")
+		return
+	}
+
+	msg := description + ":\n"
+	for _, result := range results {
+		msg += "- " + getResultDescription(result) + "\n"
+	}
+	discordSend(m.ChannelID, msg)
+}
+
+// Get a line for the "!results" command, e.g. "Season 1 (Round 2): **`Zamiel`** 3-1 `Dea1h`"
+func getResultDescription(result *RaceResult) string {
+	// Usernames are put in code blocks since underscores in usernames can mess up the formatting.
+	racer1 := "`" + result.Racer1Username + "`"
+	racer2 := "`" + result.Racer2Username + "`"
+	if result.Winner == 1 {
+		racer1 = "**" + racer1 + "**"
+	} else if result.Winner == 2 {
+		racer2 = "**" + racer2 + "**"
+	}
+
+	msg := result.TournamentName + " (" + bracketGetStage(result.BracketRound, result.BracketGroup) + "): "
+	msg += racer1 + " " + result.Score + " " + racer2
+	if result.DatetimeCompleted.Valid {
+		msg += " - " + result.DatetimeCompleted.Time.UTC().Format("2006-01-02")
+	}
+
+	return msg
+}
+
+func commandResultsPrint(m *discordgo.MessageCreate) {
+	msg := "Get the results of the completed matches with:\n"
+	msg += "`!results` - Every match\n"
+	msg += "`!results [round]` - The matches in a round\n"
+	msg += "`!results @user` - The matches of a racer\n"
+	msg += "e.g. `!results 2`"
+	discordSend(m.ChannelID, msg)
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"database/sql"
-	"strconv"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -38,66 +37,44 @@ func commandScore(m *discordgo.MessageCreate, args []string) {
 		return
 	}
 
-	// Check to see if this race is in progress.
-	if race.State != RaceStateInProgress {
-		discordSend(m.ChannelID, "You can only report the score once you have finished picking characters and builds.")
+	// Check to see if a score was already reported.
+	if race.State == RaceStateScorePending {
+		discordSend(m.ChannelID, "A score of \""+race.Score.String+"\" was already reported and is waiting to be confirmed with `!scoreok` or disputed with `!scoredispute`.")
 		return
 	}
 
-	// Check to see if this score was reported in the correct format.
-	// e.g. 3-0
-	score := args[0]
-	scoreValid := true
-	if len(score) != 3 {
-		scoreValid = false
-	}
-	if score[1] != '-' {
-		scoreValid = false
+	// Check to see if this race is in progress.
+	if !raceCanTrigger(race, RaceTriggerScoreReported) {
+		discordSend(m.ChannelID, "You can only report the score once you have finished picking characters and builds.")
+		return
 	}
-	minWinNum := 0
-	maxWinNum := tournaments[race.ChallongeURL].BestOf/2 + 1
 
-	// The first digit.
-	var digit1 int
-	if v, err := strconv.Atoi(string(score[0])); err != nil {
-		scoreValid = false
-	} else if v < minWinNum || v > maxWinNum {
-		scoreValid = false
+	// Check to see if the racers are reporting the games one by one.
+	var games []*RaceGame
+	if v, err := modals.RaceGames.GetAll(race.ChannelID); err != nil {
+		msg := "Failed to get the games from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
 	} else {
-		digit1 = v
+		games = v
 	}
-
-	// The second digit.
-	var digit2 int
-	if v, err := strconv.Atoi(string(score[2])); err != nil {
-		scoreValid = false
-	} else if v < minWinNum || v > maxWinNum {
-		scoreValid = false
-	} else {
-		digit2 = v
+	if len(games) > 0 {
+		discordSend(m.ChannelID, "The score of this match is calculated from the games reported with the `!game` command, so use that instead.")
+		return
 	}
 
-	if !scoreValid {
+	// Check to see if this score was reported in the correct format.
+	// e.g. 3-0
+	var digit1, digit2 int
+	if v1, v2, ok := scoreParse(race, args[0]); !ok {
 		msg := "You must report the score in the following format: `!score #-#`\n"
 		msg += "e.g. `!score 3-2`"
 		discordSend(m.ChannelID, msg)
 		return
-	}
-
-	// Get the winner's name.
-	var winnerName string
-	if racerNum == 1 {
-		if digit1 > digit2 {
-			winnerName = race.Racer1.Username
-		} else {
-			winnerName = race.Racer2.Username
-		}
-	} else if racerNum == 2 {
-		if digit1 > digit2 {
-			winnerName = race.Racer2.Username
-		} else {
-			winnerName = race.Racer1.Username
-		}
+	} else {
+		digit1 = v1
+		digit2 = v2
 	}
 
 	// Put the wins in the right order according to what is listed on the Challonge bracket.
@@ -109,46 +86,14 @@ func commandScore(m *discordgo.MessageCreate, args []string) {
 		p2Wins = digit1
 		p1Wins = digit2
 	}
-	score = strconv.Itoa(p1Wins) + "-" + strconv.Itoa(p2Wins)
-
-	// Get the Challonge participant ID of the winner.
-	var winnerID float64
-	if p1Wins > p2Wins {
-		winnerID = race.Racer1ChallongeID
-	} else {
-		winnerID = race.Racer2ChallongeID
-	}
-
-	// Update the match on Challonge:
-	// https://api.challonge.com/v1/documents/matches/update
-	challongeTournamentID := floatToString(tournaments[race.ChallongeURL].ChallongeID)
-	apiURL := "https://api.challonge.com/v1/tournaments/" + challongeTournamentID + "/matches/" + race.ChallongeMatchID + ".json"
-	apiURL += "?api_key=" + challongeAPIKey
-	apiURL += "&match[scores_csv]=" + score
-	apiURL += "&match[winner_id]=" + floatToString(winnerID)
-	if _, err := challongeGetJSON("PUT", apiURL, nil); err != nil {
-		msg := "Failed to get the tournament from Challonge: " + err.Error()
-		log.Error(msg)
-		discordSend(race.ChannelID, msg)
-		return
-	}
-
-	// Set the state.
-	race.State = RaceStateCompleted
-	if err := modals.Races.SetState(race.ChannelID, race.State); err != nil {
-		msg := "Failed to set the state for race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		discordSend(race.ChannelID, msg)
-		return
-	}
 
-	msg := "The score of \"" + score + "\" was successfully submitted (with " + winnerName + " winning the match)."
-	discordSend(m.ChannelID, msg)
+	scoreReport(race, m.Author, racerNum, p1Wins, p2Wins, "")
 }
 
 func commandScorePrint(m *discordgo.MessageCreate) {
 	msg := "Report the score of the match with: `!score [score]`\n"
 	msg += "e.g. `!score 3-2`\n"
-	msg += "The number of wins for the person reporting the score should come first."
+	msg += "The number of wins for the person reporting the score should come first.\n"
+	msg += "Your opponent will then need to confirm it with `!scoreok`."
 	discordSend(m.ChannelID, msg)
 }
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"database/sql"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func commandScoreDispute(m *discordgo.MessageCreate, args []string) {
+	// Check to see if this is a race channel (and get the race from the database).
+	var race *Race
+	if v, err := getRace(m.ChannelID); err == sql.ErrNoRows {
+		discordSend(m.ChannelID, "You can only use that command in a race channel.")
+		return
+	} else if err != nil {
+		msg := "Failed to get the race from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		race = v
+	}
+
+	// Check to see if this person is one of the two racers.
+	// (The person who reported the score can also dispute it, in case they made a typo.)
+	racerNum := raceGetRacerNum(race, m.Author.ID)
+	if racerNum == 0 {
+		discordSend(m.ChannelID, "Only \""+race.Racer1.Username+"\" and \""+race.Racer2.Username+"\" can dispute a score.")
+		return
+	}
+
+	// Check to see if there is a score to dispute.
+	if race.State != RaceStateScorePending {
+		discordSend(m.ChannelID, "There is no score waiting to be confirmed.")
+		return
+	}
+
+	score := race.Score.String
+	if err := modals.Races.ClearScore(race.ChannelID); err != nil {
+		msg := "Failed to clear the score for race \"" + race.Name() + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	}
+	race.Score = sql.NullString{}
+	race.Winner = 0
+	race.ScoreReporter = 0
+
+	// Set the state.
+	if err := raceTransition(race, RaceTriggerScoreDisputed, RaceStateInProgress); err != nil {
+		msg := "Failed to set the state for race \"" + race.Name() + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	}
+
+	if err := schedulerCancelAll(race.ChannelID, TimerActionScoreConfirm); err != nil {
+		msg := "Failed to cancel the score confirmation timer: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	}
+
+	raceEventRecord(race, m.Author, RaceEventTypeScoreDisputed, racerNum, score, nil)
+
+	msg := "<@&" + discordAdminRoleID + "> - " + m.Author.Mention() + " disputed the score of \"" + score + "\".\n"
+	msg += "The score was not submitted. Either racer can report the correct result again with `!game` "
+	msg += "(or `!score`), or an admin can set it with `!forcescore`."
+	discordSend(m.ChannelID, msg)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"database/sql"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func commandScoreOk(m *discordgo.MessageCreate, args []string) {
+	// Check to see if this is a race channel (and get the race from the database).
+	var race *Race
+	if v, err := getRace(m.ChannelID); err == sql.ErrNoRows {
+		discordSend(m.ChannelID, "You can only use that command in a race channel.")
+		return
+	} else if err != nil {
+		msg := "Failed to get the race from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		race = v
+	}
+
+	// Check to see if this person is one of the two racers.
+	racerNum := raceGetRacerNum(race, m.Author.ID)
+	if racerNum == 0 {
+		discordSend(m.ChannelID, "Only \""+race.Racer1.Username+"\" and \""+race.Racer2.Username+"\" can confirm a score.")
+		return
+	}
+
+	// Check to see if there is a score to confirm.
+	if race.State != RaceStateScorePending {
+		discordSend(m.ChannelID, "There is no score waiting to be confirmed. Use the `!score` command to report one.")
+		return
+	}
+
+	// Check to see if they are the one who reported it.
+	if racerNum == race.ScoreReporter {
+		discordSend(m.ChannelID, "You reported the score, so your opponent has to be the one to confirm it.")
+		return
+	}
+
+	raceEventRecord(race, m.Author, RaceEventTypeScoreConfirmed, racerNum, race.Score.String, nil)
+
+	if err := scoreSubmit(race, RaceTriggerScoreConfirmed); err != nil {
+		log.Error(err.Error())
+		discordSend(m.ChannelID, err.Error())
+		return
+	}
+
+	discordSend(m.ChannelID, scoreGetSubmittedMsg(race))
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"database/sql"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// SeedEntry is a participant of the bracket along with the rating that they are seeded by.
+type SeedEntry struct {
+	Participant *BracketParticipant
+	User        *User // Nil if they could not be found in the Discord server or the database.
+	Rating      float64
+}
+
+func commandSeed(m *discordgo.MessageCreate, args []string) {
+	if !isAdmin(m) {
+		return
+	}
+
+	// The tournament only has to be specified if there is more than one.
+	var tournament Tournament
+	if len(args) == 0 && len(tournaments) == 1 {
+		for _, v := range tournaments {
+			tournament = v
+		}
+	} else if len(args) != 1 {
+		commandSeedPrint(m)
+		return
+	} else if v, ok := tournaments[args[0]]; !ok {
+		discordSend(m.ChannelID, "\""+args[0]+"\" is not one of the current tournaments.")
+		return
+	} else {
+		tournament = v
+	}
+
+	var participants []*BracketParticipant
+	if v, err := getBracketProvider(tournament).GetParticipants(tournament); err != nil {
+		msg := "Failed to get the participants for tournament \"" + tournament.Name + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		participants = v
+	}
+
+	if len(participants) == 0 {
+		discordSend(m.ChannelID, "There are no participants in tournament \""+tournament.Name+"\".")
+		return
+	}
+
+	var entries []*SeedEntry
+	if v, err := seedGetEntries(tournament, participants); err != nil {
+		msg := "Failed to get the ratings of the participants: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		entries = v
+	}
+
+	participantIDs := make([]float64, 0)
+	for _, entry := range entries {
+		participantIDs = append(participantIDs, entry.Participant.ID)
+	}
+	if err := getBracketProvider(tournament).SetSeeds(tournament, participantIDs); err != nil {
+		msg := "Failed to set the seeds for tournament \"" + tournament.Name + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	}
+
+	msg := "The seeds for **" + tournament.Name + "** were set from the ratings of the participants:\n"
+	for i, entry := range entries {
+		msg += strconv.Itoa(i+1) + ". `" + entry.Participant.Name + "` - "
+		if entry.User == nil {
+			msg += ratingGetString(entry.Rating) + " (not found in the Discord server)\n"
+		} else if entry.User.RatedMatches == 0 {
+			msg += ratingGetString(entry.Rating) + " (unrated)\n"
+		} else {
+			msg += ratingGetDescription(entry.User) + "\n"
+		}
+	}
+	discordSend(m.ChannelID, msg)
+}
+
+// Get the participants from the highest rating to the lowest. Participants that have not played a
+// rated match (or that cannot be found) have the default rating.
+func seedGetEntries(tournament Tournament, participants []*BracketParticipant) ([]*SeedEntry, error) {
+	var members []*discordgo.Member
+	if v, err := getDiscordMembers(); err != nil {
+		return nil, err
+	} else {
+		members = v
+	}
+
+	var roles []*discordgo.Role
+	if v, err := discordSession.GuildRoles(discordGuildID); err != nil {
+		return nil, err
+	} else {
+		roles = v
+	}
+
+	entries := make([]*SeedEntry, 0)
+	for _, participant := range participants {
+		entry := &SeedEntry{
+			Participant: participant,
+			Rating:      ratingDefault,
+		}
+		entries = append(entries, entry)
+
+		// In team tournaments, the participants are the team roles and the rating is the rating of
+		// the team captain.
+		var discordUser *discordgo.User
+		if tournament.Ruleset == RulesetTeam {
+			if roleID, err := getDiscordRoleIDByName(roles, participant.Name); err == nil {
+				discordUser = getDiscordTeamCaptain(members, roleID)
+			}
+		} else {
+			discordUser = getDiscordUserByName(members, participant.Name)
+		}
+		if discordUser == nil {
+			continue
+		}
+
+		if v, err := modals.Users.GetFromDiscordID(discordUser.ID); err == sql.ErrNoRows {
+			// They have never been in a match, so they have the default rating.
+			entry.User = &User{
+				DiscordID: discordUser.ID,
+				Username:  participant.Name,
+				Rating:    ratingDefault,
+			}
+		} else if err != nil {
+			return nil, err
+		} else {
+			entry.User = v
+			entry.Rating = v.Rating
+		}
+	}
+
+	// Ties keep the order of the bracket.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Rating > entries[j].Rating
+	})
+
+	return entries, nil
+}
+
+func commandSeedPrint(m *discordgo.MessageCreate) {
+	msg := "Seed a tournament by the ratings of the participants with: `!seed [challonge url suffix]`\n"
+	msg += "(This can only be done before the tournament has started.)\n"
+	msg += "e.g. `!seed " + strings.Join(getTournamentURLs(), "`, `!seed ") + "`"
+	discordSend(m.ChannelID, msg)
+}
+
+func getTournamentURLs() []string {
+	urls := make([]string, 0)
+	for url := range tournaments {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	return urls
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func commandStandings(m *discordgo.MessageCreate, args []string) {
+	if len(seasons) == 0 {
+		discordSend(m.ChannelID, "There are no seasons configured.")
+		return
+	}
+
+	// The season only has to be specified if there is more than one.
+	var season Season
+	if len(args) == 0 && len(seasons) == 1 {
+		for _, v := range seasons {
+			season = v
+		}
+	} else if len(args) == 0 {
+		commandStandingsPrint(m)
+		return
+	} else if v, ok := getSeasonByName(strings.Join(args, " ")); !ok {
+		discordSend(m.ChannelID, "\""+strings.Join(args, " ")+"\" is not a valid season.")
+		commandStandingsPrint(m)
+		return
+	} else {
+		season = v
+	}
+
+	if msg, err := seasonGetStandingsMsg(season); err != nil {
+		msg := "Failed to get the standings for season \"" + season.Name + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+	} else {
+		discordSend(m.ChannelID, msg)
+	}
+}
+
+// Season names are not case sensitive.
+func getSeasonByName(name string) (Season, bool) {
+	for _, season := range seasons {
+		if strings.EqualFold(season.Name, name) {
+			return season, true
+		}
+	}
+
+	return Season{}, false
+}
+
+func commandStandingsPrint(m *discordgo.MessageCreate) {
+	names := make([]string, 0)
+	for name := range seasons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	msg := "Get the standings of a season with: `!standings [season]`\n"
+	msg += "The seasons are: **" + strings.Join(names, "**, **") + "**"
+	discordSend(m.ChannelID, msg)
+}
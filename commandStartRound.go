@@ -2,8 +2,9 @@ package main
 
 import (
 	"database/sql"
-	"encoding/json"
 	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -29,39 +30,67 @@ func commandStartRound(m *discordgo.MessageCreate, args []string) {
 
 	// Go through all of the tournaments.
 	for _, tournament := range tournaments {
-		startRound(m, tournament, false)
+		startRound(m, tournament, StartRoundOptions{})
 	}
 }
 
-func startRound(m *discordgo.MessageCreate, tournament Tournament, dryRun bool) {
-	// Get the tournament from Challonge.
-	apiURL := "https://api.challonge.com/v1/tournaments/" + floatToString(tournament.ChallongeID) + ".json?"
-	apiURL += "api_key=" + challongeAPIKey + "&include_participants=1&include_matches=1"
-	var raw []byte
-	if v, err := challongeGetJSON("GET", apiURL, nil); err != nil {
-		msg := "Failed to get the tournament from Challonge: " + err.Error()
-		log.Error(msg)
-		discordSend(m.ChannelID, msg)
-		return
-	} else {
-		raw = v
+// StartRoundOptions changes which open matches get a channel and who is told about it.
+type StartRoundOptions struct {
+	// Check that every racer can be found without creating anything.
+	DryRun bool
+
+	// The round start was not requested by an admin (e.g. it was started by the bracket poller or
+	// by a reported score), so nothing is sent to the channel of the message, errors are only
+	// logged, and the new match channels explain why they were created.
+	Automatic bool
+
+	// If not empty, only the matches of these participants are started.
+	ParticipantIDs []float64
+}
+
+// Create a channel and a race for every open match that does not already have one, so this is safe
+// to run as many times as needed.
+func startRound(m *discordgo.MessageCreate, tournament Tournament, options StartRoundOptions) {
+	dryRun := options.DryRun
+	automatic := options.Automatic
+	report := func(msg string) {
+		if !automatic {
+			discordSend(m.ChannelID, msg)
+		}
 	}
 
-	vMap := make(map[string]interface{})
-	if err := json.Unmarshal(raw, &vMap); err != nil {
-		msg := "Failed to unmarshal the Challonge JSON: " + err.Error()
-		log.Error(msg)
-		discordSend(m.ChannelID, msg)
-		return
+	// Swiss and round-robin tournaments pair their next round once the current round is complete.
+	// (A dry run only shows what the next round would be, without saving it.)
+	var matches []*BracketMatch
+	if pairingIsNative(tournament) {
+		if v, err := pairingPairNextRound(tournament, !dryRun); err != nil {
+			msg := "Failed to pair the next round for tournament \"" + tournament.Name + "\": " + err.Error()
+			log.Error(msg)
+			report(msg)
+			return
+		} else if dryRun && len(v) > 0 {
+			matches = pairingGetMatches(v, pairingGetOpenRound(v))
+		}
+	}
+
+	// Get the open matches from the bracket.
+	if matches == nil {
+		if v, err := getBracketProvider(tournament).GetOpenMatches(tournament); err != nil {
+			msg := "Failed to get the open matches for tournament \"" + tournament.Name + "\": " + err.Error()
+			log.Error(msg)
+			report(msg)
+			return
+		} else {
+			matches = v
+		}
 	}
-	jsonTournament := vMap["tournament"].(map[string]interface{})
 
 	// Get the Discord guild members.
 	var discordMembers []*discordgo.Member
 	if v, err := discordSession.GuildMembers(discordGuildID, "0", 1000); err != nil {
 		msg := "Failed to get the Discord guild members: " + err.Error()
 		log.Error(msg)
-		discordSend(m.ChannelID, msg)
+		report(msg)
 		return
 	} else {
 		discordMembers = v
@@ -72,52 +101,103 @@ func startRound(m *discordgo.MessageCreate, tournament Tournament, dryRun bool)
 	if v, err := discordSession.GuildRoles(discordGuildID); err != nil {
 		msg := "Failed to get the roles for the guild: " + err.Error()
 		log.Error(msg)
-		discordSend(m.ChannelID, msg)
+		report(msg)
 		return
 	} else {
 		discordRoles = v
 	}
 
-	// Get all of the open matches.
-	foundMatches := false
-	foundPlayers := make([]string, 0)
-	var round string
-	for _, v := range jsonTournament["matches"].([]interface{}) {
-		vMap := v.(map[string]interface{})
-		match := vMap["match"].(map[string]interface{})
-		if match["state"] != "open" {
-			continue
+	// Get the races that were already created for this tournament, so that the same match never
+	// gets a second channel.
+	var existingRaces []*RaceMatch
+	if v, err := modals.Races.GetAllMatches(tournament.ChallongeURL); err != nil {
+		msg := "Failed to get the existing races from the database: " + err.Error()
+		log.Error(msg)
+		report(msg)
+		return
+	} else {
+		existingRaces = v
+	}
+
+	// Racers that are still playing a match already have a channel open. The names of the existing
+	// channels are taken as well, so that a new match between the same two players gets a channel
+	// that can be told apart from the old one.
+	foundPlayers := make([]float64, 0)
+	foundChannelNames := make([]string, 0)
+	for _, existingRace := range existingRaces {
+		if existingRace.State != RaceStateCompleted {
+			foundPlayers = append(foundPlayers, existingRace.Racer1ChallongeID, existingRace.Racer2ChallongeID)
 		}
+		foundChannelNames = append(foundChannelNames, existingRace.ChannelName)
+	}
+
+	// In automatic mode, a match that fails to start is skipped so that it does not hold up the
+	// rest of the bracket, and the admins are told about it once at the end.
+	failures := make([]string, 0)
 
+	// Get all of the open matches.
+	foundMatches := false
+	numCreated := 0
+	stages := make([]string, 0)
+	for _, match := range matches {
 		foundMatches = true
-		player1ID := match["player1_id"].(float64)
-		player2ID := match["player2_id"].(float64)
-		player1Name := challongeGetParticipantName(jsonTournament, player1ID)
-		player2Name := challongeGetParticipantName(jsonTournament, player2ID)
-		challongeMatchID := floatToString(match["id"].(float64))
-		channelName := player1Name + "-vs-" + player2Name
-
-		// Check to see if we have already created a channel for either of these players.
-		playerAlreadyHasChannel := false
-		for _, p := range foundPlayers {
-			if p == player1Name || p == player2Name {
-				playerAlreadyHasChannel = true
+		player1ID := match.Player1ID
+		player2ID := match.Player2ID
+		player1Name := match.Player1Name
+		player2Name := match.Player2Name
+		challongeMatchID := match.ID
+		channelName := bracketGetChannelPrefix(match.Round, match.Group) + player1Name + "-vs-" + player2Name
+		stage := bracketGetStage(match.Round, match.Group)
+
+		// Check to see if this match already has a channel.
+		alreadyCreated := false
+		for _, existingRace := range existingRaces {
+			if existingRace.ChallongeMatchID == challongeMatchID {
+				alreadyCreated = true
 				break
 			}
 		}
-		if playerAlreadyHasChannel {
-			log.Info("Skipping match \"" + channelName + "\" since one of the players already has a channel open.")
+		if alreadyCreated {
+			if !stringInSlice(stage, stages) {
+				stages = append(stages, stage)
+			}
+			continue
+		}
+
+		// Check to see if this match is one of the matches that we are looking for.
+		if len(options.ParticipantIDs) > 0 &&
+			!floatInSlice(player1ID, options.ParticipantIDs) &&
+			!floatInSlice(player2ID, options.ParticipantIDs) {
+
 			continue
 		}
-		foundPlayers = append(foundPlayers, player1Name)
-		foundPlayers = append(foundPlayers, player2Name)
-		round = floatToString(match["round"].(float64))
+
+		// Check to see if we have already created a channel for either of these players. (Some
+		// formats have several open matches for each player at once.)
+		if !tournament.ConcurrentMatches {
+			if floatInSlice(player1ID, foundPlayers) || floatInSlice(player2ID, foundPlayers) {
+				log.Info("Skipping match \"" + channelName + "\" since one of the players already has a channel open.")
+				continue
+			}
+		}
+		foundPlayers = append(foundPlayers, player1ID, player2ID)
+
+		// The same two players can have more than one open match (e.g. in a double round-robin), so
+		// the identifier of the match is added to tell the channels apart.
+		if stringInSlice(channelName, foundChannelNames) && match.Identifier != "" {
+			channelName += "-" + strings.ToLower(match.Identifier)
+		}
+		foundChannelNames = append(foundChannelNames, channelName)
 
 		var racer1DiscordID string
 		var racer2DiscordID string
 		if v1, v2, err := getDiscordIDsForMatch(tournament, discordMembers, discordRoles, player1Name, player2Name); err != nil {
 			log.Error(err)
-			discordSend(m.ChannelID, err.Error())
+			if automatic {
+				failures = append(failures, err.Error())
+				continue
+			}
+			report(err.Error())
 			return
 		} else {
 			racer1DiscordID = v1
@@ -136,81 +216,116 @@ func startRound(m *discordgo.MessageCreate, tournament Tournament, dryRun bool)
 			racer2DiscordID,
 		); err != nil {
 			log.Error(err)
-			discordSend(m.ChannelID, err.Error())
+			if automatic {
+				failures = append(failures, err.Error())
+				continue
+			}
+			report(err.Error())
 			return
 		} else {
 			channelID = v
 		}
 
-		buildsRemaining := make([]string, 0)
-		for _, build := range builds {
-			buildsRemaining = append(buildsRemaining, build.Name)
-		}
-
 		// Create the race in the database.
 		race := &Race{
 			TournamentName:      tournament.Name,
+			Season:              tournament.Season,
 			Racer1ChallongeID:   player1ID,
 			Racer2ChallongeID:   player2ID,
 			ChannelID:           channelID,
 			ChannelName:         channelName,
 			ChallongeURL:        tournament.ChallongeURL,
 			ChallongeMatchID:    challongeMatchID,
-			BracketRound:        round,
+			BracketRound:        match.Round,
+			BracketGroupID:      match.GroupID,
+			BracketGroup:        match.Group,
+			BracketIdentifier:   match.Identifier,
 			State:               RaceStateInitial,
-			CharactersRemaining: characters,
-			BuildsRemaining:     buildsRemaining,
+			CharactersRemaining: tournament.Characters,
+			BuildsRemaining:     tournament.Builds,
 			Racer1Bans:          0, // Initialized before banning begins.
 			Racer2Bans:          0, // Initialized before banning begins.
 			Racer1Vetos:         0, // Initialized before vetoing begins.
 			Racer2Vetos:         0, // Initialized before vetoing begins.
 		}
 		if err := modals.Races.Insert(racer1DiscordID, racer2DiscordID, race); err != nil {
-			msg := "Failed to create the race in the database: " + err.Error()
+			msg := "Failed to create the race of \"" + channelName + "\" in the database: " + err.Error()
 			log.Error(msg)
-			discordSend(m.ChannelID, msg)
+			if automatic {
+				failures = append(failures, msg)
+				continue
+			}
+			report(msg)
 			return
 		}
 
 		// We re-get the race in the database so that the racer fields are filled in properly.
 		if v, err := getRace(channelID); err != nil {
-			msg := "Failed to get the race from the database: " + err.Error()
+			msg := "Failed to get the race of \"" + channelName + "\" from the database: " + err.Error()
 			log.Error(msg)
-			discordSend(m.ChannelID, msg)
+			if automatic {
+				failures = append(failures, msg)
+				continue
+			}
+			report(msg)
 			return
 		} else {
 			race = v
 		}
 
 		// Send the introductory messages for the Discord channel.
+		if automatic {
+			msg := "**" + race.Stage() + "** of **" + tournament.Name + "**: this match just opened up on the bracket."
+			discordSend(race.ChannelID, msg)
+		}
 		announceStatus(m, race, true)
 
+		if !stringInSlice(stage, stages) {
+			stages = append(stages, stage)
+		}
+		numCreated++
 		log.Info("Started race: " + channelName)
 	}
 
+	if len(failures) > 0 {
+		msg := "Failed to start " + strconv.Itoa(len(failures)) + " match" + getPluralEs(len(failures)) + " that opened up on the bracket for tournament \"" + tournament.Name + "\":\n"
+		for _, failure := range failures {
+			msg += "- " + failure + "\n"
+		}
+		msg += "Fix the problem and use `!startround` to try again."
+		discordSend(discordGeneralChannelID, msg)
+	}
+
 	if dryRun {
 		msg := "Tournament \"" + tournament.Name + "\" looks good."
-		discordSend(m.ChannelID, msg)
+		report(msg)
 		log.Info(msg)
 		return
 	}
 
-	// Rename the channel category.
-	categoryName := "Round " + round + " - " + string(tournament.Ruleset)
-	if _, err := discordSession.ChannelEdit(tournament.DiscordCategoryID, categoryName); err != nil {
-		msg := "Failed to rename the channel category: " + err.Error()
-		log.Error(msg)
-		discordSend(m.ChannelID, msg)
-		return
-	}
+	if numCreated > 0 {
+		// Rename the channel category after the stages of the bracket that are being played.
+		categoryName := bracketGetCategoryName(stages, tournament.Ruleset)
+		if _, err := discordSession.ChannelEdit(tournament.DiscordCategoryID, categoryName); err != nil {
+			msg := "Failed to rename the channel category: " + err.Error()
+			log.Error(msg)
+			report(msg)
+			return
+		}
 
-	if foundMatches {
-		msg := "Round " + round + " channels created for tournament \"" + tournament.Name + "\"."
-		discordSend(m.ChannelID, msg)
+		msg := strconv.Itoa(numCreated) + " channel" + getPlural(numCreated) + " created for tournament \"" + tournament.Name + "\" (" + strings.Join(stages, ", ") + ")."
+		report(msg)
+		log.Info(msg)
+	} else if automatic {
+		// The poller runs often, so there is no need to log that nothing changed.
+		return
+	} else if foundMatches {
+		msg := "Every open match on the bracket for tournament \"" + tournament.Name + "\" already has a channel."
+		report(msg)
 		log.Info(msg)
 	} else {
-		msg := "There are no open matches on the Challonge bracket for tournament \"" + tournament.Name + "\"."
-		discordSend(m.ChannelID, msg)
+		msg := "There are no open matches on the bracket for tournament \"" + tournament.Name + "\"."
+		report(msg)
 		log.Info(msg)
 	}
 }
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	testChallongeID     = 1000
+	testChallongeAPIKey = "test_api_key"
+)
+
+// A four player single elimination bracket with a match for third place. The final and the match
+// for third place wait for the semifinals.
+func testChallongeFixture() []*LocalTournament {
+	return []*LocalTournament{
+		{
+			ID:   testChallongeID,
+			URL:  testTournamentURL,
+			Name: "Test Tournament",
+			Participants: []LocalParticipant{
+				{ID: 1, Name: "Dea1h"},
+				{ID: 2, Name: "Cyber_1"},
+				{ID: 3, Name: "Moucheron"},
+				{ID: 4, Name: "Krakenos"},
+			},
+			Matches: []LocalMatch{
+				{ID: 101, Round: 1, Identifier: "A", State: "open", Player1ID: 1, Player2ID: 4},
+				{ID: 102, Round: 1, Identifier: "B", State: "open", Player1ID: 2, Player2ID: 3},
+				{ID: 103, Round: 2, Identifier: "C", State: "pending"},
+				{ID: 104, Round: 2, Identifier: "D", State: "pending"},
+			},
+		},
+	}
+}
+
+// Serve the fixture from a mock Challonge server and use it as the bracket for the test.
+func testStartChallonge(t *testing.T) *ChallongeMockServer {
+	t.Helper()
+
+	mock := NewChallongeMockServer(testChallongeFixture(), testChallongeAPIKey)
+	mock.SetPrereqs(103, ChallongeMockPrereq{MatchID: 101}, ChallongeMockPrereq{MatchID: 102})
+	mock.SetPrereqs(104, ChallongeMockPrereq{MatchID: 101, Loser: true}, ChallongeMockPrereq{MatchID: 102, Loser: true})
+
+	oldBracketProvider := bracketProvider
+	bracketProvider = &ChallongeProvider{}
+	challongeAPIURL = mock.URL()
+	challongeAPIKey = testChallongeAPIKey
+	t.Cleanup(func() {
+		mock.Close()
+		bracketProvider = oldBracketProvider
+		challongeAPIURL = ""
+		challongeAPIKey = ""
+	})
+
+	return mock
+}
+
+func TestRoundLifecycle(t *testing.T) {
+	g := testSetup(t, Tournament{
+		Ruleset:     RulesetUnseeded,
+		Type:        TournamentTypeVeto,
+		BestOf:      1,
+		ChallongeID: testChallongeID,
+	})
+	mock := testStartChallonge(t)
+	members := map[string]*discordgo.Member{
+		"Dea1h":     g.Racer1,
+		"Cyber_1":   g.Racer2,
+		"Moucheron": g.Session.AddMember("Moucheron"),
+		"Krakenos":  g.Session.AddMember("Krakenos"),
+	}
+
+	// The semifinals get a channel when the round is started.
+	g.Send(discordGeneralChannelID, g.Admin, "!startround")
+	want := "2 channels created for tournament \"Test Tournament\" (Round 1)."
+	if got := g.LastMessage(discordGeneralChannelID); got != want {
+		t.Fatalf("got a message of %q, want %q", got, want)
+	}
+	races := testGetRacesByMatch(t)
+	testCheckRaceChannel(t, g, races["101"], "Dea1h-vs-Krakenos")
+	testCheckRaceChannel(t, g, races["102"], "Cyber_1-vs-Moucheron")
+	testCheckCategoryName(t, g, "Round 1 - unseeded")
+
+	g.Send(discordGeneralChannelID, g.Admin, "!startround")
+	want = "Every open match on the bracket for tournament \"Test Tournament\" already has a channel."
+	if got := g.LastMessage(discordGeneralChannelID); got != want {
+		t.Errorf("got a message of %q when starting the round again, want %q", got, want)
+	}
+
+	// The final waits for both semifinals.
+	testPlayMatch(t, g, members, races["101"], "Dea1h")
+	testCheckMatch(t, mock, 101, "complete", 1, 4, 1)
+	testCheckMatch(t, mock, 103, "pending", 1, 0, 0)
+	testCheckMatch(t, mock, 104, "pending", 4, 0, 0)
+	if races := testGetRacesByMatch(t); len(races) != 2 {
+		t.Errorf("got %d races after the first semifinal, want 2", len(races))
+	}
+
+	// Once both semifinals are done, the final and the match for third place open up and get a
+	// channel without waiting for "!startround".
+	testPlayMatch(t, g, members, races["102"], "Cyber_1")
+	testCheckMatch(t, mock, 102, "complete", 2, 3, 2)
+	testCheckMatch(t, mock, 103, "open", 1, 2, 0)
+	testCheckMatch(t, mock, 104, "open", 4, 3, 0)
+	races = testGetRacesByMatch(t)
+	testCheckRaceChannel(t, g, races["103"], "Dea1h-vs-Cyber_1")
+	testCheckRaceChannel(t, g, races["104"], "Krakenos-vs-Moucheron")
+	testCheckCategoryName(t, g, "Round 2 - unseeded")
+	for _, matchID := range []string{"103", "104"} {
+		messages := g.Session.GetMessages(races[matchID].ChannelID)
+		want := "**Round 2** of **Test Tournament**: this match just opened up on the bracket."
+		if len(messages) == 0 || messages[0] != want {
+			t.Errorf("got the messages %q in the channel of match %s, want the first one to be %q", messages, matchID, want)
+		}
+	}
+
+	testPlayMatch(t, g, members, races["103"], "Cyber_1")
+	testPlayMatch(t, g, members, races["104"], "Krakenos")
+	testCheckMatch(t, mock, 103, "complete", 1, 2, 2)
+	testCheckMatch(t, mock, 104, "complete", 4, 3, 4)
+
+	// Ending the round cleans up every channel, but the results are kept.
+	g.Send(discordGeneralChannelID, g.Admin, "!endround delete")
+	for matchID, race := range races {
+		if _, err := g.Session.Channel(race.ChannelID); err == nil {
+			t.Errorf("the channel of match %s was not deleted", matchID)
+		}
+	}
+	if races := testGetRacesByMatch(t); len(races) != 4 {
+		t.Errorf("got %d races after the round ended, want the 4 results", len(races))
+	}
+	want = " (The result of the match was kept.)"
+	if got := g.LastMessage(discordGeneralChannelID); !strings.HasSuffix(got, want) {
+		t.Errorf("got a message of %q, want it to end with %q", got, want)
+	}
+}
+
+func TestStartRoundRematch(t *testing.T) {
+	g := testSetup(t, Tournament{
+		Ruleset:     RulesetUnseeded,
+		Type:        TournamentTypeVeto,
+		BestOf:      1,
+		ChallongeID: testChallongeID,
+	})
+	testStartChallonge(t)
+	g.Session.AddMember("Moucheron")
+	g.Session.AddMember("Krakenos")
+
+	// An earlier match between the same two players still has its channel.
+	race := g.AddRace(t, RaceStateCompleted)
+	if _, err := db.Exec("UPDATE tournament_races SET channel_name = ? WHERE channel_id = ?", "Dea1h-vs-Krakenos", race.ChannelID); err != nil {
+		t.Fatalf("Failed to rename the race: %v", err)
+	}
+
+	g.Send(discordGeneralChannelID, g.Admin, "!startround")
+	races := testGetRacesByMatch(t)
+	testCheckRaceChannel(t, g, races["101"], "Dea1h-vs-Krakenos-a")
+	testCheckRaceChannel(t, g, races["102"], "Cyber_1-vs-Moucheron")
+}
+
+func TestStartRoundAutomaticFailure(t *testing.T) {
+	g := testSetup(t, Tournament{
+		Ruleset:     RulesetUnseeded,
+		Type:        TournamentTypeVeto,
+		BestOf:      1,
+		ChallongeID: testChallongeID,
+	})
+	testStartChallonge(t)
+	g.Session.AddMember("Moucheron") // "Krakenos" is not in the server.
+
+	// A match that cannot be started does not stop the other matches from getting a channel.
+	m := &discordgo.MessageCreate{
+		Message: &discordgo.Message{
+			ChannelID: discordGeneralChannelID,
+		},
+	}
+	startRound(m, tournaments[testTournamentURL], StartRoundOptions{
+		Automatic: true,
+	})
+	races := testGetRacesByMatch(t)
+	if len(races) != 1 {
+		t.Fatalf("got %d races, want 1", len(races))
+	}
+	testCheckRaceChannel(t, g, races["102"], "Cyber_1-vs-Moucheron")
+
+	want := "Failed to start 1 match that opened up on the bracket for tournament \"Test Tournament\":\n"
+	want += "- Failed to find \"Krakenos\" in the Discord server.\n"
+	want += "Fix the problem and use `!startround` to try again."
+	if got := g.LastMessage(discordGeneralChannelID); got != want {
+		t.Errorf("got a message of %q, want %q", got, want)
+	}
+}
+
+// Start the race and have the winner report a score of 1-0, which the other racer confirms.
+func testPlayMatch(t *testing.T, g *TestGuild, members map[string]*discordgo.Member, race *Race, winnerName string) {
+	t.Helper()
+
+	// Skip agreeing on a time, since that is not what is being tested.
+	if err := modals.Races.SetStateFrom(race.ChannelID, RaceStateInitial, RaceStateScheduled); err != nil {
+		t.Fatalf("Failed to schedule race \"%s\": %v", race.Name(), err)
+	}
+	race = g.StartMatch(t, testGetRace(t, race.ChannelID))
+	if race.State != RaceStateInProgress {
+		t.Fatalf("got a state of %s for race \"%s\" after the match started, want %s", race.State, race.Name(), RaceStateInProgress)
+	}
+
+	loserName := race.Racer1.Username
+	if loserName == winnerName {
+		loserName = race.Racer2.Username
+	}
+	g.Send(race.ChannelID, members[winnerName], "!score 1-0")
+	g.Send(race.ChannelID, members[loserName], "!scoreok")
+
+	race = testGetRace(t, race.ChannelID)
+	want := "The score of \"" + race.Score.String + "\" was successfully submitted (with " + winnerName + " winning the match)."
+	if got := g.LastMessage(race.ChannelID); got != want {
+		t.Fatalf("got a message of %q in race \"%s\", want %q", got, race.Name(), want)
+	}
+	if race.State != RaceStateCompleted {
+		t.Fatalf("got a state of %s for race \"%s\" after the score was confirmed, want %s", race.State, race.Name(), RaceStateCompleted)
+	}
+}
+
+// Get the races of the tournament, indexed by the ID of their match on the bracket.
+func testGetRacesByMatch(t *testing.T) map[string]*Race {
+	t.Helper()
+
+	var raceMatches []*RaceMatch
+	if v, err := modals.Races.GetAllMatches(testTournamentURL); err != nil {
+		t.Fatalf("Failed to get the races from the database: %v", err)
+	} else {
+		raceMatches = v
+	}
+
+	races := make(map[string]*Race)
+	for _, raceMatch := range raceMatches {
+		races[raceMatch.ChallongeMatchID] = testGetRace(t, raceMatch.ChannelID)
+	}
+
+	return races
+}
+
+func testCheckRaceChannel(t *testing.T, g *TestGuild, race *Race, name string) {
+	t.Helper()
+
+	if race == nil {
+		t.Fatalf("there is no race for \"%s\"", name)
+	}
+	if race.ChannelName != name {
+		t.Errorf("got a race of \"%s\", want \"%s\"", race.ChannelName, name)
+	}
+
+	var channel *discordgo.Channel
+	if v, err := g.Session.Channel(race.ChannelID); err != nil {
+		t.Fatalf("Failed to get the channel of \"%s\": %v", name, err)
+	} else {
+		channel = v
+	}
+	if channel.Name != name {
+		t.Errorf("got a channel name of \"%s\", want \"%s\"", channel.Name, name)
+	}
+	if channel.ParentID != tournaments[testTournamentURL].DiscordCategoryID {
+		t.Errorf("the channel of \"%s\" is not in the tournament category", name)
+	}
+}
+
+func testCheckCategoryName(t *testing.T, g *TestGuild, name string) {
+	t.Helper()
+
+	if category, err := g.Session.Channel(tournaments[testTournamentURL].DiscordCategoryID); err != nil {
+		t.Errorf("Failed to get the tournament category: %v", err)
+	} else if category.Name != name {
+		t.Errorf("got a category name of \"%s\", want \"%s\"", category.Name, name)
+	}
+}
+
+func testCheckMatch(t *testing.T, mock *ChallongeMockServer, matchID float64, state string, player1ID float64, player2ID float64, winnerID float64) {
+	t.Helper()
+
+	match, ok := mock.GetMatch(testChallongeID, matchID)
+	if !ok {
+		t.Fatalf("match %v is not on the bracket", matchID)
+	}
+	if match.State != state || match.Player1ID != player1ID || match.Player2ID != player2ID || match.WinnerID != winnerID {
+		t.Errorf(
+			"got match %v in the state \"%s\" with players %v and %v and a winner of %v, want \"%s\" with players %v and %v and a winner of %v",
+			matchID, match.State, match.Player1ID, match.Player2ID, match.WinnerID,
+			state, player1ID, player2ID, winnerID,
+		)
+	}
+}
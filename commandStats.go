@@ -0,0 +1,280 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// StatsRow is one line of a "!stats" table.
+type StatsRow struct {
+	Name  string
+	Bans  int
+	Picks int
+	Vetos int
+	Games int
+
+	// The wins and games that the win rate is calculated from. (This is either the win rate of the
+	// racer who picked it or the win rate of the racer that the statistics are for.)
+	WinRateWins  int
+	WinRateGames int
+}
+
+func commandStats(m *discordgo.MessageCreate, args []string) {
+	if len(args) == 0 {
+		commandStatsPrint(m)
+		return
+	}
+
+	filter := &StatsFilter{}
+	var filterDescription string
+	if v1, v2, ok := commandStatsParseFilter(args[1:], filter); !ok {
+		commandStatsPrint(m)
+		return
+	} else if v1 != "" {
+		filterDescription = " (" + v1 + " **" + v2 + "**)"
+	}
+
+	if strings.HasPrefix(args[0], "<@") {
+		if len(m.Mentions) == 0 {
+			commandStatsPrint(m)
+			return
+		}
+		filter.DiscordID = m.Mentions[0].ID
+		commandStatsRacer(m, m.Mentions[0], filter, filterDescription)
+		return
+	}
+
+	var draftType, description string
+	switch strings.ToLower(args[0]) {
+	case "characters", "character", "chars", "char":
+		draftType = DraftTypeCharacter
+		description = "Character statistics"
+	case "builds", "build", "items", "item":
+		draftType = DraftTypeBuild
+		description = "Build statistics"
+	default:
+		commandStatsPrint(m)
+		return
+	}
+
+	var rows []*StatsRow
+	if v, err := commandStatsGetRows(draftType, filter); err != nil {
+		msg := "Failed to get the statistics from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		rows = v
+	}
+
+	if len(rows) == 0 {
+		discordSend(m.ChannelID, description+filterDescription+": there are no completed matches yet.")
+		return
+	}
+
+	msg := description + filterDescription + ":\n"
+	msg += "(The win rate is for the racer who picked it.)\n"
+	msg += commandStatsGetTable(rows, "Picker win %")
+	discordSend(m.ChannelID, msg)
+}
+
+func commandStatsRacer(m *discordgo.MessageCreate, user *discordgo.User, filter *StatsFilter, filterDescription string) {
+	var matchWins, matchLosses int
+	if v1, v2, err := modals.Stats.GetMatchRecord(filter); err != nil {
+		msg := "Failed to get the match record from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		matchWins = v1
+		matchLosses = v2
+	}
+
+	var characterRows, buildRows []*StatsRow
+	if v, err := commandStatsGetRows(DraftTypeCharacter, filter); err != nil {
+		msg := "Failed to get the statistics from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		characterRows = v
+	}
+	if v, err := commandStatsGetRows(DraftTypeBuild, filter); err != nil {
+		msg := "Failed to get the statistics from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		buildRows = v
+	}
+
+	description := "Statistics for **" + user.Username + "**" + filterDescription
+	if matchWins+matchLosses == 0 {
+		discordSend(m.ChannelID, description+": there are no completed matches yet.")
+		return
+	}
+
+	// Every game has a character, so the characters can be used to count the games.
+	var gameWins, games int
+	for _, row := range characterRows {
+		gameWins += row.WinRateWins
+		games += row.WinRateGames
+	}
+
+	msg := description + ":\n"
+	msg += "- Matches: " + strconv.Itoa(matchWins) + "-" + strconv.Itoa(matchLosses)
+	msg += " (" + getPercent(matchWins, matchWins+matchLosses) + " won)\n"
+	if games > 0 {
+		msg += "- Games: " + strconv.Itoa(gameWins) + "-" + strconv.Itoa(games-gameWins)
+		msg += " (" + getPercent(gameWins, games) + " won)\n"
+	}
+	if len(characterRows) > 0 {
+		msg += "Characters (their own bans, picks, and vetos):\n"
+		msg += commandStatsGetTable(characterRows, "Win %")
+	}
+	if len(buildRows) > 0 {
+		msg += "Builds (their own bans, picks, and vetos):\n"
+		msg += commandStatsGetTable(buildRows, "Win %")
+	}
+	discordSend(m.ChannelID, msg)
+}
+
+// Parse the optional filter at the end of the command, e.g. "season Season 1" or
+// "tournament isaac-season-1". Returns the type and the value of the filter (for the message) and
+// whether or not it was valid.
+func commandStatsParseFilter(args []string, filter *StatsFilter) (string, string, bool) {
+	if len(args) == 0 {
+		return "", "", true
+	}
+	if len(args) < 2 {
+		return "", "", false
+	}
+
+	value := strings.Join(args[1:], " ")
+	switch strings.ToLower(args[0]) {
+	case "tournament":
+		if len(args) != 2 {
+			return "", "", false
+		}
+		filter.ChallongeURL = value
+		return "tournament", value, true
+	case "season":
+		filter.Season = value
+		return "season", value, true
+	}
+
+	return "", "", false
+}
+
+// Combine the draft and the game statistics into one row per character or build, sorted by how
+// often they came up.
+func commandStatsGetRows(draftType string, filter *StatsFilter) ([]*StatsRow, error) {
+	var draftCounts []*StatsDraftCount
+	if v, err := modals.Stats.GetDraftCounts(draftType, filter); err != nil {
+		return nil, err
+	} else {
+		draftCounts = v
+	}
+
+	var gameCounts []*StatsGameCount
+	if v, err := modals.Stats.GetGameCounts(draftType, filter); err != nil {
+		return nil, err
+	} else {
+		gameCounts = v
+	}
+
+	rowMap := make(map[string]*StatsRow)
+	getRow := func(name string) *StatsRow {
+		if _, ok := rowMap[name]; !ok {
+			rowMap[name] = &StatsRow{
+				Name: name,
+			}
+		}
+		return rowMap[name]
+	}
+
+	for _, count := range draftCounts {
+		row := getRow(count.Name)
+		switch count.EventType {
+		case RaceEventTypeBan:
+			row.Bans += count.Count
+		case RaceEventTypePick:
+			row.Picks += count.Count
+		case RaceEventTypeVeto:
+			row.Vetos += count.Count
+		}
+	}
+
+	for _, count := range gameCounts {
+		row := getRow(count.Name)
+		row.Games += count.Games
+		if filter.DiscordID != "" {
+			row.WinRateWins += count.RacerWins
+			row.WinRateGames += count.Games
+		} else {
+			row.WinRateWins += count.PickerWins
+			row.WinRateGames += count.PickerGames
+		}
+	}
+
+	rows := make([]*StatsRow, 0)
+	for _, row := range rowMap {
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		iTotal := rows[i].Bans + rows[i].Picks + rows[i].Vetos + rows[i].Games
+		jTotal := rows[j].Bans + rows[j].Picks + rows[j].Vetos + rows[j].Games
+		if iTotal != jTotal {
+			return iTotal > jTotal
+		}
+		return rows[i].Name < rows[j].Name
+	})
+
+	return rows, nil
+}
+
+func commandStatsGetTable(rows []*StatsRow, winRateHeader string) string {
+	table := [][]string{
+		{"Name", "Bans", "Picks", "Vetos", "Games", winRateHeader},
+	}
+	for _, row := range rows {
+		winRate := "-"
+		if row.WinRateGames > 0 {
+			winRate = getPercent(row.WinRateWins, row.WinRateGames)
+		}
+		table = append(table, []string{
+			row.Name,
+			strconv.Itoa(row.Bans),
+			strconv.Itoa(row.Picks),
+			strconv.Itoa(row.Vetos),
+			strconv.Itoa(row.Games),
+			winRate,
+		})
+	}
+
+	return getTableMsg(table)
+}
+
+// Get a rounded percentage, e.g. "67%".
+func getPercent(amount int, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+
+	percent := int(math.Round(float64(amount) * 100 / float64(total)))
+	return strconv.Itoa(percent) + "%"
+}
+
+func commandStatsPrint(m *discordgo.MessageCreate) {
+	msg := "Get the statistics of the completed matches with:\n"
+	msg += "`!stats characters` - How often each character was banned, picked, vetoed, and won with\n"
+	msg += "`!stats builds` - How often each build was banned, picked, vetoed, and won with\n"
+	msg += "`!stats @user` - The record of a racer and their own bans, picks, and vetos\n"
+	msg += "Any of these can be filtered by adding `tournament [challonge url suffix]` or `season [season]` to the end.\n"
+	msg += "e.g. `!stats characters season Season 1`"
+	discordSend(m.ChannelID, msg)
+}
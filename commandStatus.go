@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"math"
 	"runtime/debug"
+	"strconv"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -32,10 +33,14 @@ func announceStatus(m *discordgo.MessageCreate, race *Race, shouldPing bool) {
 		printStatusInitial(m, race, shouldPing)
 	} else if race.State == RaceStateScheduled {
 		printStatusScheduled(m, race)
-	} else if race.State == RaceStateVetoCharacters {
-
-	} else if race.State == RaceStateVetoBuilds {
-
+	} else if race.State == RaceStateBanningCharacters ||
+		race.State == RaceStatePickingCharacters ||
+		race.State == RaceStateVetoCharacters ||
+		race.State == RaceStateBanningBuilds ||
+		race.State == RaceStatePickingBuilds ||
+		race.State == RaceStateVetoBuilds {
+
+		printStatusDraft(race, "")
 	} else if race.State == RaceStateInProgress {
 
 	} else if race.State == RaceStateCompleted {
@@ -145,3 +150,41 @@ func printStatusScheduled(m *discordgo.MessageCreate, race *Race) {
 	msg := getRaceScheduleMessage(race, race.Racer1) // Default to using the first racer's timezone.
 	discordSend(race.ChannelID, msg)
 }
+
+// Print the prompt for whoever needs to ban, pick, or veto next.
+func printStatusDraft(race *Race, msg string) {
+	if race.State == RaceStateBanningCharacters || race.State == RaceStateBanningBuilds {
+		msg += getNextMsg(race)
+		msg += getBansRemaining(race)
+		msg += getRemainingThingsMsg(race)
+	} else if race.State == RaceStatePickingCharacters || race.State == RaceStatePickingBuilds {
+		msg += getNextMsg(race)
+		msg += getPicksRemainingMsg(race)
+		msg += getRemainingThingsMsg(race)
+	} else if race.State == RaceStateVetoCharacters || race.State == RaceStateVetoBuilds {
+		var thing, thingTitle string
+		var things []string
+		if race.State == RaceStateVetoCharacters {
+			thing = "character"
+			thingTitle = "Character"
+			things = race.Characters
+		} else {
+			thing = "build"
+			thingTitle = "Build"
+			things = race.Builds
+		}
+		if len(things) > 0 {
+			msg += "**Round " + strconv.Itoa(len(things)) + "**:\n"
+			msg += "- " + thingTitle + ": *" + things[len(things)-1] + "*\n\n"
+		}
+
+		if race.ActiveRacer == 1 {
+			msg += race.Racer1.Mention()
+		} else if race.ActiveRacer == 2 {
+			msg += race.Racer2.Mention()
+		}
+		msg += ", do you want to veto this " + thing + "? Use `!yes` or `!no` to answer."
+	}
+
+	discordSend(race.ChannelID, msg)
+}
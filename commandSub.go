@@ -128,7 +128,7 @@ func getRemainingThingsMsg(race *Race) string {
 	column1length := 0
 	halfwayPoint := int(float64((len(thingsRemaining) - 1) / 2))
 	for i := 0; i <= halfwayPoint; i++ {
-		line := strconv.Itoa(i+1) + " - " + thingsRemaining[i]
+		line := getRemainingThingNum(i+1, len(thingsRemaining)) + " - " + thingsRemaining[i]
 		lines = append(lines, line)
 		if len(line) > column1length {
 			column1length = len(line)
@@ -146,7 +146,7 @@ func getRemainingThingsMsg(race *Race) string {
 	// Build column 2.
 	lineCounter := 0
 	for i := halfwayPoint + 1; i < len(thingsRemaining); i++ {
-		line := strconv.Itoa(i+1) + " - " + thingsRemaining[i]
+		line := getRemainingThingNum(i+1, len(thingsRemaining)) + " - " + thingsRemaining[i]
 		lines[lineCounter] += line
 		lineCounter++
 	}
@@ -161,6 +161,16 @@ func getRemainingThingsMsg(race *Race) string {
 	return msg
 }
 
+// Pad the number with spaces so that the names line up when there are 10 or more things.
+func getRemainingThingNum(num int, numThings int) string {
+	numString := strconv.Itoa(num)
+	for len(numString) < len(strconv.Itoa(numThings)) {
+		numString = " " + numString
+	}
+
+	return numString
+}
+
 func getNextMsg(race *Race) string {
 	var msg string
 	if race.ActiveRacer == 1 {
@@ -171,17 +181,3 @@ func getNextMsg(race *Race) string {
 	msg += ", you're next!\n\n"
 	return msg
 }
-
-func incrementActiveRacer(race *Race) {
-	// Increment the active racer.
-	race.ActiveRacer++
-	if race.ActiveRacer > 2 {
-		race.ActiveRacer = 1
-	}
-	if err := modals.Races.SetActiveRacer(race.ChannelID, race.ActiveRacer); err != nil {
-		msg := "Failed to set the active racer for race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		discordSend(race.ChannelID, msg)
-		return
-	}
-}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func commandTable(m *discordgo.MessageCreate, args []string) {
+	// Only Swiss and round-robin tournaments have a table.
+	nativeTournaments := make([]Tournament, 0)
+	for _, tournament := range tournaments {
+		if pairingIsNative(tournament) {
+			nativeTournaments = append(nativeTournaments, tournament)
+		}
+	}
+	if len(nativeTournaments) == 0 {
+		discordSend(m.ChannelID, "There are no Swiss or round-robin tournaments.")
+		return
+	}
+
+	// The tournament only has to be specified if there is more than one.
+	var tournament Tournament
+	if len(args) == 0 && len(nativeTournaments) == 1 {
+		tournament = nativeTournaments[0]
+	} else if len(args) != 1 {
+		commandTablePrint(m, nativeTournaments)
+		return
+	} else if v, ok := tournaments[args[0]]; !ok || !pairingIsNative(v) {
+		discordSend(m.ChannelID, "\""+args[0]+"\" is not one of the current Swiss or round-robin tournaments.")
+		return
+	} else {
+		tournament = v
+	}
+
+	var participants []*BracketParticipant
+	if v, err := bracketProvider.GetParticipants(tournament); err != nil {
+		msg := "Failed to get the participants for tournament \"" + tournament.Name + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		participants = v
+	}
+
+	var pairings []*Pairing
+	if v, err := modals.Pairings.GetAll(tournament.ChallongeURL); err != nil {
+		msg := "Failed to get the pairings from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		pairings = v
+	}
+
+	msg := "Standings for **" + tournament.Name + "** ("
+	if tournament.Format == TournamentFormatRoundRobin {
+		msg += "round-robin, "
+	} else {
+		msg += "Swiss, "
+	}
+	numRounds := strconv.Itoa(pairingGetNumRounds(tournament, len(participants)))
+	if len(pairings) == 0 {
+		msg += numRounds + " rounds, not started yet):\n"
+	} else if pairingIsOver(tournament, participants, pairings) {
+		msg += "final):\n"
+	} else if round := pairingGetOpenRound(pairings); round != 0 {
+		msg += "round " + strconv.Itoa(round) + " of " + numRounds + "):\n"
+	} else {
+		msg += "round " + strconv.Itoa(pairingGetLastRound(pairings)) + " of " + numRounds + " complete):\n"
+	}
+
+	// The standings are put in a code block so that the columns line up.
+	table := [][]string{
+		{"#", "Name", "Points", "Matches", "Buchholz", "Sonneborn-Berger"},
+	}
+	for i, standing := range pairingGetStandings(tournament, participants, pairings) {
+		name := standing.Name
+		if !standing.Active {
+			name += " (dropped)"
+		}
+		table = append(table, []string{
+			strconv.Itoa(i + 1),
+			name,
+			strconv.Itoa(standing.Points),
+			strconv.Itoa(standing.Wins) + "-" + strconv.Itoa(standing.Losses),
+			strconv.Itoa(standing.Buchholz),
+			strconv.Itoa(standing.SonnebornBerger),
+		})
+	}
+	msg += getTableMsg(table)
+	discordSend(m.ChannelID, msg)
+}
+
+func commandTablePrint(m *discordgo.MessageCreate, nativeTournaments []Tournament) {
+	urls := make([]string, 0)
+	for _, tournament := range nativeTournaments {
+		urls = append(urls, tournament.ChallongeURL)
+	}
+	sort.Strings(urls)
+
+	msg := "Get the standings of a Swiss or round-robin tournament with: `!table [challonge url suffix]`\n"
+	msg += "e.g. `!table " + strings.Join(urls, "`, `!table ") + "`"
+	discordSend(m.ChannelID, msg)
+}
@@ -10,6 +10,10 @@ import (
 )
 
 func commandTime(m *discordgo.MessageCreate, args []string) {
+	commandTimeWithCause(m, args, nil)
+}
+
+func commandTimeWithCause(m *discordgo.MessageCreate, args []string, cause *RaceEventCause) {
 	if len(args) == 0 {
 		announceSchedule(m)
 		return
@@ -58,7 +62,7 @@ func commandTime(m *discordgo.MessageCreate, args []string) {
 	}
 
 	// Check to see if this race has already been scheduled.
-	if race.State != RaceStateInitial {
+	if !raceCanTrigger(race, RaceTriggerTimeProposed) {
 		discordSend(m.ChannelID, "The race has already been scheduled. To delete this time and start over, use the `!timedelete` command.")
 		return
 	}
@@ -110,6 +114,8 @@ func commandTime(m *discordgo.MessageCreate, args []string) {
 		return
 	}
 
+	raceEventRecord(race, m.Author, RaceEventTypeTimeProposed, activeRacer, datetime.UTC().Format("2006-01-02 15:04 MST"), cause)
+
 	var racer1 *User
 	var racer2 *User
 	if m.Author.ID == race.Racer1.DiscordID {
@@ -7,6 +7,10 @@ import (
 )
 
 func commandTimeDelete(m *discordgo.MessageCreate, args []string) {
+	commandTimeDeleteWithCause(m, args, nil)
+}
+
+func commandTimeDeleteWithCause(m *discordgo.MessageCreate, args []string, cause *RaceEventCause) {
 	// Check to see if this is a race channel (and get the race from the database).
 	var race *Race
 	if v, err := getRace(m.ChannelID); err == sql.ErrNoRows {
@@ -33,6 +37,12 @@ func commandTimeDelete(m *discordgo.MessageCreate, args []string) {
 		return
 	}
 
+	// Check to see if this race has already started.
+	if !raceCanTrigger(race, RaceTriggerTimeDeleted) {
+		discordSend(m.ChannelID, "The match has already started, so it cannot be rescheduled.")
+		return
+	}
+
 	// Set the scheduled time to null.
 	if err := modals.Races.UnsetDatetimeScheduled(m.ChannelID); err != nil {
 		msg := "Failed to unset the scheduled time: " + err.Error()
@@ -42,13 +52,20 @@ func commandTimeDelete(m *discordgo.MessageCreate, args []string) {
 	}
 
 	// Set the state.
-	race.State = RaceStateInitial
-	if err := modals.Races.SetState(m.ChannelID, race.State); err != nil {
+	if err := raceTransition(race, RaceTriggerTimeDeleted, RaceStateInitial); err != nil {
 		msg := "Failed to set the state: " + err.Error()
 		log.Error(msg)
 		discordSend(m.ChannelID, msg)
 		return
 	}
+	if err := matchCancelStart(race.ChannelID); err != nil {
+		msg := "Failed to cancel the start of race \"" + race.Name() + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	}
+
+	raceEventRecord(race, m.Author, RaceEventTypeTimeDeleted, raceGetRacerNum(race, m.Author.ID), "", cause)
 
 	discordSend(m.ChannelID, "The currently scheduled time has been deleted. Please suggest a new time with the `!time` command.")
 }
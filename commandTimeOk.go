@@ -7,6 +7,10 @@ import (
 )
 
 func commandTimeOk(m *discordgo.MessageCreate, args []string) {
+	commandTimeOkWithCause(m, args, nil)
+}
+
+func commandTimeOkWithCause(m *discordgo.MessageCreate, args []string, cause *RaceEventCause) {
 	// Create the user in the database if it does not already exist.
 	var user *User
 	if v, err := userGet(m.Author); err != nil {
@@ -44,7 +48,7 @@ func commandTimeOk(m *discordgo.MessageCreate, args []string) {
 	}
 
 	// Check to see if this race has already been scheduled.
-	if race.State != RaceStateInitial {
+	if !raceCanTrigger(race, RaceTriggerTimeConfirmed) {
 		discordSend(m.ChannelID, "Both racers have already agreed to a time, so you cannot confirm.")
 		return
 	}
@@ -74,20 +78,24 @@ func commandTimeOk(m *discordgo.MessageCreate, args []string) {
 	}
 
 	// Set the state.
-	race.State = RaceStateScheduled
-	if err := modals.Races.SetState(m.ChannelID, race.State); err != nil {
+	if err := raceTransition(race, RaceTriggerTimeConfirmed, RaceStateScheduled); err != nil {
 		msg := "Failed to set the state for race \"" + race.Name() + "\": " + err.Error()
 		log.Error(msg)
 		discordSend(m.ChannelID, msg)
 		return
 	}
-	log.Info("Race \""+race.Name()+"\" is now in state:", race.State)
+
+	raceEventRecord(race, m.Author, RaceEventTypeTimeConfirmed, activeRacer, "", cause)
+
+	// Start the match 5 minutes before the scheduled time.
+	if err := matchScheduleStart(race); err != nil {
+		msg := "Failed to schedule the start of race \"" + race.Name() + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	}
 
 	msg := "The race time has been confirmed. I will notify you 5 minutes before the match begins.\n"
 	msg += "(To delete this time and start over, use the `!timedelete` command.)"
 	discordSend(m.ChannelID, msg)
-
-	// Sleep until the match starts.
-	// (Use a goroutine so that the rest of the program doesn't block.)
-	go matchStart(race)
 }
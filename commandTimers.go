@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kierdavis/dateparser"
+)
+
+func commandTimers(m *discordgo.MessageCreate, args []string) {
+	if !isAdmin(m) {
+		return
+	}
+
+	if len(args) == 0 || args[0] == "list" {
+		commandTimersList(m)
+	} else if args[0] == "cancel" {
+		commandTimersCancel(m, args[1:])
+	} else if args[0] == "reschedule" {
+		commandTimersReschedule(m, args[1:])
+	} else {
+		commandTimersPrint(m)
+	}
+}
+
+func commandTimersList(m *discordgo.MessageCreate) {
+	timers := schedulerGetAll()
+	if len(timers) == 0 {
+		discordSend(m.ChannelID, "There are no pending timers.")
+		return
+	}
+
+	msg := "Pending timers (in UTC):\n"
+	for _, timer := range timers {
+		msg += "- `#" + strconv.Itoa(timer.ID) + "` **" + string(timer.Action) + "**"
+		if timer.ChannelID != "" {
+			msg += " in <#" + timer.ChannelID + ">"
+		}
+		msg += " at " + timer.DatetimeFire.UTC().Format("2006-01-02 15:04:05")
+		msg += " (in " + time.Until(timer.DatetimeFire).Round(time.Second).String() + ")\n"
+	}
+	discordSend(m.ChannelID, msg)
+}
+
+func commandTimersCancel(m *discordgo.MessageCreate, args []string) {
+	if len(args) != 1 {
+		commandTimersPrint(m)
+		return
+	}
+
+	var id int
+	if v, err := strconv.Atoi(strings.TrimPrefix(args[0], "#")); err != nil {
+		discordSend(m.ChannelID, "\""+args[0]+"\" is not a number.")
+		return
+	} else {
+		id = v
+	}
+
+	if err := schedulerCancel(id); err != nil {
+		msg := "Failed to cancel the timer: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	}
+
+	discordSend(m.ChannelID, "Timer `#"+strconv.Itoa(id)+"` was cancelled.")
+}
+
+func commandTimersReschedule(m *discordgo.MessageCreate, args []string) {
+	if len(args) < 2 {
+		commandTimersPrint(m)
+		return
+	}
+
+	var id int
+	if v, err := strconv.Atoi(strings.TrimPrefix(args[0], "#")); err != nil {
+		discordSend(m.ChannelID, "\""+args[0]+"\" is not a number.")
+		return
+	} else {
+		id = v
+	}
+
+	// The new time can either be relative to now (e.g. "30m") or a date & time.
+	input := strings.Join(args[1:], " ")
+	var datetime time.Time
+	if v, err := time.ParseDuration(input); err == nil {
+		datetime = time.Now().UTC().Add(v)
+	} else if v, err := dateparser.Parse(input); err != nil {
+		msg := "Failed to parse the time: " + err.Error()
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		datetime = v
+	}
+
+	if err := schedulerReschedule(id, datetime); err != nil {
+		msg := "Failed to reschedule the timer: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	}
+
+	discordSend(m.ChannelID, "Timer `#"+strconv.Itoa(id)+"` will now fire at "+datetime.UTC().Format("2006-01-02 15:04:05")+" (UTC).")
+}
+
+func commandTimersPrint(m *discordgo.MessageCreate) {
+	msg := "Manage the pending timers with:\n"
+	msg += "`!timers` - List all of the pending timers\n"
+	msg += "`!timers cancel [id]` - Cancel a timer\n"
+	msg += "`!timers reschedule [id] [duration or date & time in UTC]` - Change when a timer fires\n"
+	msg += "e.g. `!timers reschedule 12 30m`"
+	discordSend(m.ChannelID, msg)
+}
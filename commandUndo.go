@@ -0,0 +1,77 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func commandUndo(m *discordgo.MessageCreate, args []string) {
+	if !isAdmin(m) {
+		return
+	}
+
+	// Check to see if this is a race channel (and get the race from the database).
+	var race *Race
+	if v, err := getRace(m.ChannelID); err == sql.ErrNoRows {
+		discordSend(m.ChannelID, "You can only use that command in a race channel.")
+		return
+	} else if err != nil {
+		msg := "Failed to get the race from the database: " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		race = v
+	}
+
+	// Find the last ban, pick, or veto.
+	var event *RaceEvent
+	if v, err := modals.RaceEvents.GetLastDraft(race.ChannelID); err == sql.ErrNoRows {
+		discordSend(m.ChannelID, "There is nothing to undo in this match.")
+		return
+	} else if err != nil {
+		msg := "Failed to get the last event for race \"" + race.Name() + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	} else {
+		event = v
+	}
+
+	var snapshot *RaceDraftSnapshot
+	if err := json.Unmarshal([]byte(event.Snapshot.String), &snapshot); err != nil {
+		msg := "Failed to unmarshal the draft snapshot for race \"" + race.Name() + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	}
+
+	// Check to see if the race can go back to the state that it was in.
+	// (e.g. a match that has already been completed cannot be undone)
+	if !raceCanTransition(race, RaceTriggerUndo, snapshot.State) {
+		discordSend(m.ChannelID, "You cannot undo anything in a match that is in the \""+string(race.State)+"\" state.")
+		return
+	}
+
+	// The draft is put back and the event is marked as undone in the same update as the state.
+	description := raceEventGetDescription(race, event)
+	if err := raceTransitionWrite(race, RaceTriggerUndo, snapshot.State, func(from RaceState) error {
+		return modals.RaceEvents.Undo(race.ChannelID, event.ID, from, snapshot)
+	}); err != nil {
+		msg := "Failed to undo the last action for race \"" + race.Name() + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(m.ChannelID, msg)
+		return
+	}
+	raceSetDraftSnapshot(race, snapshot)
+
+	raceEventRecord(race, m.Author, RaceEventTypeUndo, event.RacerNum, description, nil)
+
+	msg := "An admin has undone the last action: " + description + "\n\n"
+	printStatusDraft(race, msg)
+
+	// Restart the timer for the racer whose turn it is now.
+	turnSchedule(race)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCommandUndo(t *testing.T) {
+	g := testSetup(t, Tournament{
+		Ruleset:          RulesetUnseeded,
+		Type:             TournamentTypeBanPick,
+		BestOf:           3,
+		NumCharacterBans: 2,
+	})
+	race := g.StartMatch(t, g.AddRace(t, RaceStateScheduled))
+	second := g.Racer(3 - race.ActiveRacer)
+
+	g.Send(race.ChannelID, g.Admin, "!forceban 1")
+
+	// An event on behalf of the racer that did not cause anything (e.g. from a ban that failed)
+	// right before a ban that the racer made themselves.
+	race = testGetRace(t, race.ChannelID)
+	raceEventRecord(race, nil, RaceEventTypeAutoBan, race.ActiveRacer, "1", nil)
+	g.Send(race.ChannelID, second, "!ban 1")
+
+	// Undoing a ban that the racer made themselves leaves the other events alone.
+	g.Send(race.ChannelID, g.Admin, "!undo")
+	testCheckUndone(t, race.ChannelID, []RaceEventType{
+		RaceEventTypeForceBan, RaceEventTypeBan, RaceEventTypeAutoBan, RaceEventTypeBan, RaceEventTypeUndo,
+	}, []bool{false, false, false, true, false})
+
+	// Undoing a forced ban also undoes the force command that caused it.
+	g.Send(race.ChannelID, g.Admin, "!undo")
+	testCheckUndone(t, race.ChannelID, []RaceEventType{
+		RaceEventTypeForceBan, RaceEventTypeBan, RaceEventTypeAutoBan, RaceEventTypeBan, RaceEventTypeUndo, RaceEventTypeUndo,
+	}, []bool{true, true, false, true, false, false})
+
+	race = testGetRace(t, race.ChannelID)
+	if race.Racer1Bans != 2 || race.Racer2Bans != 2 {
+		t.Errorf("got %d and %d bans left after undoing both bans, want 2 and 2", race.Racer1Bans, race.Racer2Bans)
+	}
+	if len(race.CharactersRemaining) != len(tournaments[testTournamentURL].Characters) {
+		t.Errorf("got %d characters remaining after undoing both bans, want all of them", len(race.CharactersRemaining))
+	}
+}
+
+func testCheckUndone(t *testing.T, channelID string, eventTypes []RaceEventType, undone []bool) {
+	t.Helper()
+
+	events := testGetEvents(t, channelID)
+	if len(events) != len(eventTypes) {
+		t.Fatalf("got %d events, want %d", len(events), len(eventTypes))
+	}
+	for i, event := range events {
+		if event.Type != eventTypes[i] || event.Undone != undone[i] {
+			t.Errorf("got event %d of \"%s\" with an undone of %t, want \"%s\" with %t", i, event.Type, event.Undone, eventTypes[i], undone[i])
+		}
+	}
+}
@@ -2,12 +2,15 @@ package main
 
 import (
 	"database/sql"
-	"strconv"
 
 	"github.com/bwmarrin/discordgo"
 )
 
 func commandYes(m *discordgo.MessageCreate, args []string) {
+	commandYesWithCause(m, args, nil)
+}
+
+func commandYesWithCause(m *discordgo.MessageCreate, args []string, cause *RaceEventCause) {
 	// Check to see if this is a race channel (and get the race from the database).
 	var race *Race
 	if v, err := getRace(m.ChannelID); err == sql.ErrNoRows {
@@ -34,7 +37,7 @@ func commandYes(m *discordgo.MessageCreate, args []string) {
 	}
 
 	// Check to see if this race is in the vetoing phase.
-	if race.State != RaceStateVetoCharacters && race.State != RaceStateVetoBuilds {
+	if !raceCanTrigger(race, RaceTriggerVeto) {
 		discordSend(m.ChannelID, "You can only veto something once the characters have been chosen.")
 		return
 	}
@@ -53,58 +56,27 @@ func commandYes(m *discordgo.MessageCreate, args []string) {
 		return
 	}
 
-	// The character/build was already added, so remove it.
-	var veto string
-	if race.State == RaceStateVetoCharacters {
-		veto = race.Characters[len(race.Characters)-1]
-		race.Characters = race.Characters[:len(race.Characters)-1] // Delete the last element.
-		if err := modals.Races.SetCharacters(race.ChannelID, race.Characters); err != nil {
-			msg := "Failed to set the characters for race \"" + race.Name() + "\": " + err.Error()
-			log.Error(msg)
-			discordSend(m.ChannelID, msg)
-			return
-		}
-	} else if race.State == RaceStateVetoBuilds {
-		veto = race.Builds[len(race.Builds)-1]
-		race.Builds = race.Builds[:len(race.Builds)-1] // Delete the last element.
-		if err := modals.Races.SetBuilds(race.ChannelID, race.Builds); err != nil {
-			msg := "Failed to set the builds for race \"" + race.Name() + "\": " + err.Error()
-			log.Error(msg)
-			discordSend(m.ChannelID, msg)
-			return
-		}
-	}
-
-	// Decrement the vetos.
-	var vetosLeft int
-	if racerNum == 1 {
-		race.Racer1Vetos--
-		vetosLeft = race.Racer1Vetos
-	} else if racerNum == 2 {
-		race.Racer2Vetos--
-		vetosLeft = race.Racer2Vetos
-	}
-	if err := modals.Races.SetVetos(race.ChannelID, racerNum, vetosLeft); err != nil {
-		msg := "Failed to set the vetos for racer " + strconv.Itoa(racerNum) + " on race \"" + race.Name() + "\": " + err.Error()
-		log.Error(msg)
-		discordSend(m.ChannelID, msg)
-		return
-	}
+	// Remember how the draft looked beforehand, in case an admin needs to undo this.
+	snapshot := raceGetDraftSnapshot(race)
 
-	// Set the number of people who have voted on this build.
-	race.NumVoted = 2 // If this person is vetoing, then the other person does not get a say.
-	if err := modals.Races.SetNumVoted(race.ChannelID, race.NumVoted); err != nil {
-		msg := "Failed to set the NumVoted for race \"" + race.Name() + "\": " + err.Error()
+	// Veto the thing and pass the turn to the other racer.
+	draft := raceGetDraftSnapshot(race)
+	veto := draftVeto(draft, racerNum)
+	if err := raceTransitionDraft(race, RaceTriggerVeto, draft); err != nil {
+		msg := "Failed to veto \"" + veto + "\" for race \"" + race.Name() + "\": " + err.Error()
 		log.Error(msg)
 		discordSend(m.ChannelID, msg)
 		return
 	}
 
-	incrementActiveRacer(race)
+	raceEventRecordDraft(race, m.Author, RaceEventTypeVeto, racerNum, veto, snapshot, cause)
 	msg := m.Author.Mention() + " vetoed: *" + veto + "*\n\n"
 	if race.State == RaceStateVetoCharacters {
-		charactersRound(race, msg)
+		charactersRound(race, RaceTriggerVeto, raceGetDraftSnapshot(race), msg)
 	} else if race.State == RaceStateVetoBuilds {
-		buildsRound(race, msg)
+		buildsRound(race, RaceTriggerVeto, raceGetDraftSnapshot(race), msg)
 	}
+
+	// Start the timer for the next turn (or stop it if the draft is over).
+	turnSchedule(race)
 }
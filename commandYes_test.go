@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestCommandYes(t *testing.T) {
+	g := testSetup(t, Tournament{
+		Ruleset:           RulesetUnseeded,
+		Type:              TournamentTypeVeto,
+		BestOf:            2,
+		NumCharacterVetos: 1,
+	})
+	race := g.StartMatch(t, g.AddRace(t, RaceStateScheduled))
+	if race.State != RaceStateVetoCharacters {
+		t.Fatalf("got a state of %s after the match started, want %s", race.State, RaceStateVetoCharacters)
+	}
+	if len(race.Characters) != 1 {
+		t.Fatalf("got %d characters after the match started, want 1", len(race.Characters))
+	}
+	first := g.Racer(race.ActiveRacer)
+	second := g.Racer(3 - race.ActiveRacer)
+	firstVeto := race.Characters[0]
+
+	tests := []struct {
+		name    string
+		member  *discordgo.Member
+		content string
+		want    string
+	}{
+		{"not a racer", g.Admin, "!yes", "Only `Dea1h` and `Cyber_1` can veto a build."},
+		{"not their turn", second, "!yes", "It is not your turn."},
+		{"first veto", first, "!yes", first.User.Mention() + " vetoed: *" + firstVeto + "*"},
+	}
+
+	for _, test := range tests {
+		g.Send(race.ChannelID, test.member, test.content)
+		if got := g.LastMessage(race.ChannelID); !strings.Contains(got, test.want) {
+			t.Errorf("%s: got a message of %q, want it to contain %q", test.name, got, test.want)
+		}
+	}
+
+	// The vetoed character is replaced with a new one and the other racer is asked about it.
+	race = testGetRace(t, race.ChannelID)
+	if len(race.Characters) != 1 || race.Characters[0] == firstVeto {
+		t.Fatalf("got the characters %v after the veto, want one new character", race.Characters)
+	}
+	if g.Racer(race.ActiveRacer) != second {
+		t.Fatalf("got an active racer of %d after the veto, want the second racer", race.ActiveRacer)
+	}
+	secondVeto := race.Characters[0]
+	want := second.User.Mention() + ", do you want to veto this character?"
+	if got := g.LastMessage(race.ChannelID); !strings.Contains(got, want) {
+		t.Errorf("got a message of %q, want it to contain %q", got, want)
+	}
+
+	// Once both racers are out of vetos, the rest of the characters are chosen without asking.
+	g.Send(race.ChannelID, second, "!yes")
+	got := g.LastMessage(race.ChannelID)
+	if !strings.Contains(got, second.User.Mention()+" vetoed: *"+secondVeto+"*") {
+		t.Errorf("got a message of %q, want the second veto", got)
+	}
+	if !strings.Contains(got, "Match Summary") {
+		t.Errorf("got a message of %q, want the match summary", got)
+	}
+
+	race = testGetRace(t, race.ChannelID)
+	if race.State != RaceStateInProgress {
+		t.Errorf("got a state of %s after the vetos, want %s", race.State, RaceStateInProgress)
+	}
+	if race.Racer1Vetos != 0 || race.Racer2Vetos != 0 {
+		t.Errorf("got %d and %d vetos left, want 0 and 0", race.Racer1Vetos, race.Racer2Vetos)
+	}
+	if len(race.Characters) != 2 ||
+		stringInSlice(firstVeto, race.Characters) ||
+		stringInSlice(secondVeto, race.Characters) {
+
+		t.Errorf("got the characters %v, want two characters that were not vetoed", race.Characters)
+	}
+
+	g.Send(race.ChannelID, first, "!yes")
+	want = "You can only veto something once the characters have been chosen."
+	if got := g.LastMessage(race.ChannelID); got != want {
+		t.Errorf("got a message of %q after the vetos, want %q", got, want)
+	}
+}
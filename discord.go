@@ -16,8 +16,27 @@ const (
 	discordGeneralChannelName  = "matches"
 )
 
+// DiscordSession is the subset of the Discord API that the command handlers use. It is satisfied
+// by "*discordgo.Session" and by "FakeDiscordSession" (in the tests).
+type DiscordSession interface {
+	Guild(guildID string) (*discordgo.Guild, error)
+	GuildMembers(guildID string, after string, limit int) ([]*discordgo.Member, error)
+	GuildMember(guildID string, userID string) (*discordgo.Member, error)
+	GuildRoles(guildID string) ([]*discordgo.Role, error)
+	GuildChannels(guildID string) ([]*discordgo.Channel, error)
+	GuildChannelCreate(guildID string, name string, ctype discordgo.ChannelType) (*discordgo.Channel, error)
+	Channel(channelID string) (*discordgo.Channel, error)
+	ChannelEdit(channelID string, name string) (*discordgo.Channel, error)
+	ChannelEditComplex(channelID string, data *discordgo.ChannelEdit) (*discordgo.Channel, error)
+	ChannelDelete(channelID string) (*discordgo.Channel, error)
+	ChannelMessages(channelID string, limit int, beforeID string, afterID string, aroundID string) ([]*discordgo.Message, error)
+	ChannelMessageSend(channelID string, content string) (*discordgo.Message, error)
+	UserChannelCreate(recipientID string) (*discordgo.Channel, error)
+}
+
 var (
-	discordSession           *discordgo.Session
+	discordGateway           *discordgo.Session // The websocket connection to Discord.
+	discordSession           DiscordSession     // Everything else should use this instead of the gateway.
 	discordBotID             string
 	discordGuildName         string
 	discordGuildID           string
@@ -49,22 +68,23 @@ func discordInit() {
 		log.Fatal("Failed to create a Discord session:", err)
 		return
 	} else {
+		discordGateway = d
 		discordSession = d
 	}
 
 	// Register function handlers for various events.
-	discordSession.AddHandler(discordReady)
-	discordSession.AddHandler(discordMessageCreate)
+	discordGateway.AddHandler(discordReady)
+	discordGateway.AddHandler(discordMessageCreate)
 
 	// Register function handlers for all of the commands.
 	commandInit()
 
 	// Set all intents:
 	// https://github.com/bwmarrin/discordgo/wiki/FAQ#gateway-intents
-	discordSession.Identify.Intents = discordgo.MakeIntent(discordgo.IntentsAll)
+	discordGateway.Identify.Intents = discordgo.MakeIntent(discordgo.IntentsAll)
 
 	// Open the websocket and begin listening.
-	if err := discordSession.Open(); err != nil {
+	if err := discordGateway.Open(); err != nil {
 		log.Fatal("Error opening Discord session: ", err)
 		return
 	}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// FakeDiscordSession is an in-memory Discord guild that implements the DiscordSession interface.
+// It records every channel, permission overwrite, and message so that the command handlers can be
+// exercised without connecting to Discord.
+type FakeDiscordSession struct {
+	GuildID   string
+	GuildName string
+	Members   []*discordgo.Member
+	Roles     []*discordgo.Role
+	Channels  []*discordgo.Channel
+	Messages  map[string][]*discordgo.Message // Indexed by channel ID.
+
+	nextID int
+	mutex  *sync.Mutex
+}
+
+// Ensure that the fake stays in sync with the interface.
+var _ DiscordSession = (*FakeDiscordSession)(nil)
+
+func NewFakeDiscordSession(guildID string, guildName string) *FakeDiscordSession {
+	return &FakeDiscordSession{
+		GuildID:   guildID,
+		GuildName: guildName,
+		Members:   make([]*discordgo.Member, 0),
+		Roles:     make([]*discordgo.Role, 0),
+		Channels:  make([]*discordgo.Channel, 0),
+		Messages:  make(map[string][]*discordgo.Message),
+
+		nextID: 1,
+		mutex:  new(sync.Mutex),
+	}
+}
+
+/*
+	Setup helpers
+*/
+
+func (f *FakeDiscordSession) AddRole(name string) *discordgo.Role {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	role := &discordgo.Role{
+		ID:   f.getNextID(),
+		Name: name,
+	}
+	f.Roles = append(f.Roles, role)
+	return role
+}
+
+func (f *FakeDiscordSession) AddMember(username string, roleIDs ...string) *discordgo.Member {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	member := &discordgo.Member{
+		GuildID: f.GuildID,
+		User: &discordgo.User{
+			ID:            f.getNextID(),
+			Username:      username,
+			Discriminator: "0000",
+		},
+		Roles: roleIDs,
+	}
+	f.Members = append(f.Members, member)
+	return member
+}
+
+func (f *FakeDiscordSession) AddChannel(name string, ctype discordgo.ChannelType, parentID string) *discordgo.Channel {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	channel := &discordgo.Channel{
+		ID:       f.getNextID(),
+		GuildID:  f.GuildID,
+		Name:     name,
+		Type:     ctype,
+		ParentID: parentID,
+	}
+	f.Channels = append(f.Channels, channel)
+	return channel
+}
+
+// GetMessages returns the content of every message that was sent to the channel, in order.
+func (f *FakeDiscordSession) GetMessages(channelID string) []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	contents := make([]string, 0)
+	for _, message := range f.Messages[channelID] {
+		contents = append(contents, message.Content)
+	}
+	return contents
+}
+
+func (f *FakeDiscordSession) getNextID() string {
+	id := strconv.Itoa(f.nextID)
+	f.nextID++
+	return id
+}
+
+func (f *FakeDiscordSession) getChannel(channelID string) (*discordgo.Channel, int) {
+	for i, channel := range f.Channels {
+		if channel.ID == channelID {
+			return channel, i
+		}
+	}
+
+	return nil, -1
+}
+
+func (f *FakeDiscordSession) checkGuildID(guildID string) error {
+	if guildID != f.GuildID {
+		return errors.New("Unknown guild: " + guildID)
+	}
+
+	return nil
+}
+
+/*
+	DiscordSession methods
+*/
+
+func (f *FakeDiscordSession) Guild(guildID string) (*discordgo.Guild, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.checkGuildID(guildID); err != nil {
+		return nil, err
+	}
+
+	return &discordgo.Guild{
+		ID:       f.GuildID,
+		Name:     f.GuildName,
+		Members:  f.Members,
+		Roles:    f.Roles,
+		Channels: f.Channels,
+	}, nil
+}
+
+func (f *FakeDiscordSession) GuildMembers(guildID string, after string, limit int) ([]*discordgo.Member, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.checkGuildID(guildID); err != nil {
+		return nil, err
+	}
+
+	members := make([]*discordgo.Member, 0)
+	for _, member := range f.Members {
+		if len(members) >= limit {
+			break
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+func (f *FakeDiscordSession) GuildMember(guildID string, userID string) (*discordgo.Member, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.checkGuildID(guildID); err != nil {
+		return nil, err
+	}
+
+	for _, member := range f.Members {
+		if member.User.ID == userID {
+			return member, nil
+		}
+	}
+
+	return nil, errors.New("Unknown member: " + userID)
+}
+
+func (f *FakeDiscordSession) GuildRoles(guildID string) ([]*discordgo.Role, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.checkGuildID(guildID); err != nil {
+		return nil, err
+	}
+
+	return f.Roles, nil
+}
+
+func (f *FakeDiscordSession) GuildChannels(guildID string) ([]*discordgo.Channel, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.checkGuildID(guildID); err != nil {
+		return nil, err
+	}
+
+	// Return a copy so that callers can delete channels while iterating.
+	channels := make([]*discordgo.Channel, len(f.Channels))
+	copy(channels, f.Channels)
+	return channels, nil
+}
+
+func (f *FakeDiscordSession) GuildChannelCreate(guildID string, name string, ctype discordgo.ChannelType) (*discordgo.Channel, error) {
+	if err := f.checkGuildID(guildID); err != nil {
+		return nil, err
+	}
+
+	return f.AddChannel(name, ctype, ""), nil
+}
+
+func (f *FakeDiscordSession) Channel(channelID string) (*discordgo.Channel, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	channel, _ := f.getChannel(channelID)
+	if channel == nil {
+		return nil, errors.New("Unknown channel: " + channelID)
+	}
+
+	return channel, nil
+}
+
+func (f *FakeDiscordSession) ChannelEdit(channelID string, name string) (*discordgo.Channel, error) {
+	return f.ChannelEditComplex(channelID, &discordgo.ChannelEdit{
+		Name: name,
+	})
+}
+
+func (f *FakeDiscordSession) ChannelEditComplex(channelID string, data *discordgo.ChannelEdit) (*discordgo.Channel, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	channel, _ := f.getChannel(channelID)
+	if channel == nil {
+		return nil, errors.New("Unknown channel: " + channelID)
+	}
+
+	// Like the real API, only the fields that were specified are changed.
+	if data.Name != "" {
+		channel.Name = data.Name
+	}
+	if data.Topic != "" {
+		channel.Topic = data.Topic
+	}
+	if data.PermissionOverwrites != nil {
+		channel.PermissionOverwrites = data.PermissionOverwrites
+	}
+	if data.ParentID != "" {
+		channel.ParentID = data.ParentID
+	}
+
+	return channel, nil
+}
+
+func (f *FakeDiscordSession) ChannelDelete(channelID string) (*discordgo.Channel, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	channel, i := f.getChannel(channelID)
+	if channel == nil {
+		return nil, errors.New("Unknown channel: " + channelID)
+	}
+
+	f.Channels = append(f.Channels[:i], f.Channels[i+1:]...)
+	return channel, nil
+}
+
+// ChannelMessages returns the messages of the channel from the newest to the oldest, like the real
+// API. (Only "beforeID" is supported, since that is all that the bot uses to page through them.)
+func (f *FakeDiscordSession) ChannelMessages(channelID string, limit int, beforeID string, afterID string, aroundID string) ([]*discordgo.Message, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if channel, _ := f.getChannel(channelID); channel == nil {
+		return nil, errors.New("Unknown channel: " + channelID)
+	}
+
+	channelMessages := f.Messages[channelID]
+	end := len(channelMessages)
+	if beforeID != "" {
+		for i, message := range channelMessages {
+			if message.ID == beforeID {
+				end = i
+				break
+			}
+		}
+	}
+
+	messages := make([]*discordgo.Message, 0)
+	for i := end - 1; i >= 0 && len(messages) < limit; i-- {
+		messages = append(messages, channelMessages[i])
+	}
+	return messages, nil
+}
+
+func (f *FakeDiscordSession) ChannelMessageSend(channelID string, content string) (*discordgo.Message, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if channel, _ := f.getChannel(channelID); channel == nil {
+		return nil, errors.New("Unknown channel: " + channelID)
+	}
+
+	message := &discordgo.Message{
+		ID:        f.getNextID(),
+		ChannelID: channelID,
+		GuildID:   f.GuildID,
+		Content:   content,
+	}
+	f.Messages[channelID] = append(f.Messages[channelID], message)
+	return message, nil
+}
+
+func (f *FakeDiscordSession) UserChannelCreate(recipientID string) (*discordgo.Channel, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	// Like the real API, the same private channel is returned every time.
+	for _, channel := range f.Channels {
+		if channel.Type == discordgo.ChannelTypeDM && len(channel.Recipients) == 1 && channel.Recipients[0].ID == recipientID {
+			return channel, nil
+		}
+	}
+
+	var recipient *discordgo.User
+	for _, member := range f.Members {
+		if member.User.ID == recipientID {
+			recipient = member.User
+			break
+		}
+	}
+	if recipient == nil {
+		return nil, errors.New("Unknown user: " + recipientID)
+	}
+
+	channel := &discordgo.Channel{
+		ID:         f.getNextID(),
+		Type:       discordgo.ChannelTypeDM,
+		Recipients: []*discordgo.User{recipient},
+	}
+	f.Channels = append(f.Channels, channel)
+	return channel, nil
+}
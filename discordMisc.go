@@ -2,15 +2,96 @@ package main
 
 import (
 	"errors"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/bwmarrin/discordgo"
 )
 
+const (
+	// Discord rejects messages that are longer than this.
+	discordMaxMessageLength = 2000
+)
+
+// Send a message to a channel. Messages that are too long for Discord are split into several
+// messages.
 func discordSend(channelID string, msg string) {
-	if _, err := discordSession.ChannelMessageSend(channelID, msg); err != nil {
-		log.Error("Failed to send \"" + msg + "\" to \"" + channelID + "\": " + err.Error())
+	for _, chunk := range discordSplitMessage(msg) {
+		if _, err := discordSession.ChannelMessageSend(channelID, chunk); err != nil {
+			log.Error("Failed to send \"" + chunk + "\" to \"" + channelID + "\": " + err.Error())
+			return
+		}
+	}
+}
+
+// Split a message into chunks that each fit into a single Discord message. The message is split
+// in between lines where possible. If a code block is split, it is closed at the end of the chunk
+// and reopened at the start of the next one so that the formatting is kept.
+func discordSplitMessage(msg string) []string {
+	if len(msg) <= discordMaxMessageLength {
+		return []string{msg}
+	}
+
+	// Leave room to close and reopen a code block.
+	maxLength := discordMaxMessageLength - len("```\n") - len("\n```")
+
+	chunks := make([]string, 0)
+	chunk := ""
+	inCodeBlock := false
+	for _, line := range strings.SplitAfter(msg, "\n") {
+		// Lines that are too long by themselves have to be split up. (The limit is in bytes, so the
+		// runes are added one at a time so that a multibyte character is never cut in half.)
+		pieces := make([]string, 0)
+		piece := ""
+		for _, r := range line {
+			if len(piece)+utf8.RuneLen(r) > maxLength {
+				pieces = append(pieces, piece)
+				piece = ""
+			}
+			piece += string(r)
+		}
+		pieces = append(pieces, piece)
+
+		for _, piece := range pieces {
+			// (A chunk that only has the reopened code block in it does not need to be sent.)
+			if len(chunk)+len(piece) > maxLength && chunk != "" && chunk != "```\n" {
+				if inCodeBlock {
+					if !strings.HasSuffix(chunk, "\n") {
+						chunk += "\n"
+					}
+					chunk += "```"
+				}
+				chunks = append(chunks, chunk)
+				chunk = ""
+				if inCodeBlock {
+					chunk = "```\n"
+				}
+			}
+			chunk += piece
+			if strings.Count(piece, "```")%2 == 1 {
+				inCodeBlock = !inCodeBlock
+			}
+		}
+	}
+	if strings.TrimSpace(chunk) != "" {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+// Send a direct message to a user. Failures are only logged, since the user may have direct
+// messages from server members turned off.
+func discordSendDM(discordID string, msg string) {
+	var channel *discordgo.Channel
+	if v, err := discordSession.UserChannelCreate(discordID); err != nil {
+		log.Error("Failed to create a private channel with \"" + discordID + "\": " + err.Error())
 		return
+	} else {
+		channel = v
 	}
+
+	discordSend(channel.ID, msg)
 }
 
 // Other calls to "discordSession.GuildMembers" should be refactored here, but I don't have the
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDiscordSplitMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		msg       string
+		numChunks int
+	}{
+		{"short", "hello", 1},
+		{"exactly the limit", strings.Repeat("a", discordMaxMessageLength), 1},
+		{"many lines", strings.Repeat("line\n", 1000), 3},
+		{"one long ASCII line", strings.Repeat("a", 5000), 3},
+		{"one long multibyte line", strings.Repeat("é", 1500), 2},
+		{"four-byte runes", strings.Repeat("🎲", 1000), 3},
+		{"multibyte lines", strings.Repeat("ééééé\n", 500), 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chunks := discordSplitMessage(test.msg)
+			if len(chunks) != test.numChunks {
+				t.Errorf("got %d chunks, want %d", len(chunks), test.numChunks)
+			}
+			for i, chunk := range chunks {
+				if len(chunk) > discordMaxMessageLength {
+					t.Errorf("chunk %d is %d bytes long", i, len(chunk))
+				}
+				if !utf8.ValidString(chunk) {
+					t.Errorf("chunk %d is not valid UTF-8", i)
+				}
+			}
+			if joined := strings.Join(chunks, ""); joined != test.msg {
+				t.Errorf("the chunks do not add up to the original message")
+			}
+		})
+	}
+}
+
+func TestDiscordSplitMessageCodeBlock(t *testing.T) {
+	msg := "Standings:\n```\n" + strings.Repeat("1  Zamiel  ééé\n", 300) + "```\n"
+	chunks := discordSplitMessage(msg)
+	if len(chunks) < 2 {
+		t.Fatalf("got %d chunks, want at least 2", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > discordMaxMessageLength {
+			t.Errorf("chunk %d is %d bytes long", i, len(chunk))
+		}
+		if strings.Count(chunk, "```")%2 != 0 {
+			t.Errorf("chunk %d does not close its code block", i)
+		}
+	}
+}
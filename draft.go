@@ -0,0 +1,88 @@
+package main
+
+/*
+	The changes that each ban, pick, and veto makes to the draft. These only change the draft
+	snapshot that they are given; the command handlers write it to the database (along with the
+	state) with "raceTransitionDraft".
+*/
+
+// Ban the thing at the index of the remaining characters or builds. Returns what was banned.
+func draftBan(draft *RaceDraftSnapshot, racerNum int, choice int) string {
+	var thing string
+	if draft.State == RaceStateBanningCharacters {
+		thing = draft.CharactersRemaining[choice]
+		draft.CharactersRemaining = deleteFromSlice(draft.CharactersRemaining, choice)
+	} else if draft.State == RaceStateBanningBuilds {
+		thing = draft.BuildsRemaining[choice]
+		draft.BuildsRemaining = deleteFromSlice(draft.BuildsRemaining, choice)
+	}
+
+	if racerNum == 1 {
+		draft.Racer1Bans--
+	} else if racerNum == 2 {
+		draft.Racer2Bans--
+	}
+	draftNextRacer(draft)
+
+	return thing
+}
+
+// Pick the thing at the index of the remaining characters or builds. Returns what was picked.
+func draftPick(draft *RaceDraftSnapshot, choice int) string {
+	var thing string
+	if draft.State == RaceStatePickingCharacters {
+		thing = draft.CharactersRemaining[choice]
+		draft.CharactersRemaining = deleteFromSlice(draft.CharactersRemaining, choice)
+		draft.Characters = append(draft.Characters, thing)
+	} else if draft.State == RaceStatePickingBuilds {
+		thing = draft.BuildsRemaining[choice]
+		draft.BuildsRemaining = deleteFromSlice(draft.BuildsRemaining, choice)
+		draft.Builds = append(draft.Builds, thing)
+	}
+	draftNextRacer(draft)
+
+	return thing
+}
+
+// Veto the character or build that was randomly chosen last. Returns what was vetoed.
+func draftVeto(draft *RaceDraftSnapshot, racerNum int) string {
+	// The character/build was already added, so remove it.
+	var veto string
+	if draft.State == RaceStateVetoCharacters {
+		veto = draft.Characters[len(draft.Characters)-1]
+		draft.Characters = draft.Characters[:len(draft.Characters)-1] // Delete the last element.
+	} else if draft.State == RaceStateVetoBuilds {
+		veto = draft.Builds[len(draft.Builds)-1]
+		draft.Builds = draft.Builds[:len(draft.Builds)-1] // Delete the last element.
+	}
+
+	if racerNum == 1 {
+		draft.Racer1Vetos--
+	} else if racerNum == 2 {
+		draft.Racer2Vetos--
+	}
+	draft.NumVoted = 2 // If this person is vetoing, then the other person does not get a say.
+	draftNextRacer(draft)
+
+	return veto
+}
+
+// Keep the character or build that was randomly chosen last.
+func draftNoVeto(draft *RaceDraftSnapshot) {
+	draft.NumVoted++
+	draftNextRacer(draft)
+}
+
+// Get whether the racer whose turn it is has already used all of their vetos, in which case their
+// turn is skipped.
+func draftIsOutOfVetos(draft *RaceDraftSnapshot) bool {
+	return (draft.ActiveRacer == 1 && draft.Racer1Vetos == 0) ||
+		(draft.ActiveRacer == 2 && draft.Racer2Vetos == 0)
+}
+
+func draftNextRacer(draft *RaceDraftSnapshot) {
+	draft.ActiveRacer++
+	if draft.ActiveRacer > 2 {
+		draft.ActiveRacer = 1
+	}
+}
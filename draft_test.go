@@ -0,0 +1,227 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+// The state of the draft after a step. The racers are numbered by who picks first, since that is
+// random: 1 is the first picker and 2 is the other racer. A character or build of "?" was chosen at
+// random, so it can be anything.
+type draftTestStep struct {
+	command     string // Sent by the active racer; blank for the state right after the match starts.
+	state       RaceState
+	characters  []string
+	builds      []string
+	bans        [2]int
+	vetos       [2]int
+	activeRacer int
+}
+
+var (
+	draftTestCharacters = []string{"Isaac", "Magdalene", "Cain", "Judas", "Eve", "Samson"}
+	draftTestBuilds     = []string{"Cricket's Head", "Magic Mushroom", "Polyphemus", "Proptosis"} // These work with every character.
+)
+
+func TestDraft(t *testing.T) {
+	banPickSteps := []draftTestStep{
+		{"", RaceStateBanningCharacters, nil, nil, [2]int{1, 1}, [2]int{0, 0}, 1},
+		{"!ban 1", RaceStateBanningCharacters, nil, nil, [2]int{0, 1}, [2]int{0, 0}, 2},
+		{"!ban 1", RaceStatePickingCharacters, nil, nil, [2]int{0, 0}, [2]int{0, 0}, 1},
+		{"!pick 1", RaceStatePickingCharacters, []string{"Cain"}, nil, [2]int{0, 0}, [2]int{0, 0}, 2},
+		{"!pick 2", RaceStatePickingCharacters, []string{"Cain", "Eve"}, nil, [2]int{0, 0}, [2]int{0, 0}, 1},
+		{"!pick 2", RaceStateInProgress, []string{"Cain", "Eve", "Samson"}, nil, [2]int{0, 0}, [2]int{0, 0}, 2},
+	}
+	vetoSteps := []draftTestStep{
+		{"", RaceStateVetoCharacters, []string{"?"}, nil, [2]int{0, 0}, [2]int{1, 1}, 1},
+		{"!no", RaceStateVetoCharacters, []string{"?"}, nil, [2]int{0, 0}, [2]int{1, 1}, 2},
+		{"!yes", RaceStateVetoCharacters, []string{"?"}, nil, [2]int{0, 0}, [2]int{1, 0}, 1},
+		{"!yes", RaceStateInProgress, []string{"?", "?"}, nil, [2]int{0, 0}, [2]int{0, 0}, 2},
+	}
+
+	tests := []struct {
+		name       string
+		tournament Tournament
+		steps      []draftTestStep
+	}{
+		{
+			"seeded ban/pick",
+			Tournament{
+				Ruleset:          RulesetSeeded,
+				Type:             TournamentTypeBanPick,
+				BestOf:           2,
+				NumCharacterBans: 1,
+				NumBuildBans:     1,
+			},
+			[]draftTestStep{
+				{"", RaceStateBanningCharacters, nil, nil, [2]int{1, 1}, [2]int{0, 0}, 1},
+				{"!ban 1", RaceStateBanningCharacters, nil, nil, [2]int{0, 1}, [2]int{0, 0}, 2},
+				{"!ban 1", RaceStatePickingCharacters, nil, nil, [2]int{0, 0}, [2]int{0, 0}, 1},
+				{"!pick 1", RaceStatePickingCharacters, []string{"Cain"}, nil, [2]int{0, 0}, [2]int{0, 0}, 2},
+				{"!pick 1", RaceStateBanningBuilds, []string{"Cain", "Judas"}, nil, [2]int{1, 1}, [2]int{0, 0}, 1},
+				{"!ban 1", RaceStateBanningBuilds, []string{"Cain", "Judas"}, nil, [2]int{0, 1}, [2]int{0, 0}, 2},
+				{"!ban 2", RaceStatePickingBuilds, []string{"Cain", "Judas"}, nil, [2]int{0, 0}, [2]int{0, 0}, 1},
+				{"!pick 2", RaceStatePickingBuilds, []string{"Cain", "Judas"}, []string{"Proptosis"}, [2]int{0, 0}, [2]int{0, 0}, 2},
+				{"!pick 1", RaceStateInProgress, []string{"Cain", "Judas"}, []string{"Proptosis", "Magic Mushroom"}, [2]int{0, 0}, [2]int{0, 0}, 1},
+			},
+		},
+		{
+			"unseeded ban/pick",
+			Tournament{
+				Ruleset:          RulesetUnseeded,
+				Type:             TournamentTypeBanPick,
+				BestOf:           3,
+				NumCharacterBans: 1,
+			},
+			banPickSteps,
+		},
+		{
+			"team ban/pick",
+			Tournament{
+				Ruleset:          RulesetTeam,
+				Type:             TournamentTypeBanPick,
+				BestOf:           3,
+				NumCharacterBans: 1,
+			},
+			banPickSteps,
+		},
+		{
+			"seeded veto",
+			Tournament{
+				Ruleset:           RulesetSeeded,
+				Type:              TournamentTypeVeto,
+				BestOf:            1,
+				NumCharacterVetos: 1,
+				NumBuildVetos:     1,
+			},
+			[]draftTestStep{
+				{"", RaceStateVetoCharacters, []string{"?"}, nil, [2]int{0, 0}, [2]int{1, 1}, 1},
+				{"!no", RaceStateVetoCharacters, []string{"?"}, nil, [2]int{0, 0}, [2]int{1, 1}, 2},
+				// The other racer starts the build vetos.
+				{"!no", RaceStateVetoBuilds, []string{"?"}, []string{"?"}, [2]int{0, 0}, [2]int{1, 1}, 2},
+				{"!yes", RaceStateVetoBuilds, []string{"?"}, []string{"?"}, [2]int{0, 0}, [2]int{1, 0}, 1},
+				{"!no", RaceStateInProgress, []string{"?"}, []string{"?"}, [2]int{0, 0}, [2]int{1, 0}, 1},
+			},
+		},
+		{
+			"unseeded veto",
+			Tournament{
+				Ruleset:           RulesetUnseeded,
+				Type:              TournamentTypeVeto,
+				BestOf:            2,
+				NumCharacterVetos: 1,
+			},
+			vetoSteps,
+		},
+		{
+			"team veto",
+			Tournament{
+				Ruleset:           RulesetTeam,
+				Type:              TournamentTypeVeto,
+				BestOf:            2,
+				NumCharacterVetos: 1,
+			},
+			vetoSteps,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.tournament.Characters = draftTestCharacters
+			test.tournament.Builds = draftTestBuilds
+			g := testSetup(t, test.tournament)
+			race := g.StartMatch(t, g.AddRace(t, RaceStateScheduled))
+
+			for i, step := range test.steps {
+				var vetoed string
+				if step.command == "!yes" {
+					if race.State == RaceStateVetoCharacters {
+						vetoed = race.Characters[len(race.Characters)-1]
+					} else if race.State == RaceStateVetoBuilds {
+						vetoed = race.Builds[len(race.Builds)-1]
+					}
+				}
+
+				if step.command != "" {
+					g.Send(race.ChannelID, g.Racer(race.ActiveRacer), step.command)
+					race = testGetRace(t, race.ChannelID)
+				}
+
+				prefix := "step " + strconv.Itoa(i) + " (" + step.command + "): "
+				draftTestCheck(t, prefix, race, step)
+				if vetoed != "" &&
+					(stringInSlice(vetoed, race.Characters) || stringInSlice(vetoed, race.CharactersRemaining) ||
+						stringInSlice(vetoed, race.Builds) || stringInSlice(vetoed, race.BuildsRemaining)) {
+
+					t.Errorf("%sthe vetoed \"%s\" can still be chosen", prefix, vetoed)
+				}
+			}
+		})
+	}
+}
+
+func draftTestCheck(t *testing.T, prefix string, race *Race, step draftTestStep) {
+	t.Helper()
+
+	first := race.FirstPicker
+	other := 3 - first
+	getRacerNum := func(num int) int {
+		if num == 1 {
+			return first
+		}
+		return other
+	}
+	getPerRacer := func(racer1 int, racer2 int) [2]int {
+		if first == 1 {
+			return [2]int{racer1, racer2}
+		}
+		return [2]int{racer2, racer1}
+	}
+
+	if race.State != step.state {
+		t.Errorf("%sgot a state of %s, want %s", prefix, race.State, step.state)
+	}
+	if !draftTestMatches(race.Characters, step.characters) {
+		t.Errorf("%sgot the characters %v, want %v", prefix, race.Characters, step.characters)
+	}
+	if !draftTestMatches(race.Builds, step.builds) {
+		t.Errorf("%sgot the builds %v, want %v", prefix, race.Builds, step.builds)
+	}
+	if bans := getPerRacer(race.Racer1Bans, race.Racer2Bans); bans != step.bans {
+		t.Errorf("%sgot bans of %v, want %v", prefix, bans, step.bans)
+	}
+	if vetos := getPerRacer(race.Racer1Vetos, race.Racer2Vetos); vetos != step.vetos {
+		t.Errorf("%sgot vetos of %v, want %v", prefix, vetos, step.vetos)
+	}
+	if want := getRacerNum(step.activeRacer); race.ActiveRacer != want {
+		t.Errorf("%sgot an active racer of %d, want %d", prefix, race.ActiveRacer, want)
+	}
+
+	// Nothing can be chosen twice, and nothing that was chosen can still be chosen.
+	for _, lists := range [][2][]string{
+		{race.Characters, race.CharactersRemaining},
+		{race.Builds, race.BuildsRemaining},
+	} {
+		seen := make(map[string]bool)
+		for _, thing := range append(append([]string{}, lists[0]...), lists[1]...) {
+			if seen[thing] {
+				t.Errorf("%s\"%s\" is in the draft more than once: %v and %v", prefix, thing, lists[0], lists[1])
+			}
+			seen[thing] = true
+		}
+	}
+}
+
+// Get whether the chosen characters or builds match the expected ones, where "?" matches anything.
+func draftTestMatches(got []string, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range want {
+		if want[i] != "?" && got[i] != want[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Parse a winning time in the format of "m:ss" or "h:mm:ss" (e.g. "12:34") into seconds.
+func gameParseTime(timeString string) (int, error) {
+	parts := strings.Split(timeString, ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return 0, errors.New("\"" + timeString + "\" is not a valid time. Use the format of \"m:ss\" or \"h:mm:ss\".")
+	}
+
+	seconds := 0
+	for i, part := range parts {
+		var value int
+		if v, err := strconv.Atoi(part); err != nil || v < 0 {
+			return 0, errors.New("\"" + timeString + "\" is not a valid time. Use the format of \"m:ss\" or \"h:mm:ss\".")
+		} else {
+			value = v
+		}
+
+		// The minutes (if there are hours) and the seconds cannot go past 59.
+		if i > 0 && value >= 60 {
+			return 0, errors.New("\"" + timeString + "\" is not a valid time. Use the format of \"m:ss\" or \"h:mm:ss\".")
+		}
+
+		seconds = seconds*60 + value
+	}
+
+	if seconds == 0 {
+		return 0, errors.New("The time cannot be 0.")
+	}
+
+	return seconds, nil
+}
+
+// Format a winning time in seconds, e.g. "12:34" or "1:02:03".
+func gameFormatTime(seconds int) string {
+	hours := seconds / 3600
+	minutes := (seconds % 3600) / 60
+	seconds = seconds % 60
+
+	msg := ""
+	if hours > 0 {
+		msg += strconv.Itoa(hours) + ":"
+		if minutes < 10 {
+			msg += "0"
+		}
+	}
+	msg += strconv.Itoa(minutes) + ":"
+	if seconds < 10 {
+		msg += "0"
+	}
+	msg += strconv.Itoa(seconds)
+
+	return msg
+}
+
+// Get the number of games that each racer has won.
+func gameGetWins(games []*RaceGame) (int, int) {
+	p1Wins := 0
+	p2Wins := 0
+	for _, game := range games {
+		if game.Winner == 1 {
+			p1Wins++
+		} else if game.Winner == 2 {
+			p2Wins++
+		}
+	}
+
+	return p1Wins, p2Wins
+}
+
+// Get the result of each game in the format that Challonge expects, with racer 1's wins first.
+// (e.g. "1-0,0-1,1-0")
+func gameGetScoresCSV(games []*RaceGame) string {
+	scores := make([]string, 0)
+	for _, game := range games {
+		if game.Winner == 1 {
+			scores = append(scores, "1-0")
+		} else {
+			scores = append(scores, "0-1")
+		}
+	}
+
+	return strings.Join(scores, ",")
+}
+
+// Get a line for the "!game" command, e.g. "Game 1 (Isaac / Mom's Knife): Zamiel won in 12:34"
+func gameGetDescription(race *Race, game *RaceGame) string {
+	msg := "Game " + strconv.Itoa(game.GameNum) + " (" + game.Character
+	if game.Build != "" {
+		msg += " / " + game.Build
+	}
+	msg += "): "
+
+	if game.Winner == 1 {
+		msg += "`" + race.Racer1.Username + "`"
+	} else {
+		msg += "`" + race.Racer2.Username + "`"
+	}
+	msg += " won"
+	if game.WinningTime.Valid {
+		msg += " in " + gameFormatTime(int(game.WinningTime.Int64))
+	}
+
+	return msg
+}
@@ -15,6 +15,7 @@ CREATE TABLE tournament_races (
     id                    INT            NOT NULL  PRIMARY KEY  AUTO_INCREMENT,
     /* PRIMARY KEY automatically creates a UNIQUE constraint */
     tournament_name       NVARCHAR(500)  NOT NULL,
+    season                NVARCHAR(100)  NOT NULL  DEFAULT "", /* The season of the tournament, if any; used to filter the "!stats" command */
     racer1                INT            NOT NULL, /* The "tournament_users" database ID */
     racer1_challonge_id   INT            NOT NULL, /* The "participant" ID; needed to automatically set the winner through the Challonge API */
     racer2                INT            NOT NULL, /* The "tournament_users" database ID */
@@ -23,26 +24,35 @@ CREATE TABLE tournament_races (
     channel_name          NVARCHAR(500)  NOT NULL,
     challonge_url         NVARCHAR(100)  NOT NULL, /* The suffix of the Challonge URL for this tournament */
     challonge_match_id    NVARCHAR(100)  NOT NULL,
-    bracket_round         NVARCHAR(10)   NOT NULL,
+    bracket_round         NVARCHAR(10)   NOT NULL, /* Negative for the losers bracket of a double elimination tournament */
+    bracket_group_id      INT            NOT NULL  DEFAULT 0, /* The Challonge "group_id" (or 0 if the match is not in a group stage) */
+    bracket_group         NVARCHAR(10)   NOT NULL  DEFAULT "", /* The letter of the group, e.g. "A" */
+    bracket_identifier    NVARCHAR(10)   NOT NULL  DEFAULT "", /* The letter(s) of the match on the bracket, e.g. "A" or "AB" */
     state                 NVARCHAR(50)   NOT NULL, /* Definitions are listed in the "Race" struct */
     datetime_created      TIMESTAMP      NOT NULL  DEFAULT NOW(),
     datetime_scheduled    TIMESTAMP      NULL      DEFAULT NULL,
     first_picker          INT            NOT NULL  DEFAULT 1,
     active_racer          INT            NOT NULL  DEFAULT 1,
-    characters_remaining  NVARCHAR(500)  NOT NULL,
-    characters            NVARCHAR(500)  NOT NULL  DEFAULT "",
-    builds_remaining      NVARCHAR(500)  NOT NULL,
-    builds                NVARCHAR(500)  NOT NULL  DEFAULT "",
+    characters_remaining  NVARCHAR(2000) NOT NULL, /* Comma separated; these are long enough for large best-of formats */
+    characters            NVARCHAR(2000) NOT NULL  DEFAULT "",
+    builds_remaining      NVARCHAR(2000) NOT NULL,
+    builds                NVARCHAR(2000) NOT NULL  DEFAULT "",
     racer1_bans           INT            NOT NULL,
     racer2_bans           INT            NOT NULL,
     racer1_vetos          INT            NOT NULL,
     racer2_vetos          INT            NOT NULL,
     num_voted             INT            NOT NULL  DEFAULT 0,
-    score                 NVARCHAR(10)   NULL      DEFAULT NULL, /* e.g. "3-2" */
+    score                 NVARCHAR(10)   NULL      DEFAULT NULL, /* e.g. "3-2", with racer 1's wins first */
+    winner                INT            NOT NULL  DEFAULT 0, /* 1 or 2 (or 0 if the score has not been reported yet) */
+    score_reporter        INT            NOT NULL  DEFAULT 0, /* 1 or 2 (or 0 if the score was forced by an admin) */
+    datetime_completed    TIMESTAMP      NULL      DEFAULT NULL,
+    archived              TINYINT(1)     NOT NULL  DEFAULT 0, /* 1 once the channel has been moved to the archive category by "!endround" */
     FOREIGN KEY (racer1) REFERENCES tournament_users (id) ON DELETE CASCADE,
     FOREIGN KEY (racer2) REFERENCES tournament_users (id) ON DELETE CASCADE
 );
 CREATE INDEX tournament_races_index_channel_id ON tournament_races (channel_id);
+CREATE INDEX tournament_races_index_state ON tournament_races (state);
+CREATE INDEX tournament_races_index_challonge_url ON tournament_races (challonge_url);
 
 DROP TABLE IF EXISTS tournament_users;
 CREATE TABLE tournament_users (
@@ -53,10 +63,16 @@ CREATE TABLE tournament_users (
     timezone                 NVARCHAR(100)  NULL      DEFAULT NULL,
     /* The TZ column of: https://en.wikipedia.org/wiki/List_of_tz_database_time_zones */
     stream_url               NVARCHAR(255)  NULL      DEFAULT NULL,
-    caster_always_ok         TINYINT(1)     NOT NULL  DEFAULT 0
+    caster_always_ok         TINYINT(1)     NOT NULL  DEFAULT 0,
+    /* The Glicko-2 rating, which is updated after every completed match (see "rating.go") */
+    rating                   DOUBLE         NOT NULL  DEFAULT 1500,
+    rating_deviation         DOUBLE         NOT NULL  DEFAULT 350,
+    rating_volatility        DOUBLE         NOT NULL  DEFAULT 0.06,
+    rated_matches            INT            NOT NULL  DEFAULT 0
 );
 CREATE INDEX tournament_users_index_discord_id ON tournament_users (discord_id);
 CREATE INDEX tournament_users_index_username ON tournament_users (username);
+CREATE INDEX tournament_users_index_rating ON tournament_users (rating);
 
 DROP TABLE IF EXISTS tournament_casts;
 CREATE TABLE tournament_casts (
@@ -71,3 +87,81 @@ CREATE TABLE tournament_casts (
     UNIQUE(race_id, caster), /* The same person cannot cast the same race more than once */
     UNIQUE(race_id, language) /* There cannot be two casts of the same race in the same language */
 );
+
+DROP TABLE IF EXISTS tournament_race_events;
+CREATE TABLE tournament_race_events (
+    id                INT            NOT NULL  PRIMARY KEY  AUTO_INCREMENT,
+    race_id           INT            NOT NULL, /* The "tournament_races" database ID */
+    event_type        NVARCHAR(50)   NOT NULL, /* Definitions are listed in the "raceEventType.go" file */
+    actor             INT            NULL      DEFAULT NULL, /* The "tournament_users" database ID of the person who did it; null for the bot */
+    racer_num         INT            NOT NULL  DEFAULT 0, /* The racer that it was done on behalf of (1 or 2), or 0 if neither */
+    value             NVARCHAR(500)  NOT NULL  DEFAULT "", /* e.g. the character that was banned or the score that was reported */
+    draft_type        NVARCHAR(20)   NOT NULL  DEFAULT "", /* "character" or "build" for a ban, pick, or veto; used by the "!stats" command */
+    snapshot          TEXT           NULL      DEFAULT NULL, /* The JSON of the draft before a ban, pick, or veto; used by the "!undo" command */
+    cause_event_id    INT            NULL      DEFAULT NULL, /* The "tournament_race_events" database ID of the force command or timeout that caused it, if any */
+    undone            TINYINT(1)     NOT NULL  DEFAULT 0,
+    datetime_created  TIMESTAMP      NOT NULL  DEFAULT NOW(),
+    FOREIGN KEY (race_id) REFERENCES tournament_races (id) ON DELETE CASCADE,
+    FOREIGN KEY (actor) REFERENCES tournament_users (id) ON DELETE SET NULL
+);
+CREATE INDEX tournament_race_events_index_race_id ON tournament_race_events (race_id);
+
+DROP TABLE IF EXISTS tournament_race_games;
+CREATE TABLE tournament_race_games (
+    id                INT            NOT NULL  PRIMARY KEY  AUTO_INCREMENT,
+    race_id           INT            NOT NULL, /* The "tournament_races" database ID */
+    game_num          INT            NOT NULL, /* Starting at 1 */
+    winner            INT            NOT NULL, /* 1 or 2 */
+    character_name    NVARCHAR(100)  NOT NULL, /* The character from the "characters" column of the race */
+    build_name        NVARCHAR(100)  NOT NULL  DEFAULT "", /* The build from the "builds" column of the race, if any */
+    winning_time      INT            NULL      DEFAULT NULL, /* In seconds */
+    reporter          INT            NULL      DEFAULT NULL, /* The "tournament_users" database ID */
+    datetime_created  TIMESTAMP      NOT NULL  DEFAULT NOW(),
+    FOREIGN KEY (race_id) REFERENCES tournament_races (id) ON DELETE CASCADE,
+    FOREIGN KEY (reporter) REFERENCES tournament_users (id) ON DELETE SET NULL,
+    UNIQUE(race_id, game_num) /* Reporting the same game again replaces it */
+);
+
+DROP TABLE IF EXISTS tournament_race_messages;
+CREATE TABLE tournament_race_messages (
+    /* The message log of each race channel, saved when the channel is archived */
+    id                 INT            NOT NULL  PRIMARY KEY  AUTO_INCREMENT,
+    race_id            INT            NOT NULL, /* The "tournament_races" database ID */
+    message_id         NVARCHAR(100)  NOT NULL, /* The Discord message ID */
+    author_discord_id  NVARCHAR(100)  NOT NULL,
+    author_username    NVARCHAR(100)  NOT NULL,
+    content            NVARCHAR(4000) NOT NULL,
+    attachments        NVARCHAR(4000) NOT NULL  DEFAULT "", /* The URLs of the attachments, one per line */
+    datetime_sent      TIMESTAMP      NOT NULL  DEFAULT NOW(),
+    FOREIGN KEY (race_id) REFERENCES tournament_races (id) ON DELETE CASCADE,
+    UNIQUE(race_id, message_id) /* Archiving the same channel again does not duplicate the messages */
+);
+
+DROP TABLE IF EXISTS tournament_pairings;
+CREATE TABLE tournament_pairings (
+    /* The pairings of the tournaments that are paired by the bot itself (i.e. Swiss and round-robin) */
+    id                INT            NOT NULL  PRIMARY KEY  AUTO_INCREMENT,
+    /* PRIMARY KEY automatically creates a UNIQUE constraint */
+    challonge_url     NVARCHAR(100)  NOT NULL, /* The suffix of the Challonge URL for this tournament */
+    round             INT            NOT NULL, /* Starting at 1 */
+    player1_id        INT            NOT NULL, /* The "participant" ID */
+    player1_name      NVARCHAR(100)  NOT NULL,
+    player2_id        INT            NOT NULL, /* The "participant" ID (or 0 if player 1 has a bye) */
+    player2_name      NVARCHAR(100)  NOT NULL  DEFAULT "",
+    score             NVARCHAR(10)   NULL      DEFAULT NULL, /* e.g. "3-2", with player 1's wins first */
+    winner            INT            NOT NULL  DEFAULT 0, /* 1 or 2 (or 0 if the match has not been played yet) */
+    datetime_created  TIMESTAMP      NOT NULL  DEFAULT NOW()
+);
+CREATE INDEX tournament_pairings_index_challonge_url ON tournament_pairings (challonge_url);
+
+DROP TABLE IF EXISTS tournament_timers;
+CREATE TABLE tournament_timers (
+    id                INT            NOT NULL  PRIMARY KEY  AUTO_INCREMENT,
+    action            NVARCHAR(50)   NOT NULL, /* Definitions are listed in the "scheduler.go" file */
+    channel_id        NVARCHAR(100)  NOT NULL  DEFAULT "", /* The Discord channel ID of the race that this timer is for, if any */
+    payload           NVARCHAR(500)  NOT NULL  DEFAULT "", /* Extra data for the action, e.g. the scheduled time of the match */
+    datetime_fire     TIMESTAMP      NOT NULL  DEFAULT NOW(),
+    datetime_created  TIMESTAMP      NOT NULL  DEFAULT NOW()
+);
+CREATE INDEX tournament_timers_index_datetime_fire ON tournament_timers (datetime_fire);
+CREATE INDEX tournament_timers_index_channel_id ON tournament_timers (channel_id);
@@ -14,3 +14,15 @@ func languageInit() {
 		"pl": "Polish",
 	}
 }
+
+// Get the full name of a language, e.g. "English" for "en".
+func getLanguageName(race *Race, language string) string {
+	if name, ok := tournaments[race.ChallongeURL].Languages[language]; ok {
+		return name
+	}
+	if name, ok := languageMap[language]; ok {
+		return name
+	}
+
+	return language
+}
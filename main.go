@@ -56,12 +56,19 @@ func main() {
 	defer modals.Close()
 
 	// Initialize the other parts of the program.
+	randomInit()
 	loadAllBuilds()
+	languageInit()
+	reminderInit()
 	discordInit()
-	defer discordSession.Close()
-	challongeInit()
+	defer discordGateway.Close()
+	archiveInit()
+	bracketInit()
+	schedulerInit()
 	matchInit()
-	languageInit()
+	seasonInit()
+	bracketPollInit()
+	reloadInit()
 	log.Info("The bot has successfully initialized.")
 
 	// Wait here until CTRL-C or other term signal is received.
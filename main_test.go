@@ -0,0 +1,349 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/server"
+	logging "github.com/op/go-logging"
+	"github.com/sirupsen/logrus"
+)
+
+/*
+	The tests run the command handlers against an in-memory MySQL server that is loaded with the
+	real schema (from "install/database_schema.sql") and against the fake Discord guild in
+	"discordFake_test.go". Every test starts with empty tables and a new guild.
+*/
+
+const (
+	testGuildID       = "guild"
+	testTournamentURL = "test_tournament"
+)
+
+var (
+	// The tables are dropped in this order so that the foreign keys are never violated.
+	testTables = []string{
+		"tournament_race_messages",
+		"tournament_race_games",
+		"tournament_race_events",
+		"tournament_casts",
+		"tournament_timers",
+		"tournament_pairings",
+		"tournament_races",
+		"tournament_users",
+	}
+)
+
+// TestGuild is the fake Discord guild for a single test, along with the members that the tests
+// act as.
+type TestGuild struct {
+	Session *FakeDiscordSession
+	Admin   *discordgo.Member
+	Racer1  *discordgo.Member
+	Racer2  *discordgo.Member
+}
+
+func TestMain(m *testing.M) {
+	log = logging.MustGetLogger("isaac-tournament-bot")
+	logging.SetBackend(logging.NewLogBackend(io.Discard, "", 0))
+	logrus.SetOutput(io.Discard) // The database server logs every connection.
+	projectPath = "."
+
+	var s *server.Server
+	if v, err := testStartDatabase(); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to start the test database: "+err.Error())
+		os.Exit(1)
+	} else {
+		s = v
+	}
+	modals = &Models{}
+
+	loadAllBuilds()
+	languageInit()
+	commandInit()
+	schedulerInit()
+
+	code := m.Run()
+	s.Close()
+	os.Exit(code)
+}
+
+// Start an in-memory database server on a free port and create the tables.
+func testStartDatabase() (*server.Server, error) {
+	var address string
+	if listener, err := net.Listen("tcp", "127.0.0.1:0"); err != nil {
+		return nil, err
+	} else {
+		address = listener.Addr().String()
+		listener.Close()
+	}
+
+	engine := sqle.NewDefault(memory.NewDBProvider(memory.NewDatabase("isaac")))
+	var s *server.Server
+	if v, err := server.NewDefaultServer(server.Config{
+		Protocol: "tcp",
+		Address:  address,
+	}, engine); err != nil {
+		return nil, err
+	} else {
+		s = v
+	}
+	go s.Start()
+
+	if v, err := sql.Open("mysql", "root:@tcp("+address+")/isaac?parseTime=true"); err != nil {
+		return nil, err
+	} else {
+		db = v
+	}
+
+	if err := testLoadSchema(); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Run the statements in the schema file. The in-memory server is stricter than MariaDB:
+//   - It does not understand comments.
+//   - Every foreign key needs a unique name.
+//   - A foreign key is only enforced if it is created with the checks on, which means that the
+//     parent table has to exist first and it needs an index on the referenced column (the primary
+//     key does not count).
+//
+// So the statements are adjusted and run in an order where every table that they use exists.
+func testLoadSchema() error {
+	var schema string
+	if v, err := os.ReadFile("install/database_schema.sql"); err != nil {
+		return err
+	} else {
+		schema = string(v)
+	}
+	schema = regexp.MustCompile(`(?s)/\*.*?\*/`).ReplaceAllString(schema, "")
+	foreignKeyRegExp := regexp.MustCompile(`FOREIGN KEY`)
+	createTableRegExp := regexp.MustCompile(`^CREATE TABLE (\w+)`)
+	usedTableRegExp := regexp.MustCompile(`(?:REFERENCES|ON) (\w+) \(`)
+
+	pending := make([]string, 0)
+	for i, stmt := range strings.Split(schema, ";") {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" || strings.HasPrefix(stmt, "SET FOREIGN_KEY_CHECKS") {
+			continue
+		}
+
+		numForeignKeys := 0
+		stmt = foreignKeyRegExp.ReplaceAllStringFunc(stmt, func(match string) string {
+			numForeignKeys++
+			return "CONSTRAINT fk_" + strconv.Itoa(i) + "_" + strconv.Itoa(numForeignKeys) + " " + match
+		})
+		pending = append(pending, stmt)
+	}
+
+	created := make(map[string]bool)
+	for len(pending) > 0 {
+		remaining := make([]string, 0)
+		for _, stmt := range pending {
+			ready := true
+			for _, match := range usedTableRegExp.FindAllStringSubmatch(stmt, -1) {
+				if !created[match[1]] {
+					ready = false
+				}
+			}
+			if !ready {
+				remaining = append(remaining, stmt)
+				continue
+			}
+
+			if _, err := db.Exec(stmt); err != nil {
+				return fmt.Errorf("failed to run \"%s\": %w", stmt, err)
+			}
+
+			if match := createTableRegExp.FindStringSubmatch(stmt); match != nil {
+				table := match[1]
+				created[table] = true
+				if _, err := db.Exec("CREATE INDEX " + table + "_index_id ON " + table + " (id)"); err != nil {
+					return err
+				}
+			}
+		}
+		if len(remaining) == len(pending) {
+			return errors.New("failed to find the tables for: " + remaining[0])
+		}
+		pending = remaining
+	}
+
+	return nil
+}
+
+// Create the tables again, stop all of the timers, reset the random seed, and create a new fake guild
+// with an admin and two racers. The tournament is registered with the defaults filled in.
+func testSetup(t *testing.T, tournament Tournament) *TestGuild {
+	t.Helper()
+
+	// The in-memory server does not keep the foreign key indexes up to date when rows are deleted,
+	// so the tables are created again instead of being emptied.
+	for _, table := range testTables {
+		if _, err := db.Exec("DROP TABLE " + table); err != nil {
+			t.Fatalf("Failed to drop the \"%s\" table: %v", table, err)
+		}
+	}
+	if err := testLoadSchema(); err != nil {
+		t.Fatalf("Failed to create the tables: %v", err)
+	}
+
+	// The draft is random, so every test gets the same draws.
+	setRandomSeed(1)
+
+	schedulerMutex.Lock()
+	schedulerQueue = make(SchedulerQueue, 0)
+	schedulerArm()
+	schedulerMutex.Unlock()
+
+	session := NewFakeDiscordSession(testGuildID, "Isaac Tournaments")
+	everyoneRole := session.AddRole("@everyone")
+	adminRole := session.AddRole(discordAdminRoleName)
+	botRole := session.AddRole(discordBotRoleName)
+	casterRole := session.AddRole(discordCasterRoleName)
+	teamCaptainRole := session.AddRole(discordTeamCaptainRoleName)
+	bot := session.AddMember("Willy", botRole.ID)
+	generalChannel := session.AddChannel(discordGeneralChannelName, discordgo.ChannelTypeGuildText, "")
+
+	discordSession = session
+	discordBotID = bot.User.ID
+	discordGuildName = session.GuildName
+	discordGuildID = session.GuildID
+	discordAdminRoleID = adminRole.ID
+	discordBotRoleID = botRole.ID
+	discordCasterRoleID = casterRole.ID
+	discordEveryoneRoleID = everyoneRole.ID
+	discordTeamCaptainRoleID = teamCaptainRole.ID
+	discordGeneralChannelID = generalChannel.ID
+	archiveCategoryID = ""
+
+	category := session.AddChannel("Test Tournament", discordgo.ChannelTypeGuildCategory, "")
+	tournament.Name = "Test Tournament"
+	tournament.ChallongeURL = testTournamentURL
+	tournament.DiscordCategoryID = category.ID
+	tournamentFillDefaults(&tournament)
+	tournaments = map[string]Tournament{
+		tournament.ChallongeURL: tournament,
+	}
+
+	return &TestGuild{
+		Session: session,
+		Admin:   session.AddMember("Zamiel", adminRole.ID),
+		Racer1:  session.AddMember("Dea1h"),
+		Racer2:  session.AddMember("Cyber_1"),
+	}
+}
+
+// Create a race channel between the two racers and put the race in the database. The race has the
+// characters and builds of the tournament left to draft.
+func (g *TestGuild) AddRace(t *testing.T, state RaceState) *Race {
+	t.Helper()
+
+	for _, member := range []*discordgo.Member{g.Racer1, g.Racer2} {
+		if _, err := userGet(member.User); err != nil {
+			t.Fatalf("Failed to add \"%s\" to the database: %v", member.User.Username, err)
+		}
+	}
+
+	tournament := tournaments[testTournamentURL]
+	name := g.Racer1.User.Username + "-vs-" + g.Racer2.User.Username
+	channel := g.Session.AddChannel(name, discordgo.ChannelTypeGuildText, tournament.DiscordCategoryID)
+	race := &Race{
+		TournamentName:      tournament.Name,
+		Season:              tournament.Season,
+		ChannelID:           channel.ID,
+		ChannelName:         channel.Name,
+		ChallongeURL:        tournament.ChallongeURL,
+		ChallongeMatchID:    "1",
+		BracketRound:        "1",
+		State:               state,
+		CharactersRemaining: append([]string{}, tournament.Characters...),
+		BuildsRemaining:     append([]string{}, tournament.Builds...),
+	}
+	if err := modals.Races.Insert(g.Racer1.User.ID, g.Racer2.User.ID, race); err != nil {
+		t.Fatalf("Failed to insert the race: %v", err)
+	}
+
+	return testGetRace(t, channel.ID)
+}
+
+// Schedule the race for right now and start it, the same way that the match start timer does.
+func (g *TestGuild) StartMatch(t *testing.T, race *Race) *Race {
+	t.Helper()
+
+	datetimeScheduled := time.Now().UTC().Truncate(time.Second)
+	if err := modals.Races.SetDatetimeScheduled(race.ChannelID, datetimeScheduled, race.ActiveRacer); err != nil {
+		t.Fatalf("Failed to schedule the race: %v", err)
+	}
+	matchStart(race.ChannelID, datetimeScheduled)
+
+	return testGetRace(t, race.ChannelID)
+}
+
+// Get the member for racer 1 or racer 2.
+func (g *TestGuild) Racer(racerNum int) *discordgo.Member {
+	if racerNum == 1 {
+		return g.Racer1
+	}
+
+	return g.Racer2
+}
+
+// Send a message to the channel as the member, the same way that Discord would.
+func (g *TestGuild) Send(channelID string, member *discordgo.Member, content string) {
+	discordMessageCreate(nil, &discordgo.MessageCreate{
+		Message: &discordgo.Message{
+			ChannelID: channelID,
+			GuildID:   g.Session.GuildID,
+			Author:    member.User,
+			Content:   content,
+		},
+	})
+}
+
+// Get the last message that was sent to the channel (or a blank string if there are none).
+func (g *TestGuild) LastMessage(channelID string) string {
+	messages := g.Session.GetMessages(channelID)
+	if len(messages) == 0 {
+		return ""
+	}
+
+	return messages[len(messages)-1]
+}
+
+func testGetRace(t *testing.T, channelID string) *Race {
+	t.Helper()
+
+	race, err := getRace(channelID)
+	if err != nil {
+		t.Fatalf("Failed to get the race from the database: %v", err)
+	}
+
+	return race
+}
+
+func testGetEvents(t *testing.T, channelID string) []*RaceEvent {
+	t.Helper()
+
+	events, err := modals.RaceEvents.GetAll(channelID)
+	if err != nil {
+		t.Fatalf("Failed to get the events from the database: %v", err)
+	}
+
+	return events
+}
@@ -1,134 +1,126 @@
 package main
 
 import (
-	"os"
+	"errors"
 	"strconv"
 	"time"
 )
 
-var (
-	tournamentType    TournamentType
-	numCharacterBans  int
-	numBuildBans      int
-	numCharacterVetos int
-	numBuildVetos     int
-)
-
 func matchInit() {
-	// Read the configuration from environment variables.
-	tournamentTypeString := os.Getenv("TOURNAMENT_TYPE")
-	if len(tournamentTypeString) == 0 {
-		log.Fatal("The \"TOURNAMENT_TYPE\" environment variable is blank. Set it in the \".env\" file.")
+	// Make sure that every scheduled match has a timer.
+	// (The timers themselves are loaded from the database by the scheduler.)
+	if err := matchReconcileTimers(); err != nil {
+		log.Fatal(err.Error())
 		return
 	}
+}
 
-	if tournamentTypeString != "banPick" && tournamentTypeString != "veto" {
-		log.Fatal("The \"TOURNAMENT_TYPE\" environment variable is set to \"" + tournamentTypeString + "\", which is an invalid value.")
-		return
+// Make sure that there is exactly one start timer (and one timer for each reminder) for each
+// scheduled match in the database. This is safe to call more than once (e.g. after reloading the
+// configuration).
+func matchReconcileTimers() error {
+	var channelIDs []string
+	if v, err := modals.Races.GetAllScheduled(); err != nil {
+		return errors.New("Failed to get the scheduled races: " + err.Error())
+	} else {
+		channelIDs = v
 	}
-	tournamentType = TournamentType(tournamentTypeString)
 
-	numCharacterBansString := os.Getenv("NUM_CHARACTER_BANS")
-	if len(numCharacterBansString) == 0 {
-		log.Fatal("The \"NUM_CHARACTER_BANS\" environment variable is blank. Set it in the \".env\" file.")
-		return
-	}
+	for _, channelID := range channelIDs {
+		var race *Race
+		if v, err := getRace(channelID); err != nil {
+			return errors.New("Failed to get the race from the database: " + err.Error())
+		} else {
+			race = v
+		}
 
-	if v, err := strconv.Atoi(numCharacterBansString); err != nil {
-		log.Fatal("The \"NUM_CHARACTER_BANS\" environment variable is not a number.")
-		return
-	} else {
-		numCharacterBans = v
+		if err := matchScheduleStart(race); err != nil {
+			return errors.New("Failed to schedule the start of race \"" + race.Name() + "\": " + err.Error())
+		}
 	}
 
-	numBuildBansString := os.Getenv("NUM_BUILD_BANS")
-	if len(numBuildBansString) == 0 {
-		log.Fatal("The \"NUM_BUILD_BANS\" environment variable is blank. Set it in the \".env\" file.")
-		return
+	// Remove the timers for matches that are no longer scheduled.
+	for _, timer := range schedulerGetAll() {
+		if (timer.Action == TimerActionMatchStart || timer.Action == TimerActionReminder) && !stringInSlice(timer.ChannelID, channelIDs) {
+			if err := schedulerCancel(timer.ID); err != nil {
+				return err
+			}
+		}
 	}
 
-	if v, err := strconv.Atoi(numBuildBansString); err != nil {
-		log.Fatal("The \"NUM_BUILD_BANS\" environment variable is not a number.")
-		return
-	} else {
-		numBuildBans = v
-	}
+	return nil
+}
 
-	numCharacterVetosString := os.Getenv("NUM_CHARACTER_VETOS")
-	if len(numCharacterVetosString) == 0 {
-		log.Fatal("The \"NUM_CHARACTER_VETOS\" environment variable is blank. Set it in the \".env\" file.")
-		return
+// Start the match 5 minutes before the scheduled time. If the match already has a start timer for
+// the same time, nothing happens; if it has one for a different time, the old one is replaced. The
+// reminders before the match are scheduled in the same way.
+func matchScheduleStart(race *Race) error {
+	if err := reminderSchedule(race); err != nil {
+		return err
 	}
 
-	if v, err := strconv.Atoi(numCharacterVetosString); err != nil {
-		log.Fatal("The \"NUM_CHARACTER_VETOS\" environment variable is not a number.")
-		return
-	} else {
-		numCharacterVetos = v
+	payload := race.DatetimeScheduled.Time.UTC().Format(time.RFC3339)
+	alreadyScheduled := false
+	for _, timer := range schedulerGetAll() {
+		if timer.Action != TimerActionMatchStart || timer.ChannelID != race.ChannelID {
+			continue
+		}
+		if timer.Payload == payload && !alreadyScheduled {
+			alreadyScheduled = true
+			continue
+		}
+		if err := schedulerCancel(timer.ID); err != nil {
+			return err
+		}
 	}
-
-	numBuildVetosString := os.Getenv("NUM_BUILD_VETOS")
-	if len(numBuildVetosString) == 0 {
-		log.Fatal("The \"NUM_BUILD_VETOS\" environment variable is blank. Set it in the \".env\" file.")
-		return
+	if alreadyScheduled {
+		return nil
 	}
 
-	if v, err := strconv.Atoi(numBuildVetosString); err != nil {
-		log.Fatal("The \"NUM_BUILD_VETOS\" environment variable is not a number.")
-		return
-	} else {
-		numBuildVetos = v
-	}
+	datetimeFire := race.DatetimeScheduled.Time.Add(-5 * time.Minute)
+	_, err := schedulerAdd(TimerActionMatchStart, race.ChannelID, payload, datetimeFire)
+	return err
+}
 
-	// Schedule Discord pings for when each scheduled match starts.
-	var channelIDs []string
-	if v, err := modals.Races.GetAllScheduled(); err != nil {
-		log.Fatal("Failed to get the scheduled races: " + err.Error())
-		return
-	} else {
-		channelIDs = v
+func matchCancelStart(channelID string) error {
+	if err := schedulerCancelAll(channelID, TimerActionReminder); err != nil {
+		return err
 	}
-	for _, channelID := range channelIDs {
-		var race *Race
-		if v, err := getRace(channelID); err != nil {
-			log.Fatal("Failed to get the race from the database: " + err.Error())
-			return
-		} else {
-			race = v
-		}
 
-		go matchStart(race)
-	}
+	return schedulerCancelAll(channelID, TimerActionMatchStart)
 }
 
-func matchStart(race *Race) {
-	// Sleep until the match starts.
-	origStartTime := race.DatetimeScheduled.Time
-	sleepDuration := race.DatetimeScheduled.Time.Sub(time.Now().UTC())
-	if sleepDuration < 5*time.Minute {
-		sleepDuration = 0
+func matchStartTimerFired(timer *ScheduledTimer) {
+	var origStartTime time.Time
+	if v, err := time.Parse(time.RFC3339, timer.Payload); err != nil {
+		log.Error("Failed to parse the scheduled time of \"" + timer.Payload + "\" for timer " + strconv.Itoa(timer.ID) + ": " + err.Error())
+		return
 	} else {
-		sleepDuration -= 5 * time.Minute
+		origStartTime = v
 	}
-	time.Sleep(sleepDuration)
 
+	matchStart(timer.ChannelID, origStartTime)
+}
+
+func matchStart(channelID string, origStartTime time.Time) {
 	// Re-get the race from the database.
-	if v, err := getRace(race.ChannelID); err != nil {
+	var race *Race
+	if v, err := getRace(channelID); err != nil {
 		msg := "Failed to re-get the race from the database: " + err.Error()
 		log.Error(msg)
-		discordSend(race.ChannelID, msg)
+		discordSend(channelID, msg)
 		return
 	} else {
 		race = v
 	}
 
 	// Check to see if the race has been rescheduled.
-	if origStartTime != race.DatetimeScheduled.Time {
+	if !origStartTime.Equal(race.DatetimeScheduled.Time) {
 		return
 	}
 
 	// Check to see if this match has started already.
-	if race.State != RaceStateScheduled {
+	if !raceCanTrigger(race, RaceTriggerMatchStart) {
 		log.Info("Reached the \"matchStart\" function when the state was " + race.State + ". Doing nothing.")
 		return
 	}
@@ -156,14 +148,18 @@ func matchStart(race *Race) {
 	msg += matchGetDescription(race)
 	discordSend(discordGeneralChannelID, msg)
 
-	if tournamentType == TournamentTypeBanPick {
+	tournament := tournaments[race.ChallongeURL]
+	if tournament.Type == TournamentTypeBanPick {
 		charactersBanStart(race)
-	} else if tournamentType == TournamentTypeVeto {
+	} else if tournament.Type == TournamentTypeVeto {
 		charactersVetoStart(race)
 	} else {
 		msg := "Unknown tournament type for tournament: " + race.TournamentName
 		discordSend(discordGeneralChannelID, msg)
 	}
+
+	// Start the timer for the first turn.
+	turnSchedule(race)
 }
 
 func matchBeginningAlert(race *Race) string {
@@ -172,7 +168,7 @@ func matchBeginningAlert(race *Race) string {
 
 	// Alert the casters that the race is about to start.
 	for _, cast := range race.Casts {
-		msg += cast.Caster.Mention() + ", you are scheduled to cast this match in " + languageMap[cast.Language] + " in 5 minutes at: <" + cast.Caster.StreamURL.String + ">\n\n"
+		msg += cast.Caster.Mention() + ", you are scheduled to cast this match in " + getLanguageName(race, cast.Language) + " in 5 minutes at: <" + cast.Caster.StreamURL.String + ">\n\n"
 	}
 
 	return msg
@@ -188,7 +184,7 @@ func matchGetDescription(race *Race) string {
 	for _, cast := range race.Casts {
 		if cast.R1Permission && cast.R2Permission {
 			atLeastOneCaster = true
-			msg += "`" + cast.Caster.Username + "` has volunteered to cast the match in " + languageMap[cast.Language] + " at:\n"
+			msg += "`" + cast.Caster.Username + "` has volunteered to cast the match in " + getLanguageName(race, cast.Language) + " at:\n"
 			msg += "<" + cast.Caster.StreamURL.String + ">\n"
 		}
 	}
@@ -202,8 +198,11 @@ func matchGetDescription(race *Race) string {
 }
 
 func matchSetInProgressAndPrintSummary(race *Race, msg string) {
-	race.State = RaceStateInProgress
-	if err := modals.Races.SetState(race.ChannelID, race.State); err != nil {
+	trigger := RaceTriggerCharactersFinished
+	if race.State == RaceStatePickingBuilds || race.State == RaceStateVetoBuilds {
+		trigger = RaceTriggerBuildsFinished
+	}
+	if err := raceTransition(race, trigger, RaceStateInProgress); err != nil {
 		msg := "Failed to set the state for race \"" + race.Name() + "\": " + err.Error()
 		log.Error(msg)
 		discordSend(race.ChannelID, msg)
@@ -219,19 +218,23 @@ func matchSetInProgressAndPrintSummary(race *Race, msg string) {
 	msg += "**Racer 1: **" + race.Racer1.Mention() + " - <" + race.Racer1.StreamURL.String + ">\n"
 	msg += "**Racer 2: **" + race.Racer2.Mention() + " - <" + race.Racer2.StreamURL.String + ">\n"
 	for _, cast := range race.Casts {
-		msg += "**" + languageMap[cast.Language] + " Caster:** " + cast.Caster.Mention() + " - <" + cast.Caster.StreamURL.String + ">\n"
+		msg += "**" + getLanguageName(race, cast.Language) + " Caster:** " + cast.Caster.Mention() + " - <" + cast.Caster.StreamURL.String + ">\n"
 	}
 	msg += "\n"
 
+	// Each round goes on one line so that the summary stays readable for large best-of formats.
+	// (If it is too long for one Discord message, it is split up by the "discordSend" function.)
+	// The rounds come from the draft itself rather than the tournament's "best_of", since that can
+	// be changed by "!reload" in the middle of a match.
 	ruleset := tournaments[race.ChallongeURL].Ruleset
-	for i := 0; i < tournaments[race.ChallongeURL].BestOf; i++ {
-		msg += "**Round " + strconv.Itoa(i+1) + "**:\n"
-		msg += "- Character: *" + race.Characters[i] + "*\n"
-		if ruleset == "seeded" {
-			msg += "- Build: *" + race.Builds[i] + "*\n"
+	for i := 0; i < len(race.Characters); i++ {
+		msg += "**Round " + strconv.Itoa(i+1) + "**: *" + race.Characters[i] + "*"
+		if ruleset == "seeded" && i < len(race.Builds) {
+			msg += " / *" + race.Builds[i] + "*"
 		}
 		msg += "\n"
 	}
+	msg += "\n"
 	msg += "If I made a mistake, you can use `!randchar` "
 	if ruleset == "seeded" {
 		msg += "or `!randbuild` "
@@ -241,8 +244,9 @@ func matchSetInProgressAndPrintSummary(race *Race, msg string) {
 		msg += " and builds"
 	}
 	msg += ".\n"
-	msg += "When the race is over, please use the `!score [score]` command to report the results.\n"
-	msg += "e.g. `!score 3-2`\n\n"
+	msg += "After each game, please use the `!game` command to report the result.\n"
+	msg += "e.g. `!game 1 win 12:34` (the winning time is optional)\n"
+	msg += "Once someone has won enough games, the score of the match will be reported automatically.\n\n"
 	msg += "Good luck and have fun!"
 	discordSend(race.ChannelID, msg)
 }
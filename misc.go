@@ -2,11 +2,41 @@ package main
 
 import (
 	"math/rand"
+	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+var (
+	// All of the randomness for the bot comes from a single source so that a draft can be replayed
+	// from a known seed. (The "RANDOM_SEED" environment variable can be used to set it.)
+	randomSource = rand.New(rand.NewSource(time.Now().UnixNano()))
+	randomMutex  = new(sync.Mutex)
+)
+
+func randomInit() {
+	seedString := os.Getenv("RANDOM_SEED")
+	if len(seedString) == 0 {
+		return
+	}
+
+	if v, err := strconv.ParseInt(seedString, 10, 64); err != nil {
+		log.Fatal("The \"RANDOM_SEED\" environment variable is not a number.")
+		return
+	} else {
+		setRandomSeed(v)
+	}
+}
+
+func setRandomSeed(seed int64) {
+	randomMutex.Lock()
+	defer randomMutex.Unlock()
+
+	randomSource = rand.New(rand.NewSource(seed))
+}
+
 func deleteFromSlice(a []string, i int) []string {
 	return append(a[:i], a[i+1:]...)
 }
@@ -22,17 +52,20 @@ func getRandomArrayElement[T any](array []T) (T, int) {
 		log.Panic("Failed to get a random array element since the provided array was empty.")
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	index := rand.Intn(len(array))
+	randomMutex.Lock()
+	index := randomSource.Intn(len(array))
+	randomMutex.Unlock()
 	return array[index], index
 }
 
 // Returns a random integer between min and max. It is inclusive on both ends. For example,
 // `getRandomInt(1, 3)` will return 1, 2, or 3.
 func getRandomInt(min int, max int) int {
-	rand.Seed(time.Now().UnixNano())
+	randomMutex.Lock()
+	defer randomMutex.Unlock()
+
 	max++
-	return rand.Intn(max-min) + min
+	return randomSource.Intn(max-min) + min
 }
 
 func sliceToString(slice []string) string {
@@ -49,6 +82,16 @@ func stringInSlice(a string, list []string) bool {
 	return false
 }
 
+func floatInSlice(a float64, list []float64) bool {
+	for _, b := range list {
+		if b == a {
+			return true
+		}
+	}
+
+	return false
+}
+
 func stringToSlice(str string) []string {
 	// "strings.Split" will return a slice of length one if fed an empty string.
 	if str == "" {
@@ -57,3 +100,33 @@ func stringToSlice(str string) []string {
 
 	return strings.Split(str, ",")
 }
+
+// Get a table in a code block so that the columns line up. The first row is the header.
+func getTableMsg(table [][]string) string {
+	widths := make([]int, 0)
+	for _, row := range table {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+
+	msg := "```\n"
+	for _, row := range table {
+		line := ""
+		for i, cell := range row {
+			line += cell + strings.Repeat(" ", widths[i]-len(cell))
+			if i < len(row)-1 {
+				line += "  "
+			}
+		}
+		msg += strings.TrimRight(line, " ") + "\n"
+	}
+	msg += "```\n"
+
+	return msg
+}
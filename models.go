@@ -18,6 +18,12 @@ type Models struct {
 	Races
 	Users
 	Casts
+	RaceEvents
+	RaceGames
+	Timers
+	Stats
+	Pairings
+	RaceMessages
 }
 
 // Init opens a database connection based on the credentials in the ".env" file.
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"database/sql"
+)
+
+// Pairings are the matches of the tournaments that are paired by the bot itself. (See
+// "pairing.go".)
+type Pairings struct{}
+
+type Pairing struct {
+	ID           int
+	ChallongeURL string
+	Round        int     // Starting at 1.
+	Player1ID    float64 // The "participant" ID.
+	Player1Name  string
+	Player2ID    float64 // The "participant" ID, or 0 if player 1 has a bye.
+	Player2Name  string
+	Score        sql.NullString // e.g. "3-2", with player 1's wins first.
+	Winner       int            // 1 or 2 (or 0 if the match has not been played yet)
+}
+
+// Insert every pairing of a round at once, so that a round is never left half paired.
+func (*Pairings) Insert(pairings []*Pairing) error {
+	var tx *sql.Tx
+	if v, err := db.Begin(); err != nil {
+		return err
+	} else {
+		tx = v
+	}
+
+	for _, pairing := range pairings {
+		var result sql.Result
+		if v, err := tx.Exec(`
+			INSERT INTO tournament_pairings (
+				challonge_url,
+				round,
+				player1_id,
+				player1_name,
+				player2_id,
+				player2_name,
+				score,
+				winner
+			) VALUES (
+				?,
+				?,
+				?,
+				?,
+				?,
+				?,
+				?,
+				?
+			)
+		`,
+			pairing.ChallongeURL,
+			pairing.Round,
+			pairing.Player1ID,
+			pairing.Player1Name,
+			pairing.Player2ID,
+			pairing.Player2Name,
+			pairing.Score,
+			pairing.Winner,
+		); err != nil {
+			tx.Rollback()
+			return err
+		} else {
+			result = v
+		}
+
+		if id, err := result.LastInsertId(); err != nil {
+			tx.Rollback()
+			return err
+		} else {
+			pairing.ID = int(id)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetAll returns every pairing of a tournament, from the first round to the last.
+func (*Pairings) GetAll(challongeURL string) ([]*Pairing, error) {
+	var rows *sql.Rows
+	if v, err := db.Query(`
+		SELECT
+			id,
+			challonge_url,
+			round,
+			player1_id,
+			player1_name,
+			player2_id,
+			player2_name,
+			score,
+			winner
+		FROM tournament_pairings
+		WHERE challonge_url = ?
+		ORDER BY round ASC, id ASC
+	`, challongeURL); err != nil {
+		return nil, err
+	} else {
+		rows = v
+	}
+	defer rows.Close()
+
+	pairings := make([]*Pairing, 0)
+	for rows.Next() {
+		var pairing Pairing
+		if err := rows.Scan(
+			&pairing.ID,
+			&pairing.ChallongeURL,
+			&pairing.Round,
+			&pairing.Player1ID,
+			&pairing.Player1Name,
+			&pairing.Player2ID,
+			&pairing.Player2Name,
+			&pairing.Score,
+			&pairing.Winner,
+		); err != nil {
+			return nil, err
+		}
+		pairings = append(pairings, &pairing)
+	}
+
+	return pairings, nil
+}
+
+func (*Pairings) SetResult(id int, score string, winner int) error {
+	var stmt *sql.Stmt
+	if v, err := db.Prepare(`
+		UPDATE tournament_pairings
+		SET score = ?, winner = ?
+		WHERE id = ?
+	`); err != nil {
+		return err
+	} else {
+		stmt = v
+	}
+	defer stmt.Close()
+
+	_, err := stmt.Exec(score, winner, id)
+	return err
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"database/sql"
+	"time"
+)
+
+type RaceEvents struct{}
+
+type RaceEvent struct {
+	ID              int
+	Type            RaceEventType
+	ActorName       sql.NullString // The username of the person who did it; null if the bot did it.
+	RacerNum        int            // The racer that the event happened on behalf of, or 0 if none.
+	Value           string         // e.g. the character that was banned
+	DraftType       string         // "character" or "build" for a ban, pick, or veto.
+	Snapshot        sql.NullString // The JSON of the "RaceDraftSnapshot" before a ban, pick, or veto.
+	CauseEventID    sql.NullInt64  // The force command or timeout that caused it, if any.
+	Undone          bool           // Whether or not it was reverted with the "!undo" command.
+	DatetimeCreated time.Time
+}
+
+// Insert returns the database ID of the new event.
+func (*RaceEvents) Insert(channelID string, actorDiscordID string, event *RaceEvent) (int, error) {
+	var stmt *sql.Stmt
+	if v, err := db.Prepare(`
+		INSERT INTO tournament_race_events (
+			race_id,
+			event_type,
+			actor,
+			racer_num,
+			value,
+			draft_type,
+			snapshot,
+			cause_event_id
+		) VALUES (
+			(SELECT id FROM tournament_races WHERE channel_id = ?),
+			?,
+			(SELECT id FROM tournament_users WHERE discord_id = ?),
+			?,
+			?,
+			?,
+			?,
+			?
+		)
+	`); err != nil {
+		return 0, err
+	} else {
+		stmt = v
+	}
+	defer stmt.Close()
+
+	var result sql.Result
+	if v, err := stmt.Exec(
+		channelID,
+		event.Type,
+		actorDiscordID,
+		event.RacerNum,
+		event.Value,
+		event.DraftType,
+		event.Snapshot,
+		event.CauseEventID,
+	); err != nil {
+		return 0, err
+	} else {
+		result = v
+	}
+
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+func (*RaceEvents) GetAll(channelID string) ([]*RaceEvent, error) {
+	var rows *sql.Rows
+	if v, err := db.Query(`
+		SELECT
+			tournament_race_events.id,
+			tournament_race_events.event_type,
+			tournament_users.username,
+			tournament_race_events.racer_num,
+			tournament_race_events.value,
+			tournament_race_events.undone,
+			tournament_race_events.datetime_created
+		FROM tournament_race_events
+			LEFT JOIN tournament_users ON tournament_users.id = tournament_race_events.actor
+		WHERE tournament_race_events.race_id = (SELECT id FROM tournament_races WHERE channel_id = ?)
+		ORDER BY tournament_race_events.id ASC
+	`, channelID); err != nil {
+		return nil, err
+	} else {
+		rows = v
+	}
+	defer rows.Close()
+
+	events := make([]*RaceEvent, 0)
+	for rows.Next() {
+		var event RaceEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.Type,
+			&event.ActorName,
+			&event.RacerNum,
+			&event.Value,
+			&event.Undone,
+			&event.DatetimeCreated,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+
+	return events, nil
+}
+
+// GetLastDraft returns the most recent ban, pick, or veto that has not already been undone.
+func (*RaceEvents) GetLastDraft(channelID string) (*RaceEvent, error) {
+	var event RaceEvent
+	err := db.QueryRow(`
+		SELECT
+			tournament_race_events.id,
+			tournament_race_events.event_type,
+			tournament_users.username,
+			tournament_race_events.racer_num,
+			tournament_race_events.value,
+			tournament_race_events.snapshot,
+			tournament_race_events.datetime_created
+		FROM tournament_race_events
+			LEFT JOIN tournament_users ON tournament_users.id = tournament_race_events.actor
+		WHERE tournament_race_events.race_id = (SELECT id FROM tournament_races WHERE channel_id = ?)
+			AND tournament_race_events.snapshot IS NOT NULL
+			AND tournament_race_events.undone = 0
+		ORDER BY tournament_race_events.id DESC
+		LIMIT 1
+	`, channelID).Scan(
+		&event.ID,
+		&event.Type,
+		&event.ActorName,
+		&event.RacerNum,
+		&event.Value,
+		&event.Snapshot,
+		&event.DatetimeCreated,
+	)
+	return &event, err
+}
+
+// Undo puts the draft back to the way it was before the event and marks the event (and the force
+// or auto event that caused it, if any) as undone. This happens in a single transaction, and only
+// if the race is still in the state that we think it is in.
+func (*RaceEvents) Undo(channelID string, eventID int, from RaceState, snapshot *RaceDraftSnapshot) error {
+	var tx *sql.Tx
+	if v, err := db.Begin(); err != nil {
+		return err
+	} else {
+		tx = v
+	}
+
+	if err := racesSetDraft(tx, channelID, from, snapshot); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE tournament_race_events
+		SET undone = 1
+		WHERE id = ? AND undone = 0
+	`, eventID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// If the action was made by an admin or by the bot on behalf of the racer, the event that
+	// caused it is undone along with it.
+	var causeEventID sql.NullInt64
+	if err := tx.QueryRow(`
+		SELECT cause_event_id
+		FROM tournament_race_events
+		WHERE id = ?
+	`, eventID).Scan(&causeEventID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if causeEventID.Valid {
+		if _, err := tx.Exec(`
+			UPDATE tournament_race_events
+			SET undone = 1
+			WHERE id = ? AND undone = 0
+		`, causeEventID.Int64); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"database/sql"
+)
+
+type RaceGames struct{}
+
+type RaceGame struct {
+	GameNum     int // Starting at 1.
+	Winner      int // 1 or 2
+	Character   string
+	Build       string        // Blank if the ruleset does not have builds.
+	WinningTime sql.NullInt64 // In seconds.
+}
+
+// Insert the result of a game. If the game was already reported, the old result is replaced.
+func (*RaceGames) Set(channelID string, reporterDiscordID string, game *RaceGame) error {
+	var stmt *sql.Stmt
+	if v, err := db.Prepare(`
+		INSERT INTO tournament_race_games (
+			race_id,
+			game_num,
+			winner,
+			character_name,
+			build_name,
+			winning_time,
+			reporter
+		) VALUES (
+			(SELECT id FROM tournament_races WHERE channel_id = ?),
+			?,
+			?,
+			?,
+			?,
+			?,
+			(SELECT id FROM tournament_users WHERE discord_id = ?)
+		)
+		ON DUPLICATE KEY UPDATE
+			winner = VALUES(winner),
+			character_name = VALUES(character_name),
+			build_name = VALUES(build_name),
+			winning_time = VALUES(winning_time),
+			reporter = VALUES(reporter),
+			datetime_created = NOW()
+	`); err != nil {
+		return err
+	} else {
+		stmt = v
+	}
+	defer stmt.Close()
+
+	_, err := stmt.Exec(
+		channelID,
+		game.GameNum,
+		game.Winner,
+		game.Character,
+		game.Build,
+		game.WinningTime,
+		reporterDiscordID,
+	)
+	return err
+}
+
+// Get the games of a race, in order.
+func (*RaceGames) GetAll(channelID string) ([]*RaceGame, error) {
+	var rows *sql.Rows
+	if v, err := db.Query(`
+		SELECT
+			game_num,
+			winner,
+			character_name,
+			build_name,
+			winning_time
+		FROM tournament_race_games
+		WHERE race_id = (SELECT id FROM tournament_races WHERE channel_id = ?)
+		ORDER BY game_num ASC
+	`, channelID); err != nil {
+		return nil, err
+	} else {
+		rows = v
+	}
+	defer rows.Close()
+
+	games := make([]*RaceGame, 0)
+	for rows.Next() {
+		var game RaceGame
+		if err := rows.Scan(
+			&game.GameNum,
+			&game.Winner,
+			&game.Character,
+			&game.Build,
+			&game.WinningTime,
+		); err != nil {
+			return nil, err
+		}
+		games = append(games, &game)
+	}
+
+	return games, nil
+}
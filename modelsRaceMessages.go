@@ -0,0 +1,64 @@
+package main
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RaceMessages are the message logs of the race channels that were archived by "!endround".
+type RaceMessages struct{}
+
+type RaceMessage struct {
+	MessageID       string
+	AuthorDiscordID string
+	AuthorUsername  string
+	Content         string
+	Attachments     string // The URLs of the attachments, one per line.
+	DatetimeSent    time.Time
+}
+
+// Insert the messages of a race channel all at once. Messages that were already saved by a previous
+// archive of the same channel are skipped.
+func (*RaceMessages) Insert(channelID string, messages []*RaceMessage) error {
+	var tx *sql.Tx
+	if v, err := db.Begin(); err != nil {
+		return err
+	} else {
+		tx = v
+	}
+
+	for _, message := range messages {
+		if _, err := tx.Exec(`
+			INSERT IGNORE INTO tournament_race_messages (
+				race_id,
+				message_id,
+				author_discord_id,
+				author_username,
+				content,
+				attachments,
+				datetime_sent
+			) VALUES (
+				(SELECT id FROM tournament_races WHERE channel_id = ?),
+				?,
+				?,
+				?,
+				?,
+				?,
+				?
+			)
+		`,
+			channelID,
+			message.MessageID,
+			message.AuthorDiscordID,
+			message.AuthorUsername,
+			message.Content,
+			message.Attachments,
+			message.DatetimeSent,
+		); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"database/sql"
-	"strconv"
 	"time"
 )
 
@@ -16,6 +15,7 @@ func (*Races) Insert(racer1DiscordID string, racer2DiscordID string, race *Race)
 	if v, err := db.Prepare(`
 		INSERT INTO tournament_races (
 			tournament_name,
+			season,
 			racer1,
 			racer1_challonge_id,
 			racer2,
@@ -25,6 +25,9 @@ func (*Races) Insert(racer1DiscordID string, racer2DiscordID string, race *Race)
 			challonge_url,
 			challonge_match_id,
 			bracket_round,
+			bracket_group_id,
+			bracket_group,
+			bracket_identifier,
 			state,
 			characters_remaining,
 			builds_remaining,
@@ -33,6 +36,7 @@ func (*Races) Insert(racer1DiscordID string, racer2DiscordID string, race *Race)
 			racer1_vetos,
 			racer2_vetos
 		) VALUES (
+			?,
 			?,
 			(SELECT id FROM tournament_users WHERE discord_id = ?),
 			?,
@@ -49,6 +53,9 @@ func (*Races) Insert(racer1DiscordID string, racer2DiscordID string, race *Race)
 			?,
 			?,
 			?,
+			?,
+			?,
+			?,
 			?
 		)
 	`); err != nil {
@@ -60,6 +67,7 @@ func (*Races) Insert(racer1DiscordID string, racer2DiscordID string, race *Race)
 
 	if _, err := stmt.Exec(
 		race.TournamentName,
+		race.Season,
 		racer1DiscordID,
 		race.Racer1ChallongeID,
 		racer2DiscordID,
@@ -69,6 +77,9 @@ func (*Races) Insert(racer1DiscordID string, racer2DiscordID string, race *Race)
 		race.ChallongeURL,
 		race.ChallongeMatchID,
 		race.BracketRound,
+		race.BracketGroupID,
+		race.BracketGroup,
+		race.BracketIdentifier,
 		race.State,
 		charactersRemaining,
 		buildsRemaining,
@@ -108,13 +119,19 @@ func (*Races) Get(channelID string) (*Race, error) {
 	if err := db.QueryRow(`
 		SELECT
 			tournament_name,
+			season,
 			racer1,
+			racer1_challonge_id,
 			racer2,
+			racer2_challonge_id,
 			channel_id,
 			channel_name,
 			challonge_url,
 			challonge_match_id,
 			bracket_round,
+			bracket_group_id,
+			bracket_group,
+			bracket_identifier,
 			state,
 			datetime_scheduled,
 			first_picker,
@@ -127,18 +144,29 @@ func (*Races) Get(channelID string) (*Race, error) {
 			racer2_bans,
 			racer1_vetos,
 			racer2_vetos,
-			num_voted
+			num_voted,
+			score,
+			winner,
+			score_reporter,
+			datetime_completed,
+			archived
 		FROM tournament_races
 		WHERE channel_id = ?
 	`, channelID).Scan(
 		&race.TournamentName,
+		&race.Season,
 		&race.Racer1ID,
+		&race.Racer1ChallongeID,
 		&race.Racer2ID,
+		&race.Racer2ChallongeID,
 		&race.ChannelID,
 		&race.ChannelName,
 		&race.ChallongeURL,
 		&race.ChallongeMatchID,
 		&race.BracketRound,
+		&race.BracketGroupID,
+		&race.BracketGroup,
+		&race.BracketIdentifier,
 		&race.State,
 		&race.DatetimeScheduled,
 		&race.FirstPicker,
@@ -152,6 +180,11 @@ func (*Races) Get(channelID string) (*Race, error) {
 		&race.Racer1Vetos,
 		&race.Racer2Vetos,
 		&race.NumVoted,
+		&race.Score,
+		&race.Winner,
+		&race.ScoreReporter,
+		&race.DatetimeCompleted,
+		&race.Archived,
 	); err != nil {
 		return &race, err
 	}
@@ -164,12 +197,63 @@ func (*Races) Get(channelID string) (*Race, error) {
 	return &race, nil
 }
 
+// RaceMatch is the bracket match that a race was created for.
+type RaceMatch struct {
+	ChannelID         string
+	ChannelName       string
+	ChallongeMatchID  string
+	Racer1ChallongeID float64
+	Racer2ChallongeID float64
+	State             RaceState
+}
+
+// GetAllMatches returns the bracket match of every race in the tournament, including the completed
+// ones. (Races that were archived before they were completed are skipped, since their match still
+// needs to be played in a new channel.)
+func (*Races) GetAllMatches(challongeURL string) ([]*RaceMatch, error) {
+	var rows *sql.Rows
+	if v, err := db.Query(`
+		SELECT
+			channel_id,
+			channel_name,
+			challonge_match_id,
+			racer1_challonge_id,
+			racer2_challonge_id,
+			state
+		FROM tournament_races
+		WHERE challonge_url = ? AND (archived = 0 OR state = "completed")
+	`, challongeURL); err != nil {
+		return nil, err
+	} else {
+		rows = v
+	}
+	defer rows.Close()
+
+	raceMatches := make([]*RaceMatch, 0)
+	for rows.Next() {
+		var raceMatch RaceMatch
+		if err := rows.Scan(
+			&raceMatch.ChannelID,
+			&raceMatch.ChannelName,
+			&raceMatch.ChallongeMatchID,
+			&raceMatch.Racer1ChallongeID,
+			&raceMatch.Racer2ChallongeID,
+			&raceMatch.State,
+		); err != nil {
+			return nil, err
+		}
+		raceMatches = append(raceMatches, &raceMatch)
+	}
+
+	return raceMatches, nil
+}
+
 func (*Races) GetAllScheduled() ([]string, error) {
 	var rows *sql.Rows
 	if v, err := db.Query(`
 		SELECT channel_id
 		FROM tournament_races
-		WHERE state = "scheduled" AND datetime_scheduled IS NOT NULL
+		WHERE state = "scheduled" AND datetime_scheduled IS NOT NULL AND archived = 0
 		ORDER BY datetime_scheduled ASC
 	`); err != nil {
 		return nil, err
@@ -200,6 +284,7 @@ func (*Races) GetNext() (string, error) {
 		WHERE
 			state = "scheduled"
 			AND datetime_scheduled > NOW()
+			AND archived = 0
 		ORDER BY datetime_scheduled ASC
 		LIMIT 1
 	`).Scan(&channelID); err != nil {
@@ -209,30 +294,77 @@ func (*Races) GetNext() (string, error) {
 	return channelID, nil
 }
 
-func (*Races) SetState(channelID string, state RaceState) error {
-	var stmt *sql.Stmt
-	if v, err := db.Prepare(`
-		UPDATE tournament_races
-		SET state = ?
-		WHERE channel_id = ?
-	`); err != nil {
-		return err
+// RaceResult is a completed race, as shown by the "!results" command.
+type RaceResult struct {
+	TournamentName    string
+	BracketRound      string
+	BracketGroup      string
+	Racer1Username    string
+	Racer2Username    string
+	Score             string // With racer 1's wins first.
+	Winner            int    // 1 or 2
+	DatetimeCompleted sql.NullTime
+}
+
+// Get every race that has a reported score, from oldest to newest. If the round or the Discord ID
+// is not blank, only the matching races are returned.
+func (*Races) GetAllCompleted(round string, discordID string) ([]*RaceResult, error) {
+	var rows *sql.Rows
+	if v, err := db.Query(`
+		SELECT
+			tournament_races.tournament_name,
+			tournament_races.bracket_round,
+			tournament_races.bracket_group,
+			racer1_user.username,
+			racer2_user.username,
+			tournament_races.score,
+			tournament_races.winner,
+			tournament_races.datetime_completed
+		FROM tournament_races
+			JOIN tournament_users AS racer1_user ON racer1_user.id = tournament_races.racer1
+			JOIN tournament_users AS racer2_user ON racer2_user.id = tournament_races.racer2
+		WHERE
+			tournament_races.state = "completed"
+			AND tournament_races.score IS NOT NULL
+			AND (? = "" OR tournament_races.bracket_round = ?)
+			AND (? = "" OR racer1_user.discord_id = ? OR racer2_user.discord_id = ?)
+		ORDER BY tournament_races.datetime_completed ASC, tournament_races.id ASC
+	`, round, round, discordID, discordID, discordID); err != nil {
+		return nil, err
 	} else {
-		stmt = v
+		rows = v
 	}
-	defer stmt.Close()
+	defer rows.Close()
 
-	_, err := stmt.Exec(state, channelID)
-	return err
+	results := make([]*RaceResult, 0)
+	for rows.Next() {
+		var result RaceResult
+		if err := rows.Scan(
+			&result.TournamentName,
+			&result.BracketRound,
+			&result.BracketGroup,
+			&result.Racer1Username,
+			&result.Racer2Username,
+			&result.Score,
+			&result.Winner,
+			&result.DatetimeCompleted,
+		); err != nil {
+			return nil, err
+		}
+		results = append(results, &result)
+	}
+
+	return results, nil
 }
 
-func (*Races) SetDatetimeScheduled(channelID string, datetimeScheduled time.Time, activeRacer int) error {
-	// activeRacer is the racer who suggested the time.
+// Set the state only if the race is still in the state that the caller read. This makes it
+// impossible for two state changes to clobber each other.
+func (*Races) SetStateFrom(channelID string, from RaceState, to RaceState) error {
 	var stmt *sql.Stmt
 	if v, err := db.Prepare(`
 		UPDATE tournament_races
-		SET datetime_scheduled = ?, active_racer = ?
-		WHERE channel_id = ?
+		SET state = ?
+		WHERE channel_id = ? AND state = ?
 	`); err != nil {
 		return err
 	} else {
@@ -240,70 +372,93 @@ func (*Races) SetDatetimeScheduled(channelID string, datetimeScheduled time.Time
 	}
 	defer stmt.Close()
 
-	_, err := stmt.Exec(datetimeScheduled, activeRacer, channelID)
-	return err
-}
-
-func (*Races) UnsetDatetimeScheduled(channelID string) error {
-	var stmt *sql.Stmt
-	if v, err := db.Prepare(`
-		UPDATE tournament_races
-		SET datetime_scheduled = NULL
-		WHERE channel_id = ?
-	`); err != nil {
+	var result sql.Result
+	if v, err := stmt.Exec(to, channelID, from); err != nil {
 		return err
 	} else {
-		stmt = v
+		result = v
 	}
-	defer stmt.Close()
 
-	_, err := stmt.Exec(channelID)
-	return err
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rowsAffected == 0 {
+		return errRaceStateChanged
+	}
+
+	return nil
 }
 
-func (*Races) SetActiveRacer(channelID string, activeRacer int) error {
-	var stmt *sql.Stmt
-	if v, err := db.Prepare(`
-		UPDATE tournament_races
-		SET active_racer = ?
-		WHERE channel_id = ?
-	`); err != nil {
+// SetDraft writes the state and every field of the draft at once (e.g. for a ban, pick, or veto),
+// only if the race is still in the state that the caller read.
+func (*Races) SetDraft(channelID string, from RaceState, draft *RaceDraftSnapshot) error {
+	var tx *sql.Tx
+	if v, err := db.Begin(); err != nil {
 		return err
 	} else {
-		stmt = v
+		tx = v
 	}
-	defer stmt.Close()
 
-	_, err := stmt.Exec(activeRacer, channelID)
-	return err
-}
+	if err := racesSetDraft(tx, channelID, from, draft); err != nil {
+		tx.Rollback()
+		return err
+	}
 
-func (*Races) SetCharactersRemaining(channelID string, characters []string) error {
-	charactersString := sliceToString(characters)
+	return tx.Commit()
+}
 
-	var stmt *sql.Stmt
-	if v, err := db.Prepare(`
+// This is shared with "RaceEvents.Undo", which has to undo the event in the same transaction.
+func racesSetDraft(tx *sql.Tx, channelID string, from RaceState, draft *RaceDraftSnapshot) error {
+	var result sql.Result
+	if v, err := tx.Exec(`
 		UPDATE tournament_races
-		SET characters_remaining = ?
-		WHERE channel_id = ?
-	`); err != nil {
+		SET
+			state = ?,
+			active_racer = ?,
+			characters_remaining = ?,
+			characters = ?,
+			builds_remaining = ?,
+			builds = ?,
+			racer1_bans = ?,
+			racer2_bans = ?,
+			racer1_vetos = ?,
+			racer2_vetos = ?,
+			num_voted = ?
+		WHERE channel_id = ? AND state = ?
+	`,
+		draft.State,
+		draft.ActiveRacer,
+		sliceToString(draft.CharactersRemaining),
+		sliceToString(draft.Characters),
+		sliceToString(draft.BuildsRemaining),
+		sliceToString(draft.Builds),
+		draft.Racer1Bans,
+		draft.Racer2Bans,
+		draft.Racer1Vetos,
+		draft.Racer2Vetos,
+		draft.NumVoted,
+		channelID,
+		from,
+	); err != nil {
 		return err
 	} else {
-		stmt = v
+		result = v
 	}
-	defer stmt.Close()
 
-	_, err := stmt.Exec(charactersString, channelID)
-	return err
-}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rowsAffected == 0 {
+		return errRaceStateChanged
+	}
 
-func (*Races) SetCharacters(channelID string, characters []string) error {
-	charactersString := sliceToString(characters)
+	return nil
+}
 
+func (*Races) SetDatetimeScheduled(channelID string, datetimeScheduled time.Time, activeRacer int) error {
+	// activeRacer is the racer who suggested the time.
 	var stmt *sql.Stmt
 	if v, err := db.Prepare(`
 		UPDATE tournament_races
-		SET characters = ?
+		SET datetime_scheduled = ?, active_racer = ?
 		WHERE channel_id = ?
 	`); err != nil {
 		return err
@@ -312,17 +467,15 @@ func (*Races) SetCharacters(channelID string, characters []string) error {
 	}
 	defer stmt.Close()
 
-	_, err := stmt.Exec(charactersString, channelID)
+	_, err := stmt.Exec(datetimeScheduled, activeRacer, channelID)
 	return err
 }
 
-func (*Races) SetBuildsRemaining(channelID string, builds []string) error {
-	buildsString := sliceToString(builds)
-
+func (*Races) UnsetDatetimeScheduled(channelID string) error {
 	var stmt *sql.Stmt
 	if v, err := db.Prepare(`
 		UPDATE tournament_races
-		SET builds_remaining = ?
+		SET datetime_scheduled = NULL
 		WHERE channel_id = ?
 	`); err != nil {
 		return err
@@ -331,17 +484,15 @@ func (*Races) SetBuildsRemaining(channelID string, builds []string) error {
 	}
 	defer stmt.Close()
 
-	_, err := stmt.Exec(buildsString, channelID)
+	_, err := stmt.Exec(channelID)
 	return err
 }
 
-func (*Races) SetBuilds(channelID string, builds []string) error {
-	buildsString := sliceToString(builds)
-
+func (*Races) SetActiveRacer(channelID string, activeRacer int) error {
 	var stmt *sql.Stmt
 	if v, err := db.Prepare(`
 		UPDATE tournament_races
-		SET builds = ?
+		SET active_racer = ?
 		WHERE channel_id = ?
 	`); err != nil {
 		return err
@@ -350,15 +501,19 @@ func (*Races) SetBuilds(channelID string, builds []string) error {
 	}
 	defer stmt.Close()
 
-	_, err := stmt.Exec(buildsString, channelID)
+	_, err := stmt.Exec(activeRacer, channelID)
 	return err
 }
 
-func (*Races) SetBans(channelID string, racerNum int, bans int) error {
+// The score should have racer 1's wins first.
+func (*Races) SetScore(channelID string, score string, winner int, reporter int) error {
 	var stmt *sql.Stmt
 	if v, err := db.Prepare(`
 		UPDATE tournament_races
-		SET racer` + strconv.Itoa(racerNum) + `_bans = ?
+		SET
+			score = ?,
+			winner = ?,
+			score_reporter = ?
 		WHERE channel_id = ?
 	`); err != nil {
 		return err
@@ -367,15 +522,19 @@ func (*Races) SetBans(channelID string, racerNum int, bans int) error {
 	}
 	defer stmt.Close()
 
-	_, err := stmt.Exec(bans, channelID)
+	_, err := stmt.Exec(score, winner, reporter, channelID)
 	return err
 }
 
-func (*Races) SetVetos(channelID string, racerNum int, vetos int) error {
+// Remove a score that was disputed.
+func (*Races) ClearScore(channelID string) error {
 	var stmt *sql.Stmt
 	if v, err := db.Prepare(`
 		UPDATE tournament_races
-		SET racer` + strconv.Itoa(racerNum) + `_vetos = ?
+		SET
+			score = NULL,
+			winner = 0,
+			score_reporter = 0
 		WHERE channel_id = ?
 	`); err != nil {
 		return err
@@ -384,15 +543,15 @@ func (*Races) SetVetos(channelID string, racerNum int, vetos int) error {
 	}
 	defer stmt.Close()
 
-	_, err := stmt.Exec(vetos, channelID)
+	_, err := stmt.Exec(channelID)
 	return err
 }
 
-func (*Races) SetNumVoted(channelID string, numVoted int) error {
+func (*Races) SetDatetimeCompleted(channelID string) error {
 	var stmt *sql.Stmt
 	if v, err := db.Prepare(`
 		UPDATE tournament_races
-		SET num_voted = ?
+		SET datetime_completed = NOW()
 		WHERE channel_id = ?
 	`); err != nil {
 		return err
@@ -401,15 +560,15 @@ func (*Races) SetNumVoted(channelID string, numVoted int) error {
 	}
 	defer stmt.Close()
 
-	_, err := stmt.Exec(numVoted, channelID)
+	_, err := stmt.Exec(channelID)
 	return err
 }
 
-func (*Races) SetScore(channelID string, score string) error {
+func (*Races) SetFirstPicker(channelID string, firstPicker int) error {
 	var stmt *sql.Stmt
 	if v, err := db.Prepare(`
 		UPDATE tournament_races
-		SET score = ?
+		SET first_picker = ?
 		WHERE channel_id = ?
 	`); err != nil {
 		return err
@@ -418,15 +577,17 @@ func (*Races) SetScore(channelID string, score string) error {
 	}
 	defer stmt.Close()
 
-	_, err := stmt.Exec(score, channelID)
+	_, err := stmt.Exec(firstPicker, channelID)
 	return err
 }
 
-func (*Races) SetFirstPicker(channelID string, firstPicker int) error {
+// SetArchived marks the race as archived so that it is no longer scheduled or counted as an open
+// match. (The row is kept so that the message log, the casts, and the result are not lost.)
+func (*Races) SetArchived(channelID string) error {
 	var stmt *sql.Stmt
 	if v, err := db.Prepare(`
 		UPDATE tournament_races
-		SET first_picker = ?
+		SET archived = 1
 		WHERE channel_id = ?
 	`); err != nil {
 		return err
@@ -435,6 +596,6 @@ func (*Races) SetFirstPicker(channelID string, firstPicker int) error {
 	}
 	defer stmt.Close()
 
-	_, err := stmt.Exec(firstPicker, channelID)
+	_, err := stmt.Exec(channelID)
 	return err
 }
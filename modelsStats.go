@@ -0,0 +1,250 @@
+package main
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// Stats is not a table of its own; it aggregates the completed races in the "tournament_races",
+// "tournament_race_events", and "tournament_race_games" tables for the "!stats" command.
+type Stats struct{}
+
+// StatsFilter limits the statistics to a racer, a tournament, and/or a season. Blank values match
+// everything.
+type StatsFilter struct {
+	DiscordID    string
+	ChallongeURL string
+	Season       string
+}
+
+// StatsDraftCount is the number of times that a character or build was banned, picked, or vetoed.
+type StatsDraftCount struct {
+	Name      string
+	EventType RaceEventType
+	Count     int
+}
+
+// StatsGameCount is the number of games that were played on a character or build.
+type StatsGameCount struct {
+	Name        string
+	Games       int
+	PickerGames int // The games where we know who picked it (i.e. the ones in ban & pick tournaments).
+	PickerWins  int // How many of those games were won by the racer who picked it.
+	RacerWins   int // How many games the racer in the filter won on it. (0 if there is no racer.)
+}
+
+// GetDraftCounts returns how many times each character or build was banned, picked, or vetoed. If
+// there is a racer in the filter, only their own bans, picks, and vetos are counted.
+func (*Stats) GetDraftCounts(draftType string, filter *StatsFilter) ([]*StatsDraftCount, error) {
+	var rows *sql.Rows
+	if v, err := db.Query(`
+		SELECT
+			tournament_race_events.value,
+			tournament_race_events.event_type,
+			COUNT(*)
+		FROM tournament_race_events
+			JOIN tournament_races ON tournament_races.id = tournament_race_events.race_id
+			JOIN tournament_users AS racer1_user ON racer1_user.id = tournament_races.racer1
+			JOIN tournament_users AS racer2_user ON racer2_user.id = tournament_races.racer2
+		WHERE
+			tournament_races.state = "completed"
+			AND tournament_race_events.undone = 0
+			AND tournament_race_events.draft_type = ?
+			AND tournament_race_events.event_type IN ("ban", "pick", "veto")
+			AND (? = ""
+				OR (tournament_race_events.racer_num = 1 AND racer1_user.discord_id = ?)
+				OR (tournament_race_events.racer_num = 2 AND racer2_user.discord_id = ?))
+			AND (? = "" OR tournament_races.challonge_url = ?)
+			AND (? = "" OR tournament_races.season = ?)
+		GROUP BY tournament_race_events.value, tournament_race_events.event_type
+	`,
+		draftType,
+		filter.DiscordID, filter.DiscordID, filter.DiscordID,
+		filter.ChallongeURL, filter.ChallongeURL,
+		filter.Season, filter.Season,
+	); err != nil {
+		return nil, err
+	} else {
+		rows = v
+	}
+	defer rows.Close()
+
+	counts := make([]*StatsDraftCount, 0)
+	for rows.Next() {
+		var count StatsDraftCount
+		if err := rows.Scan(
+			&count.Name,
+			&count.EventType,
+			&count.Count,
+		); err != nil {
+			return nil, err
+		}
+		counts = append(counts, &count)
+	}
+
+	return counts, nil
+}
+
+// GetGameCounts returns how many games were played and won on each character or build. If there is
+// a racer in the filter, only their matches are counted.
+func (*Stats) GetGameCounts(draftType string, filter *StatsFilter) ([]*StatsGameCount, error) {
+	// The column name cannot be a query parameter.
+	column := "tournament_race_games.character_name"
+	if draftType == DraftTypeBuild {
+		column = "tournament_race_games.build_name"
+	}
+
+	// The racer who picked the character or build is found from the (non-undone) pick event.
+	var rows *sql.Rows
+	if v, err := db.Query(`
+		SELECT
+			`+column+`,
+			COUNT(*),
+			COUNT(picker_event.id),
+			COALESCE(SUM(picker_event.racer_num = tournament_race_games.winner), 0),
+			COALESCE(SUM(
+				(tournament_race_games.winner = 1 AND racer1_user.discord_id = ?)
+				OR (tournament_race_games.winner = 2 AND racer2_user.discord_id = ?)
+			), 0)
+		FROM tournament_race_games
+			JOIN tournament_races ON tournament_races.id = tournament_race_games.race_id
+			JOIN tournament_users AS racer1_user ON racer1_user.id = tournament_races.racer1
+			JOIN tournament_users AS racer2_user ON racer2_user.id = tournament_races.racer2
+			LEFT JOIN tournament_race_events AS picker_event ON
+				picker_event.race_id = tournament_race_games.race_id
+				AND picker_event.event_type = "pick"
+				AND picker_event.undone = 0
+				AND picker_event.draft_type = ?
+				AND picker_event.value = `+column+`
+		WHERE
+			tournament_races.state = "completed"
+			AND `+column+` != ""
+			AND (? = "" OR racer1_user.discord_id = ? OR racer2_user.discord_id = ?)
+			AND (? = "" OR tournament_races.challonge_url = ?)
+			AND (? = "" OR tournament_races.season = ?)
+		GROUP BY `+column+`
+	`,
+		filter.DiscordID, filter.DiscordID,
+		draftType,
+		filter.DiscordID, filter.DiscordID, filter.DiscordID,
+		filter.ChallongeURL, filter.ChallongeURL,
+		filter.Season, filter.Season,
+	); err != nil {
+		return nil, err
+	} else {
+		rows = v
+	}
+	defer rows.Close()
+
+	counts := make([]*StatsGameCount, 0)
+	for rows.Next() {
+		var count StatsGameCount
+		if err := rows.Scan(
+			&count.Name,
+			&count.Games,
+			&count.PickerGames,
+			&count.PickerWins,
+			&count.RacerWins,
+		); err != nil {
+			return nil, err
+		}
+		counts = append(counts, &count)
+	}
+
+	return counts, nil
+}
+
+// GetMatchRecord returns how many completed matches the racer won and lost.
+func (*Stats) GetMatchRecord(filter *StatsFilter) (int, int, error) {
+	var wins, losses int
+	err := db.QueryRow(`
+		SELECT
+			COALESCE(SUM(
+				(tournament_races.winner = 1 AND racer1_user.discord_id = ?)
+				OR (tournament_races.winner = 2 AND racer2_user.discord_id = ?)
+			), 0),
+			COALESCE(SUM(
+				(tournament_races.winner = 2 AND racer1_user.discord_id = ?)
+				OR (tournament_races.winner = 1 AND racer2_user.discord_id = ?)
+			), 0)
+		FROM tournament_races
+			JOIN tournament_users AS racer1_user ON racer1_user.id = tournament_races.racer1
+			JOIN tournament_users AS racer2_user ON racer2_user.id = tournament_races.racer2
+		WHERE
+			tournament_races.state = "completed"
+			AND (racer1_user.discord_id = ? OR racer2_user.discord_id = ?)
+			AND (? = "" OR tournament_races.challonge_url = ?)
+			AND (? = "" OR tournament_races.season = ?)
+	`,
+		filter.DiscordID, filter.DiscordID,
+		filter.DiscordID, filter.DiscordID,
+		filter.DiscordID, filter.DiscordID,
+		filter.ChallongeURL, filter.ChallongeURL,
+		filter.Season, filter.Season,
+	).Scan(&wins, &losses)
+
+	return wins, losses, err
+}
+
+// StatsMatchRecord is the number of completed matches that a racer won and lost.
+type StatsMatchRecord struct {
+	Username string
+	Wins     int
+	Losses   int
+}
+
+// GetMatchRecords returns the record of every racer in the season. A race is part of the season if
+// it was stored with the season or if it is from one of the tournaments of the season.
+func (*Stats) GetMatchRecords(season string, challongeURLs []string) ([]*StatsMatchRecord, error) {
+	// The tournaments are matched with an "IN" clause, which needs one placeholder for each URL.
+	// (There is always at least one placeholder so that the query is valid.)
+	args := []interface{}{season}
+	placeholders := make([]string, 0)
+	for _, challongeURL := range challongeURLs {
+		args = append(args, challongeURL)
+		placeholders = append(placeholders, "?")
+	}
+	if len(placeholders) == 0 {
+		args = append(args, "")
+		placeholders = append(placeholders, "?")
+	}
+
+	var rows *sql.Rows
+	if v, err := db.Query(`
+		SELECT
+			tournament_users.username,
+			COALESCE(SUM(racers.racer_num = tournament_races.winner), 0),
+			COALESCE(SUM(racers.racer_num != tournament_races.winner), 0)
+		FROM (
+			SELECT id, racer1 AS user_id, 1 AS racer_num FROM tournament_races
+			UNION ALL
+			SELECT id, racer2 AS user_id, 2 AS racer_num FROM tournament_races
+		) AS racers
+			JOIN tournament_races ON tournament_races.id = racers.id
+			JOIN tournament_users ON tournament_users.id = racers.user_id
+		WHERE
+			tournament_races.state = "completed"
+			AND (tournament_races.season = ? OR tournament_races.challonge_url IN (`+strings.Join(placeholders, ", ")+`))
+		GROUP BY tournament_users.id, tournament_users.username
+	`, args...); err != nil {
+		return nil, err
+	} else {
+		rows = v
+	}
+	defer rows.Close()
+
+	records := make([]*StatsMatchRecord, 0)
+	for rows.Next() {
+		var record StatsMatchRecord
+		if err := rows.Scan(
+			&record.Username,
+			&record.Wins,
+			&record.Losses,
+		); err != nil {
+			return nil, err
+		}
+		records = append(records, &record)
+	}
+
+	return records, nil
+}
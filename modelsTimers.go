@@ -0,0 +1,119 @@
+package main
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Timers struct{}
+
+func (*Timers) Insert(timer *ScheduledTimer) error {
+	var stmt *sql.Stmt
+	if v, err := db.Prepare(`
+		INSERT INTO tournament_timers (
+			action,
+			channel_id,
+			payload,
+			datetime_fire
+		) VALUES (
+			?,
+			?,
+			?,
+			?
+		)
+	`); err != nil {
+		return err
+	} else {
+		stmt = v
+	}
+	defer stmt.Close()
+
+	var result sql.Result
+	if v, err := stmt.Exec(
+		timer.Action,
+		timer.ChannelID,
+		timer.Payload,
+		timer.DatetimeFire,
+	); err != nil {
+		return err
+	} else {
+		result = v
+	}
+
+	if id, err := result.LastInsertId(); err != nil {
+		return err
+	} else {
+		timer.ID = int(id)
+	}
+
+	return nil
+}
+
+func (*Timers) Delete(id int) error {
+	var stmt *sql.Stmt
+	if v, err := db.Prepare(`
+		DELETE FROM tournament_timers
+		WHERE id = ?
+	`); err != nil {
+		return err
+	} else {
+		stmt = v
+	}
+	defer stmt.Close()
+
+	_, err := stmt.Exec(id)
+	return err
+}
+
+func (*Timers) GetAll() ([]*ScheduledTimer, error) {
+	var rows *sql.Rows
+	if v, err := db.Query(`
+		SELECT
+			id,
+			action,
+			channel_id,
+			payload,
+			datetime_fire
+		FROM tournament_timers
+		ORDER BY datetime_fire ASC
+	`); err != nil {
+		return nil, err
+	} else {
+		rows = v
+	}
+	defer rows.Close()
+
+	timers := make([]*ScheduledTimer, 0)
+	for rows.Next() {
+		var timer ScheduledTimer
+		if err := rows.Scan(
+			&timer.ID,
+			&timer.Action,
+			&timer.ChannelID,
+			&timer.Payload,
+			&timer.DatetimeFire,
+		); err != nil {
+			return nil, err
+		}
+		timers = append(timers, &timer)
+	}
+
+	return timers, nil
+}
+
+func (*Timers) SetDatetimeFire(id int, datetimeFire time.Time) error {
+	var stmt *sql.Stmt
+	if v, err := db.Prepare(`
+		UPDATE tournament_timers
+		SET datetime_fire = ?
+		WHERE id = ?
+	`); err != nil {
+		return err
+	} else {
+		stmt = v
+	}
+	defer stmt.Close()
+
+	_, err := stmt.Exec(datetimeFire, id)
+	return err
+}
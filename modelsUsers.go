@@ -53,7 +53,11 @@ func (*Users) GetFromDiscordID(discordID string) (*User, error) {
 			username,
 			timezone,
 			stream_url,
-			caster_always_ok
+			caster_always_ok,
+			rating,
+			rating_deviation,
+			rating_volatility,
+			rated_matches
 		FROM tournament_users
 		WHERE discord_id = ?
 	`, discordID).Scan(
@@ -62,6 +66,10 @@ func (*Users) GetFromDiscordID(discordID string) (*User, error) {
 		&user.Timezone,
 		&user.StreamURL,
 		&user.CasterAlwaysOk,
+		&user.Rating,
+		&user.RatingDeviation,
+		&user.RatingVolatility,
+		&user.RatedMatches,
 	)
 	return &user, err
 }
@@ -74,7 +82,11 @@ func (*Users) GetFromUserID(userID int) (*User, error) {
 			username,
 			timezone,
 			stream_url,
-			caster_always_ok
+			caster_always_ok,
+			rating,
+			rating_deviation,
+			rating_volatility,
+			rated_matches
 		FROM tournament_users
 		WHERE id = ?
 	`, userID).Scan(
@@ -83,6 +95,10 @@ func (*Users) GetFromUserID(userID int) (*User, error) {
 		&user.Timezone,
 		&user.StreamURL,
 		&user.CasterAlwaysOk,
+		&user.Rating,
+		&user.RatingDeviation,
+		&user.RatingVolatility,
+		&user.RatedMatches,
 	)
 	return &user, err
 }
@@ -154,3 +170,78 @@ func (*Users) SetCasterAlwaysOk(discordID string, ok bool) error {
 	_, err := stmt.Exec(ok, discordID)
 	return err
 }
+
+// SetRatings stores the new ratings of the users in a single transaction, so that a match never
+// only counts for one of the racers.
+func (*Users) SetRatings(users []*User) error {
+	var tx *sql.Tx
+	if v, err := db.Begin(); err != nil {
+		return err
+	} else {
+		tx = v
+	}
+
+	for _, user := range users {
+		if _, err := tx.Exec(`
+			UPDATE tournament_users
+			SET
+				rating = ?,
+				rating_deviation = ?,
+				rating_volatility = ?,
+				rated_matches = ?
+			WHERE discord_id = ?
+		`,
+			user.Rating,
+			user.RatingDeviation,
+			user.RatingVolatility,
+			user.RatedMatches,
+			user.DiscordID,
+		); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetLeaderboard returns the users that have played at least one rated match, from the highest
+// rating to the lowest.
+func (*Users) GetLeaderboard() ([]*User, error) {
+	var rows *sql.Rows
+	if v, err := db.Query(`
+		SELECT
+			discord_id,
+			username,
+			rating,
+			rating_deviation,
+			rating_volatility,
+			rated_matches
+		FROM tournament_users
+		WHERE rated_matches > 0
+		ORDER BY rating DESC, username ASC
+	`); err != nil {
+		return nil, err
+	} else {
+		rows = v
+	}
+	defer rows.Close()
+
+	users := make([]*User, 0)
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(
+			&user.DiscordID,
+			&user.Username,
+			&user.Rating,
+			&user.RatingDeviation,
+			&user.RatingVolatility,
+			&user.RatedMatches,
+		); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+
+	return users, nil
+}
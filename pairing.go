@@ -0,0 +1,615 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+	Swiss and round-robin tournaments are paired by the bot itself instead of by the bracket
+	provider. The bracket provider still holds the list of participants (in the order of their
+	seeds), but the pairings and the results are kept in the "tournament_pairings" table.
+
+	- Swiss: each round is paired once every match of the previous round has a result. Racers are
+	  ranked by their points (1 for each match win or bye), then by Buchholz (the sum of the points
+	  of their opponents), then by Sonneborn-Berger (the sum of the points of the opponents that they
+	  beat), then by seed. Each racer is paired with a racer from the bottom half of their score
+	  group, without rematches if at all possible. If there is an odd number of racers, the lowest
+	  ranked racer that has not had a bye yet gets one.
+	- Round-robin: the whole schedule is made with the circle method when the first round starts,
+	  and each round is opened once every match of the previous round has a result. Byes do not give
+	  any points, since every racer gets the same number of them.
+
+	Since the next round is only paired once the current round is complete, an admin has to use
+	"!forcescore" for a match that will never be played. The pairings are only saved by
+	"!startround"; "!checkround" shows what the next round would be without fixing it.
+*/
+
+// PairingProvider implements the BracketProvider interface for the tournaments that are paired by
+// the bot itself. The tournaments and the participants come from the real bracket provider.
+type PairingProvider struct {
+	BracketProvider
+}
+
+// PairingStanding is one line of the standings of a Swiss or round-robin tournament.
+type PairingStanding struct {
+	ID              float64 // The "participant" ID.
+	Name            string
+	Seed            int
+	Active          bool // False if they are no longer a participant of the tournament.
+	Wins            int
+	Losses          int
+	Byes            int
+	Points          int
+	Buchholz        int
+	SonnebornBerger int
+	Opponents       []float64
+	Defeated        []float64
+}
+
+const (
+	// The number of partial pairings to try before allowing rematches, so that a round with a lot of
+	// racers can never take too long to pair.
+	pairingMaxSearchSteps = 100000
+)
+
+// Get the bracket provider that pairs the matches of the tournament.
+func getBracketProvider(tournament Tournament) BracketProvider {
+	if pairingIsNative(tournament) {
+		return &PairingProvider{bracketProvider}
+	}
+
+	return bracketProvider
+}
+
+func pairingIsNative(tournament Tournament) bool {
+	return tournament.Format == TournamentFormatSwiss || tournament.Format == TournamentFormatRoundRobin
+}
+
+// GetOpenMatches returns the matches of the current round that have not been played yet. Nothing
+// is paired here (see "pairingPairNextRound"), so this is safe to use for a dry run. There are no
+// open matches once every match of the current round has a result.
+func (*PairingProvider) GetOpenMatches(tournament Tournament) ([]*BracketMatch, error) {
+	var pairings []*Pairing
+	if v, err := modals.Pairings.GetAll(tournament.ChallongeURL); err != nil {
+		return nil, errors.New("Failed to get the pairings from the database: " + err.Error())
+	} else {
+		pairings = v
+	}
+
+	return pairingGetMatches(pairings, pairingGetOpenRound(pairings)), nil
+}
+
+// Pair the next round if every match of the current round has a result. The new pairings are only
+// saved if "save" is true, so that a dry run can show them without fixing the round before an admin
+// has started it. There are no new pairings if the current round is still being played or if the
+// tournament is over.
+func pairingPairNextRound(tournament Tournament, save bool) ([]*Pairing, error) {
+	var pairings []*Pairing
+	if v, err := modals.Pairings.GetAll(tournament.ChallongeURL); err != nil {
+		return nil, errors.New("Failed to get the pairings from the database: " + err.Error())
+	} else {
+		pairings = v
+	}
+	if pairingGetOpenRound(pairings) != 0 {
+		return make([]*Pairing, 0), nil
+	}
+
+	var participants []*BracketParticipant
+	if v, err := bracketProvider.GetParticipants(tournament); err != nil {
+		return nil, err
+	} else {
+		participants = v
+	}
+
+	var newPairings []*Pairing
+	if v, err := pairingGetNextPairings(tournament, participants, pairings); err != nil {
+		return nil, err
+	} else {
+		newPairings = v
+	}
+	if len(newPairings) == 0 || !save {
+		return newPairings, nil
+	}
+
+	if err := modals.Pairings.Insert(newPairings); err != nil {
+		return nil, errors.New("Failed to insert the pairings into the database: " + err.Error())
+	}
+
+	return newPairings, nil
+}
+
+// Get the matches of a round that have not been played yet. (Pairings that have not been saved yet
+// do not have a match ID.)
+func pairingGetMatches(pairings []*Pairing, round int) []*BracketMatch {
+	matches := make([]*BracketMatch, 0)
+	for _, pairing := range pairings {
+		if pairing.Round != round || pairing.Winner != 0 {
+			continue
+		}
+
+		matchID := ""
+		if pairing.ID != 0 {
+			matchID = strconv.Itoa(pairing.ID)
+		}
+		matches = append(matches, &BracketMatch{
+			ID:          matchID,
+			Round:       strconv.Itoa(pairing.Round),
+			Player1ID:   pairing.Player1ID,
+			Player1Name: pairing.Player1Name,
+			Player2ID:   pairing.Player2ID,
+			Player2Name: pairing.Player2Name,
+		})
+	}
+
+	return matches
+}
+
+// ReportScore stores the result of the match, which is what the next round is paired from.
+func (*PairingProvider) ReportScore(tournament Tournament, matchID string, score string, winnerID float64) error {
+	var pairings []*Pairing
+	if v, err := modals.Pairings.GetAll(tournament.ChallongeURL); err != nil {
+		return errors.New("Failed to get the pairings from the database: " + err.Error())
+	} else {
+		pairings = v
+	}
+
+	for _, pairing := range pairings {
+		if strconv.Itoa(pairing.ID) != matchID {
+			continue
+		}
+
+		winner := 2
+		if winnerID == pairing.Player1ID {
+			winner = 1
+		}
+
+		return modals.Pairings.SetResult(pairing.ID, pairingGetTotalScore(score), winner)
+	}
+
+	return errors.New("Failed to find match \"" + matchID + "\" in the pairings for tournament \"" + tournament.Name + "\".")
+}
+
+// GetParticipants fills in the final ranks from the standings once the last round is over.
+func (p *PairingProvider) GetParticipants(tournament Tournament) ([]*BracketParticipant, error) {
+	var participants []*BracketParticipant
+	if v, err := p.BracketProvider.GetParticipants(tournament); err != nil {
+		return nil, err
+	} else {
+		participants = v
+	}
+
+	var pairings []*Pairing
+	if v, err := modals.Pairings.GetAll(tournament.ChallongeURL); err != nil {
+		return nil, errors.New("Failed to get the pairings from the database: " + err.Error())
+	} else {
+		pairings = v
+	}
+
+	if !pairingIsOver(tournament, participants, pairings) {
+		return participants, nil
+	}
+
+	rank := 0
+	for _, standing := range pairingGetStandings(tournament, participants, pairings) {
+		if !standing.Active {
+			continue
+		}
+		rank++
+		for _, participant := range participants {
+			if participant.ID == standing.ID {
+				participant.FinalRank = rank
+				break
+			}
+		}
+	}
+
+	return participants, nil
+}
+
+// SetSeeds refuses to change the seeds once the first round has been paired, since the seeds
+// decide the round-robin schedule and break the ties in the Swiss standings.
+func (p *PairingProvider) SetSeeds(tournament Tournament, participantIDs []float64) error {
+	if pairings, err := modals.Pairings.GetAll(tournament.ChallongeURL); err != nil {
+		return errors.New("Failed to get the pairings from the database: " + err.Error())
+	} else if len(pairings) > 0 {
+		return errors.New("The seeds of tournament \"" + tournament.Name + "\" cannot be changed, since it has already started.")
+	}
+
+	return p.BracketProvider.SetSeeds(tournament, participantIDs)
+}
+
+// Get the earliest round that still has a match to be played, or 0 if every match has been played.
+func pairingGetOpenRound(pairings []*Pairing) int {
+	for _, pairing := range pairings {
+		if pairing.Winner == 0 {
+			return pairing.Round
+		}
+	}
+
+	return 0
+}
+
+func pairingGetLastRound(pairings []*Pairing) int {
+	lastRound := 0
+	for _, pairing := range pairings {
+		if pairing.Round > lastRound {
+			lastRound = pairing.Round
+		}
+	}
+
+	return lastRound
+}
+
+// Get the number of rounds that the tournament will have.
+func pairingGetNumRounds(tournament Tournament, numParticipants int) int {
+	if tournament.Format == TournamentFormatRoundRobin {
+		// Every racer plays every other racer; with an odd number of racers, everyone gets a bye.
+		if numParticipants%2 == 1 {
+			return numParticipants
+		}
+		return numParticipants - 1
+	}
+
+	if tournament.NumRounds > 0 {
+		return tournament.NumRounds
+	}
+	if numParticipants < 2 {
+		return 1
+	}
+	return int(math.Ceil(math.Log2(float64(numParticipants))))
+}
+
+func pairingIsOver(tournament Tournament, participants []*BracketParticipant, pairings []*Pairing) bool {
+	if len(pairings) == 0 || pairingGetOpenRound(pairings) != 0 {
+		return false
+	}
+
+	// The whole round-robin schedule is made at once, so it is over as soon as every match has been
+	// played.
+	if tournament.Format == TournamentFormatRoundRobin {
+		return true
+	}
+
+	return pairingGetLastRound(pairings) >= pairingGetNumRounds(tournament, len(participants))
+}
+
+// Pair the next round (or, for round-robin tournaments, every round). Returns an empty slice if
+// the tournament is over.
+func pairingGetNextPairings(tournament Tournament, participants []*BracketParticipant, pairings []*Pairing) ([]*Pairing, error) {
+	if pairingIsOver(tournament, participants, pairings) {
+		return make([]*Pairing, 0), nil
+	}
+
+	if len(participants) < 2 {
+		return nil, errors.New("Tournament \"" + tournament.Name + "\" needs at least 2 participants to be paired.")
+	}
+
+	if tournament.Format == TournamentFormatRoundRobin {
+		return pairingGetRoundRobinPairings(tournament, participants), nil
+	}
+
+	return pairingGetSwissPairings(tournament, participants, pairings), nil
+}
+
+// Make the whole round-robin schedule with the circle method: the first seed stays in place and
+// everyone else rotates around them after each round.
+func pairingGetRoundRobinPairings(tournament Tournament, participants []*BracketParticipant) []*Pairing {
+	// With an odd number of racers, whoever is paired with the empty spot gets a bye.
+	circle := append([]*BracketParticipant{}, participants...)
+	if len(circle)%2 == 1 {
+		circle = append(circle, nil)
+	}
+	numRounds := pairingGetNumRounds(tournament, len(participants))
+
+	pairings := make([]*Pairing, 0)
+	for round := 1; round <= numRounds; round++ {
+		for i := 0; i < len(circle)/2; i++ {
+			player1 := circle[i]
+			player2 := circle[len(circle)-1-i]
+
+			// Alternate the first seed between racer 1 and racer 2.
+			if i == 0 && round%2 == 0 {
+				player1, player2 = player2, player1
+			}
+
+			if player1 == nil {
+				player1, player2 = player2, nil
+			}
+			pairings = append(pairings, pairingNew(tournament, round, player1, player2))
+		}
+
+		// Rotate everyone but the first seed by one spot.
+		last := circle[len(circle)-1]
+		copy(circle[2:], circle[1:len(circle)-1])
+		circle[1] = last
+	}
+
+	return pairings
+}
+
+// Pair the next Swiss round from the standings.
+func pairingGetSwissPairings(tournament Tournament, participants []*BracketParticipant, pairings []*Pairing) []*Pairing {
+	round := pairingGetLastRound(pairings) + 1
+
+	// Racers that dropped out of the tournament are not paired anymore, but they still count for
+	// the tiebreaks of their past opponents.
+	ranked := make([]*PairingStanding, 0)
+	for _, standing := range pairingGetStandings(tournament, participants, pairings) {
+		if standing.Active {
+			ranked = append(ranked, standing)
+		}
+	}
+
+	// Try to pair everyone without any rematches first.
+	var pairs [][2]*PairingStanding
+	var bye *PairingStanding
+	for _, allowRematches := range []bool{false, true} {
+		if v1, v2, ok := pairingPairSwiss(ranked, allowRematches); ok {
+			pairs = v1
+			bye = v2
+			break
+		}
+	}
+
+	newPairings := make([]*Pairing, 0)
+	for _, pair := range pairs {
+		newPairings = append(newPairings, pairingNew(
+			tournament,
+			round,
+			pairingGetParticipant(participants, pair[0]),
+			pairingGetParticipant(participants, pair[1]),
+		))
+	}
+	if bye != nil {
+		newPairings = append(newPairings, pairingNew(tournament, round, pairingGetParticipant(participants, bye), nil))
+	}
+
+	return newPairings
+}
+
+// Pair the racers, who are sorted from first place to last. If there is an odd number of racers,
+// the lowest ranked racer that has not had a bye yet gets one. Returns false if there is no way to
+// pair everyone.
+func pairingPairSwiss(ranked []*PairingStanding, allowRematches bool) ([][2]*PairingStanding, *PairingStanding, bool) {
+	if len(ranked)%2 == 0 {
+		steps := 0
+		pairs, ok := pairingPairRemaining(ranked, allowRematches, &steps)
+		return pairs, nil, ok
+	}
+
+	// If everyone has already had a bye, anyone can get another one.
+	for _, anyone := range []bool{false, true} {
+		for i := len(ranked) - 1; i >= 0; i-- {
+			if ranked[i].Byes > 0 && !anyone {
+				continue
+			}
+
+			remaining := make([]*PairingStanding, 0)
+			remaining = append(remaining, ranked[:i]...)
+			remaining = append(remaining, ranked[i+1:]...)
+			steps := 0
+			if pairs, ok := pairingPairRemaining(remaining, allowRematches, &steps); ok {
+				return pairs, ranked[i], true
+			}
+		}
+	}
+
+	return nil, nil, false
+}
+
+// Pair the highest ranked racer first and then the rest of the racers, backtracking if the rest
+// cannot be paired without a rematch.
+func pairingPairRemaining(remaining []*PairingStanding, allowRematches bool, steps *int) ([][2]*PairingStanding, bool) {
+	if len(remaining) == 0 {
+		return make([][2]*PairingStanding, 0), true
+	}
+
+	*steps++
+	if *steps > pairingMaxSearchSteps {
+		return nil, false
+	}
+
+	player := remaining[0]
+	for _, i := range pairingGetCandidates(remaining) {
+		opponent := remaining[i]
+		if !allowRematches && pairingHasPlayed(player, opponent) {
+			continue
+		}
+
+		rest := make([]*PairingStanding, 0)
+		for j, standing := range remaining {
+			if j != 0 && j != i {
+				rest = append(rest, standing)
+			}
+		}
+		if pairs, ok := pairingPairRemaining(rest, allowRematches, steps); ok {
+			return append([][2]*PairingStanding{{player, opponent}}, pairs...), true
+		}
+	}
+
+	return nil, false
+}
+
+// Get the order in which to try the opponents for the first racer. Like in chess, the top half of
+// the score group is paired with the bottom half (e.g. 1 vs 5, 2 vs 6, etc. in the first round),
+// and racers only float to another score group if they have to.
+func pairingGetCandidates(remaining []*PairingStanding) []int {
+	groupSize := 1
+	for groupSize < len(remaining) && remaining[groupSize].Points == remaining[0].Points {
+		groupSize++
+	}
+
+	half := groupSize / 2
+	if half < 1 {
+		half = 1
+	}
+
+	candidates := make([]int, 0)
+	for i := half; i < groupSize; i++ {
+		candidates = append(candidates, i)
+	}
+	for i := half - 1; i >= 1; i-- {
+		candidates = append(candidates, i)
+	}
+	for i := groupSize; i < len(remaining); i++ {
+		candidates = append(candidates, i)
+	}
+
+	return candidates
+}
+
+func pairingHasPlayed(standing1 *PairingStanding, standing2 *PairingStanding) bool {
+	for _, opponentID := range standing1.Opponents {
+		if opponentID == standing2.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Get the standings of the tournament from the played matches, from first place to last.
+func pairingGetStandings(tournament Tournament, participants []*BracketParticipant, pairings []*Pairing) []*PairingStanding {
+	standingMap := make(map[float64]*PairingStanding)
+	for _, participant := range participants {
+		standingMap[participant.ID] = &PairingStanding{
+			ID:     participant.ID,
+			Name:   participant.Name,
+			Seed:   participant.Seed,
+			Active: true,
+		}
+	}
+	getStanding := func(id float64, name string) *PairingStanding {
+		if _, ok := standingMap[id]; !ok {
+			standingMap[id] = &PairingStanding{
+				ID:   id,
+				Name: name,
+				Seed: len(participants) + 1,
+			}
+		}
+		return standingMap[id]
+	}
+
+	for _, pairing := range pairings {
+		standing1 := getStanding(pairing.Player1ID, pairing.Player1Name)
+		if pairing.Player2ID == 0 {
+			if pairing.Winner != 0 {
+				standing1.Byes++
+			}
+			continue
+		}
+		standing2 := getStanding(pairing.Player2ID, pairing.Player2Name)
+
+		// Racers that are paired count as having played each other, even before the match is over,
+		// so that they are not paired again.
+		standing1.Opponents = append(standing1.Opponents, standing2.ID)
+		standing2.Opponents = append(standing2.Opponents, standing1.ID)
+
+		if pairing.Winner == 1 {
+			standing1.Wins++
+			standing1.Defeated = append(standing1.Defeated, standing2.ID)
+			standing2.Losses++
+		} else if pairing.Winner == 2 {
+			standing2.Wins++
+			standing2.Defeated = append(standing2.Defeated, standing1.ID)
+			standing1.Losses++
+		}
+	}
+
+	for _, standing := range standingMap {
+		standing.Points = standing.Wins
+		if tournament.Format == TournamentFormatSwiss {
+			standing.Points += standing.Byes
+		}
+	}
+
+	// The tiebreaks can only be calculated once everyone has their points.
+	for _, standing := range standingMap {
+		for _, opponentID := range standing.Opponents {
+			standing.Buchholz += standingMap[opponentID].Points
+		}
+		for _, opponentID := range standing.Defeated {
+			standing.SonnebornBerger += standingMap[opponentID].Points
+		}
+	}
+
+	standings := make([]*PairingStanding, 0)
+	for _, standing := range standingMap {
+		standings = append(standings, standing)
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		if standings[i].Points != standings[j].Points {
+			return standings[i].Points > standings[j].Points
+		}
+		if standings[i].Buchholz != standings[j].Buchholz {
+			return standings[i].Buchholz > standings[j].Buchholz
+		}
+		if standings[i].SonnebornBerger != standings[j].SonnebornBerger {
+			return standings[i].SonnebornBerger > standings[j].SonnebornBerger
+		}
+		if standings[i].Seed != standings[j].Seed {
+			return standings[i].Seed < standings[j].Seed
+		}
+		return strings.ToLower(standings[i].Name) < strings.ToLower(standings[j].Name)
+	})
+
+	return standings
+}
+
+func pairingGetParticipant(participants []*BracketParticipant, standing *PairingStanding) *BracketParticipant {
+	for _, participant := range participants {
+		if participant.ID == standing.ID {
+			return participant
+		}
+	}
+
+	return nil
+}
+
+// Make a new pairing. A nil player 2 means that player 1 has a bye, which counts as already played.
+func pairingNew(tournament Tournament, round int, player1 *BracketParticipant, player2 *BracketParticipant) *Pairing {
+	pairing := &Pairing{
+		ChallongeURL: tournament.ChallongeURL,
+		Round:        round,
+		Player1ID:    player1.ID,
+		Player1Name:  player1.Name,
+	}
+	if player2 == nil {
+		pairing.Winner = 1
+	} else {
+		pairing.Player2ID = player2.ID
+		pairing.Player2Name = player2.Name
+	}
+
+	return pairing
+}
+
+// The bracket gets the result of every game (e.g. "1-0,0-1,1-0"), but the pairings only store the
+// score of the match (e.g. "2-1").
+func pairingGetTotalScore(scoresCSV string) string {
+	if !strings.Contains(scoresCSV, ",") {
+		return scoresCSV
+	}
+
+	p1Wins := 0
+	p2Wins := 0
+	for _, gameScore := range strings.Split(scoresCSV, ",") {
+		numbers := strings.Split(gameScore, "-")
+		if len(numbers) != 2 {
+			continue
+		}
+		p1Score, _ := strconv.Atoi(numbers[0])
+		p2Score, _ := strconv.Atoi(numbers[1])
+		if p1Score > p2Score {
+			p1Wins++
+		} else if p2Score > p1Score {
+			p2Wins++
+		}
+	}
+
+	return strconv.Itoa(p1Wins) + "-" + strconv.Itoa(p2Wins)
+}
@@ -6,6 +6,7 @@ import (
 
 type Race struct {
 	TournamentName      string
+	Season              string
 	Racer1ID            int     // The "tournament_users" database ID.
 	Racer1ChallongeID   float64 // The "participant" ID; needed to automatically set the winner through the Challonge API.
 	Racer1              *User
@@ -16,7 +17,10 @@ type Race struct {
 	ChannelName         string // The Discord channel name that was automatically created for this race.
 	ChallongeURL        string // The suffix of the Challonge URL for this tournament.
 	ChallongeMatchID    string
-	BracketRound        string
+	BracketRound        string  // Negative for the losers bracket of a double elimination tournament.
+	BracketGroupID      float64 // 0 if the match is not in a group stage.
+	BracketGroup        string  // The letter of the group, e.g. "A".
+	BracketIdentifier   string  // The letter(s) of the match on the bracket, e.g. "A" or "AB".
 	State               RaceState
 	DatetimeScheduled   sql.NullTime
 	FirstPicker         int
@@ -30,6 +34,11 @@ type Race struct {
 	Racer1Vetos         int
 	Racer2Vetos         int
 	NumVoted            int
+	Score               sql.NullString // e.g. "3-2", with racer 1's wins first.
+	Winner              int            // 1 or 2 (or 0 if the score has not been reported yet).
+	ScoreReporter       int            // 1 or 2 (or 0 if the score was forced by an admin).
+	DatetimeCompleted   sql.NullTime
+	Archived            bool // True once the channel has been moved to the archive category.
 	Casts               []*Cast
 }
 
@@ -41,6 +50,11 @@ func (race *Race) Name() string {
 	return race.Racer1.Username + "-vs-" + race.Racer2.Username
 }
 
+// Get the part of the bracket that the race is in, e.g. "Group A", "Losers Round 2", or "Round 3".
+func (race *Race) Stage() string {
+	return bracketGetStage(race.BracketRound, race.BracketGroup)
+}
+
 // Get this race from the database.
 func getRace(channelID string) (*Race, error) {
 	var race *Race
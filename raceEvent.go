@@ -0,0 +1,232 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	DraftTypeCharacter = "character"
+	DraftTypeBuild     = "build"
+)
+
+// RaceDraftSnapshot is the part of a race that changes during the draft. A copy is stored with
+// every ban, pick, and veto so that the "!undo" command can put the race back the way it was.
+type RaceDraftSnapshot struct {
+	State               RaceState `json:"state"`
+	ActiveRacer         int       `json:"active_racer"`
+	CharactersRemaining []string  `json:"characters_remaining"`
+	Characters          []string  `json:"characters"`
+	BuildsRemaining     []string  `json:"builds_remaining"`
+	Builds              []string  `json:"builds"`
+	Racer1Bans          int       `json:"racer1_bans"`
+	Racer2Bans          int       `json:"racer2_bans"`
+	Racer1Vetos         int       `json:"racer1_vetos"`
+	Racer2Vetos         int       `json:"racer2_vetos"`
+	NumVoted            int       `json:"num_voted"`
+}
+
+// This must be called before the race is modified.
+func raceGetDraftSnapshot(race *Race) *RaceDraftSnapshot {
+	return &RaceDraftSnapshot{
+		State:               race.State,
+		ActiveRacer:         race.ActiveRacer,
+		CharactersRemaining: append([]string{}, race.CharactersRemaining...),
+		Characters:          append([]string{}, race.Characters...),
+		BuildsRemaining:     append([]string{}, race.BuildsRemaining...),
+		Builds:              append([]string{}, race.Builds...),
+		Racer1Bans:          race.Racer1Bans,
+		Racer2Bans:          race.Racer2Bans,
+		Racer1Vetos:         race.Racer1Vetos,
+		Racer2Vetos:         race.Racer2Vetos,
+		NumVoted:            race.NumVoted,
+	}
+}
+
+// Put the race back to the way that the snapshot has it.
+func raceSetDraftSnapshot(race *Race, snapshot *RaceDraftSnapshot) {
+	race.State = snapshot.State
+	race.ActiveRacer = snapshot.ActiveRacer
+	race.CharactersRemaining = append([]string{}, snapshot.CharactersRemaining...)
+	race.Characters = append([]string{}, snapshot.Characters...)
+	race.BuildsRemaining = append([]string{}, snapshot.BuildsRemaining...)
+	race.Builds = append([]string{}, snapshot.Builds...)
+	race.Racer1Bans = snapshot.Racer1Bans
+	race.Racer2Bans = snapshot.Racer2Bans
+	race.Racer1Vetos = snapshot.Racer1Vetos
+	race.Racer2Vetos = snapshot.Racer2Vetos
+	race.NumVoted = snapshot.NumVoted
+}
+
+// RaceEventCause is the force command (or the timeout) that made something happen on behalf of a
+// racer. It is recorded right before the thing that it caused (which links to it), so a force
+// command that fails does not show up in the history.
+type RaceEventCause struct {
+	Actor *discordgo.User // Nil if the bot did it.
+	Type  RaceEventType
+	Value string
+}
+
+// Record something that happened in a race so that it can be replayed later with the "!history"
+// command. The cause is nil unless it happened on behalf of the racer. Failing to write the history
+// should never stop the match from continuing, so errors are only logged.
+func raceEventRecord(race *Race, actor *discordgo.User, eventType RaceEventType, racerNum int, value string, cause *RaceEventCause) {
+	raceEventInsert(race, actor, &RaceEvent{
+		Type:     eventType,
+		RacerNum: racerNum,
+		Value:    value,
+	}, cause)
+}
+
+// Record a ban, pick, or veto along with the state of the draft before it happened, so that it can
+// be reverted with the "!undo" command.
+func raceEventRecordDraft(race *Race, actor *discordgo.User, eventType RaceEventType, racerNum int, value string, snapshot *RaceDraftSnapshot, cause *RaceEventCause) {
+	event := &RaceEvent{
+		Type:      eventType,
+		RacerNum:  racerNum,
+		Value:     value,
+		DraftType: raceEventGetDraftType(snapshot.State),
+	}
+	if v, err := json.Marshal(snapshot); err != nil {
+		log.Error("Failed to marshal the draft snapshot for race \"" + race.Name() + "\": " + err.Error())
+	} else {
+		event.Snapshot = sql.NullString{
+			String: string(v),
+			Valid:  true,
+		}
+	}
+
+	raceEventInsert(race, actor, event, cause)
+}
+
+// Get whether a ban, pick, or veto that happened in the given state was for a character or a build.
+func raceEventGetDraftType(state RaceState) string {
+	switch state {
+	case RaceStateBanningCharacters, RaceStatePickingCharacters, RaceStateVetoCharacters:
+		return DraftTypeCharacter
+	case RaceStateBanningBuilds, RaceStatePickingBuilds, RaceStateVetoBuilds:
+		return DraftTypeBuild
+	}
+
+	return ""
+}
+
+// A nil actor means that the bot did it. Returns the database ID of the event, or 0 if it could not
+// be recorded.
+func raceEventInsert(race *Race, actor *discordgo.User, event *RaceEvent, cause *RaceEventCause) int {
+	if cause != nil {
+		if causeEventID := raceEventInsert(race, cause.Actor, &RaceEvent{
+			Type:     cause.Type,
+			RacerNum: event.RacerNum,
+			Value:    cause.Value,
+		}, nil); causeEventID != 0 {
+			event.CauseEventID = sql.NullInt64{
+				Int64: int64(causeEventID),
+				Valid: true,
+			}
+		}
+	}
+
+	actorDiscordID := ""
+	if actor != nil {
+		// Admins that force an action might not be in the database yet.
+		if _, err := userGet(actor); err != nil {
+			log.Error("Failed to get the user from the database when recording event \"" + string(event.Type) + "\" for race \"" + race.Name() + "\": " + err.Error())
+			return 0
+		}
+		actorDiscordID = actor.ID
+	}
+
+	var eventID int
+	if v, err := modals.RaceEvents.Insert(race.ChannelID, actorDiscordID, event); err != nil {
+		log.Error("Failed to record event \"" + string(event.Type) + "\" for race \"" + race.Name() + "\": " + err.Error())
+		return 0
+	} else {
+		eventID = v
+	}
+
+	return eventID
+}
+
+// Get the racer number of the Discord user, or 0 if they are not in the race.
+func raceGetRacerNum(race *Race, discordID string) int {
+	if discordID == race.Racer1.DiscordID {
+		return 1
+	} else if discordID == race.Racer2.DiscordID {
+		return 2
+	}
+
+	return 0
+}
+
+// Get a line for the "!history" command, e.g. "Zamiel banned Isaac."
+func raceEventGetDescription(race *Race, event *RaceEvent) string {
+	actor := "The bot"
+	if event.ActorName.Valid {
+		actor = event.ActorName.String
+	}
+
+	racer := "racer " + strconv.Itoa(event.RacerNum)
+	if event.RacerNum == 1 {
+		racer = race.Racer1.Username
+	} else if event.RacerNum == 2 {
+		racer = race.Racer2.Username
+	}
+
+	switch event.Type {
+	case RaceEventTypeTimeProposed:
+		return actor + " proposed a time of " + event.Value + "."
+	case RaceEventTypeTimeConfirmed:
+		return actor + " confirmed the time."
+	case RaceEventTypeTimeDeleted:
+		return actor + " deleted the scheduled time."
+	case RaceEventTypeBan:
+		return actor + " banned " + event.Value + "."
+	case RaceEventTypePick:
+		return actor + " picked " + event.Value + "."
+	case RaceEventTypeVeto:
+		return actor + " vetoed " + event.Value + "."
+	case RaceEventTypeNoVeto:
+		return actor + " did not veto " + event.Value + "."
+	case RaceEventTypeForceTime,
+		RaceEventTypeForceTimeOk,
+		RaceEventTypeForceTimeDelete,
+		RaceEventTypeForceBan,
+		RaceEventTypeForcePick,
+		RaceEventTypeForceYes,
+		RaceEventTypeForceNo:
+
+		msg := actor + " used `!" + strings.ToLower(string(event.Type)) + "`"
+		if event.Value != "" {
+			msg += " with \"" + event.Value + "\""
+		}
+		msg += " on behalf of " + racer + "."
+		return msg
+	case RaceEventTypeAutoBan:
+		return racer + " ran out of time, so " + strings.ToLower(actor) + " banned choice #" + event.Value + " at random."
+	case RaceEventTypeAutoPick:
+		return racer + " ran out of time, so " + strings.ToLower(actor) + " picked choice #" + event.Value + " at random."
+	case RaceEventTypeAutoNo:
+		return racer + " ran out of time, so " + strings.ToLower(actor) + " answered `!no` for them."
+	case RaceEventTypeGameReported:
+		return actor + " reported " + event.Value + "."
+	case RaceEventTypeScoreReported:
+		return actor + " reported a score of " + event.Value + "."
+	case RaceEventTypeScoreConfirmed:
+		return actor + " confirmed the score of " + event.Value + "."
+	case RaceEventTypeScoreDisputed:
+		return actor + " disputed the score of " + event.Value + "."
+	case RaceEventTypeScoreAutoConfirmed:
+		return "No-one disputed the score of " + event.Value + " in time, so " + strings.ToLower(actor) + " confirmed it."
+	case RaceEventTypeForceScore:
+		return actor + " used `!forcescore` to set the score to " + event.Value + "."
+	case RaceEventTypeUndo:
+		return actor + " undid: " + event.Value
+	}
+
+	return actor + ": " + string(event.Type) + " " + event.Value
+}
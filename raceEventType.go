@@ -0,0 +1,43 @@
+package main
+
+type RaceEventType string
+
+// Every event is stored in the "tournament_race_events" table so that admins can see what happened
+// in a match with the "!history" command.
+const (
+	// Scheduling
+	RaceEventTypeTimeProposed  RaceEventType = "timeProposed"
+	RaceEventTypeTimeConfirmed RaceEventType = "timeConfirmed"
+	RaceEventTypeTimeDeleted   RaceEventType = "timeDeleted"
+
+	// The draft
+	RaceEventTypeBan    RaceEventType = "ban"
+	RaceEventTypePick   RaceEventType = "pick"
+	RaceEventTypeVeto   RaceEventType = "veto"   // "!yes"
+	RaceEventTypeNoVeto RaceEventType = "noVeto" // "!no"
+
+	// Admins acting on behalf of a racer
+	// (the action itself is recorded separately, right after the force event)
+	RaceEventTypeForceTime       RaceEventType = "forceTime"
+	RaceEventTypeForceTimeOk     RaceEventType = "forceTimeOk"
+	RaceEventTypeForceTimeDelete RaceEventType = "forceTimeDelete"
+	RaceEventTypeForceBan        RaceEventType = "forceBan"
+	RaceEventTypeForcePick       RaceEventType = "forcePick"
+	RaceEventTypeForceYes        RaceEventType = "forceYes"
+	RaceEventTypeForceNo         RaceEventType = "forceNo"
+	RaceEventTypeUndo            RaceEventType = "undo"
+
+	// The bot acting on behalf of a racer whose turn timed out
+	// (the action itself is recorded separately, right after the auto event)
+	RaceEventTypeAutoBan  RaceEventType = "autoBan"
+	RaceEventTypeAutoPick RaceEventType = "autoPick"
+	RaceEventTypeAutoNo   RaceEventType = "autoNo"
+
+	// After the match
+	RaceEventTypeGameReported       RaceEventType = "gameReported"
+	RaceEventTypeScoreReported      RaceEventType = "scoreReported"
+	RaceEventTypeScoreConfirmed     RaceEventType = "scoreConfirmed"
+	RaceEventTypeScoreDisputed      RaceEventType = "scoreDisputed"
+	RaceEventTypeScoreAutoConfirmed RaceEventType = "scoreAutoConfirmed"
+	RaceEventTypeForceScore         RaceEventType = "forceScore"
+)
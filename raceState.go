@@ -22,6 +22,9 @@ const (
 	// Triggered once the pre-game selections have completed.
 	RaceStateInProgress RaceState = "inProgress"
 
-	// After a score is reported.
+	// After a racer reports a score but before their opponent confirms it.
+	RaceStateScorePending RaceState = "scorePending"
+
+	// After a score is confirmed (or forced by an admin) and submitted to the bracket.
 	RaceStateCompleted RaceState = "completed"
 )
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+)
+
+// RaceTrigger is the event that causes a race to move from one state to another (or to stay in
+// the same state, in the case of a ban, pick, or veto).
+type RaceTrigger string
+
+const (
+	RaceTriggerTimeProposed  RaceTrigger = "timeProposed"
+	RaceTriggerTimeConfirmed RaceTrigger = "timeConfirmed"
+	RaceTriggerTimeDeleted   RaceTrigger = "timeDeleted"
+
+	// Triggered 5 minutes before starting.
+	RaceTriggerMatchStart RaceTrigger = "matchStart"
+
+	RaceTriggerBan                RaceTrigger = "ban"
+	RaceTriggerPick               RaceTrigger = "pick"
+	RaceTriggerVeto               RaceTrigger = "veto" // Both "!yes" and "!no".
+	RaceTriggerBansFinished       RaceTrigger = "bansFinished"
+	RaceTriggerCharactersFinished RaceTrigger = "charactersFinished"
+	RaceTriggerBuildsFinished     RaceTrigger = "buildsFinished"
+
+	RaceTriggerGameReported   RaceTrigger = "gameReported"
+	RaceTriggerScoreReported  RaceTrigger = "scoreReported"
+	RaceTriggerScoreConfirmed RaceTrigger = "scoreConfirmed" // By the opponent or automatically.
+	RaceTriggerScoreDisputed  RaceTrigger = "scoreDisputed"
+	RaceTriggerScoreForced    RaceTrigger = "scoreForced"
+
+	// An admin reverted the last ban, pick, or veto.
+	RaceTriggerUndo RaceTrigger = "undo"
+)
+
+type RaceTransition struct {
+	From    RaceState
+	To      RaceState
+	Trigger RaceTrigger
+}
+
+// The only legal edges between race states. Everything else is rejected by "raceTransition".
+var raceTransitions = []RaceTransition{
+	// Scheduling
+	{RaceStateInitial, RaceStateInitial, RaceTriggerTimeProposed},
+	{RaceStateInitial, RaceStateScheduled, RaceTriggerTimeConfirmed},
+	{RaceStateScheduled, RaceStateInitial, RaceTriggerTimeDeleted},
+
+	// The start of the match
+	// (which one depends on the tournament type)
+	{RaceStateScheduled, RaceStateBanningCharacters, RaceTriggerMatchStart},
+	{RaceStateScheduled, RaceStateVetoCharacters, RaceTriggerMatchStart},
+
+	// Characters
+	{RaceStateBanningCharacters, RaceStateBanningCharacters, RaceTriggerBan},
+	{RaceStateBanningCharacters, RaceStatePickingCharacters, RaceTriggerBansFinished},
+	{RaceStatePickingCharacters, RaceStatePickingCharacters, RaceTriggerPick},
+	{RaceStateVetoCharacters, RaceStateVetoCharacters, RaceTriggerVeto},
+
+	// The end of the characters
+	// (which one depends on the ruleset and the tournament type)
+	{RaceStatePickingCharacters, RaceStateBanningBuilds, RaceTriggerCharactersFinished},
+	{RaceStatePickingCharacters, RaceStateVetoBuilds, RaceTriggerCharactersFinished},
+	{RaceStatePickingCharacters, RaceStateInProgress, RaceTriggerCharactersFinished},
+	{RaceStateVetoCharacters, RaceStateBanningBuilds, RaceTriggerCharactersFinished},
+	{RaceStateVetoCharacters, RaceStateVetoBuilds, RaceTriggerCharactersFinished},
+	{RaceStateVetoCharacters, RaceStateInProgress, RaceTriggerCharactersFinished},
+
+	// Builds
+	{RaceStateBanningBuilds, RaceStateBanningBuilds, RaceTriggerBan},
+	{RaceStateBanningBuilds, RaceStatePickingBuilds, RaceTriggerBansFinished},
+	{RaceStatePickingBuilds, RaceStatePickingBuilds, RaceTriggerPick},
+	{RaceStateVetoBuilds, RaceStateVetoBuilds, RaceTriggerVeto},
+	{RaceStatePickingBuilds, RaceStateInProgress, RaceTriggerBuildsFinished},
+	{RaceStateVetoBuilds, RaceStateInProgress, RaceTriggerBuildsFinished},
+
+	// Reporting
+	{RaceStateInProgress, RaceStateInProgress, RaceTriggerGameReported},
+	{RaceStateInProgress, RaceStateScorePending, RaceTriggerScoreReported},
+	{RaceStateScorePending, RaceStateCompleted, RaceTriggerScoreConfirmed},
+	{RaceStateScorePending, RaceStateInProgress, RaceTriggerScoreDisputed},
+	{RaceStateInProgress, RaceStateCompleted, RaceTriggerScoreForced},
+	{RaceStateScorePending, RaceStateCompleted, RaceTriggerScoreForced},
+
+	// Undoing the last action of the draft
+	// (which can go back to the previous phase if the action ended it)
+	{RaceStateBanningCharacters, RaceStateBanningCharacters, RaceTriggerUndo},
+	{RaceStatePickingCharacters, RaceStatePickingCharacters, RaceTriggerUndo},
+	{RaceStatePickingCharacters, RaceStateBanningCharacters, RaceTriggerUndo},
+	{RaceStateVetoCharacters, RaceStateVetoCharacters, RaceTriggerUndo},
+	{RaceStateBanningBuilds, RaceStateBanningBuilds, RaceTriggerUndo},
+	{RaceStateBanningBuilds, RaceStatePickingCharacters, RaceTriggerUndo},
+	{RaceStateBanningBuilds, RaceStateVetoCharacters, RaceTriggerUndo},
+	{RaceStatePickingBuilds, RaceStatePickingBuilds, RaceTriggerUndo},
+	{RaceStatePickingBuilds, RaceStateBanningBuilds, RaceTriggerUndo},
+	{RaceStateVetoBuilds, RaceStateVetoBuilds, RaceTriggerUndo},
+	{RaceStateVetoBuilds, RaceStatePickingCharacters, RaceTriggerUndo},
+	{RaceStateVetoBuilds, RaceStateVetoCharacters, RaceTriggerUndo},
+	{RaceStateInProgress, RaceStatePickingCharacters, RaceTriggerUndo},
+	{RaceStateInProgress, RaceStateVetoCharacters, RaceTriggerUndo},
+	{RaceStateInProgress, RaceStatePickingBuilds, RaceTriggerUndo},
+	{RaceStateInProgress, RaceStateVetoBuilds, RaceTriggerUndo},
+}
+
+// RaceTransitionError is returned when something tries to move a race along an edge that is not
+// in the "raceTransitions" table.
+type RaceTransitionError struct {
+	RaceName string
+	From     RaceState
+	To       RaceState
+	Trigger  RaceTrigger
+}
+
+func (e *RaceTransitionError) Error() string {
+	return "Race \"" + e.RaceName + "\" cannot go from state \"" + string(e.From) + "\" to state \"" + string(e.To) + "\" with trigger \"" + string(e.Trigger) + "\"."
+}
+
+// errRaceStateChanged is returned when the state in the database was changed by something else in
+// between reading the race and writing the new state.
+var errRaceStateChanged = errors.New("the state of the race was changed by something else; please try again")
+
+// Check to see if the trigger is allowed to happen in the race's current state.
+func raceCanTrigger(race *Race, trigger RaceTrigger) bool {
+	for _, transition := range raceTransitions {
+		if transition.From == race.State && transition.Trigger == trigger {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check to see if the race is allowed to go from its current state to the new state with the
+// trigger.
+func raceCanTransition(race *Race, trigger RaceTrigger, to RaceState) bool {
+	for _, transition := range raceTransitions {
+		if transition.From == race.State && transition.To == to && transition.Trigger == trigger {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Move the race to a new state. The new state is written to the database only if the race is still
+// in the state that we think it is in.
+func raceTransition(race *Race, trigger RaceTrigger, to RaceState) error {
+	return raceTransitionWrite(race, trigger, to, func(from RaceState) error {
+		if from == to {
+			return nil
+		}
+		return modals.Races.SetStateFrom(race.ChannelID, from, to)
+	})
+}
+
+// Move the race through a ban, pick, or veto (which usually stays in the same state). The state and
+// the rest of the draft are written to the database in a single update, so a ban, pick, or veto
+// can never be half applied.
+func raceTransitionDraft(race *Race, trigger RaceTrigger, draft *RaceDraftSnapshot) error {
+	if err := raceTransitionWrite(race, trigger, draft.State, func(from RaceState) error {
+		return modals.Races.SetDraft(race.ChannelID, from, draft)
+	}); err != nil {
+		return err
+	}
+
+	raceSetDraftSnapshot(race, draft)
+	return nil
+}
+
+// Check the edge, write the new state with the given function, and log the transition. The write
+// function gets the state that the race is in now, so that it can refuse to overwrite a state that
+// was changed by something else.
+func raceTransitionWrite(race *Race, trigger RaceTrigger, to RaceState, write func(from RaceState) error) error {
+	from := race.State
+	if !raceCanTransition(race, trigger, to) {
+		err := &RaceTransitionError{
+			RaceName: race.Name(),
+			From:     from,
+			To:       to,
+			Trigger:  trigger,
+		}
+		log.Error(err.Error())
+		return err
+	}
+
+	if err := write(from); err != nil {
+		return err
+	}
+
+	race.State = to
+	log.Info("Race \"" + race.Name() + "\" went from state \"" + string(from) + "\" to state \"" + string(to) + "\" (" + string(trigger) + ").")
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"math"
+	"strconv"
+)
+
+/*
+	Every racer has a Glicko-2 rating that is updated after each completed match:
+	http://www.glicko.net/glicko/glicko2.pdf
+	Each match is treated as its own rating period, so a racer's deviation only goes down when they
+	play. The ratings are used for the "!rating" and "!leaderboard" commands and to seed the bracket
+	with the "!seed" command.
+*/
+
+const (
+	ratingDefault           = 1500.0
+	ratingDeviationDefault  = 350.0
+	ratingVolatilityDefault = 0.06
+
+	// The constraint on the change in volatility over time. The paper suggests a value between 0.3
+	// and 1.2; smaller values prevent the ratings from swinging wildly after an upset.
+	ratingTau = 0.5
+
+	// The conversion factor between the Glicko scale and the Glicko-2 scale.
+	ratingScale = 173.7178
+
+	ratingConvergenceTolerance = 0.000001
+)
+
+// Update the ratings of both racers after the race has been completed. Failing to update the
+// ratings should never stop the score from being submitted, so errors are only logged.
+func ratingUpdate(race *Race) {
+	if race.Winner != 1 && race.Winner != 2 {
+		return
+	}
+
+	// Re-get the racers from the database, since the race only has the ratings from when the race
+	// was loaded.
+	var racer1, racer2 *User
+	if v, err := modals.Users.GetFromDiscordID(race.Racer1.DiscordID); err != nil {
+		log.Error("Failed to get racer 1 from the database to update the ratings of race \"" + race.Name() + "\": " + err.Error())
+		return
+	} else {
+		racer1 = v
+	}
+	if v, err := modals.Users.GetFromDiscordID(race.Racer2.DiscordID); err != nil {
+		log.Error("Failed to get racer 2 from the database to update the ratings of race \"" + race.Name() + "\": " + err.Error())
+		return
+	} else {
+		racer2 = v
+	}
+
+	racer1Score := 1.0
+	if race.Winner == 2 {
+		racer1Score = 0
+	}
+
+	// Both racers are updated with the rating that their opponent had before the match.
+	newRacer1 := *racer1
+	newRacer2 := *racer2
+	ratingApply(&newRacer1, racer2, racer1Score)
+	ratingApply(&newRacer2, racer1, 1-racer1Score)
+
+	if err := modals.Users.SetRatings([]*User{&newRacer1, &newRacer2}); err != nil {
+		log.Error("Failed to set the ratings for race \"" + race.Name() + "\": " + err.Error())
+		return
+	}
+
+	log.Info("Updated the ratings for race \"" + race.Name() + "\": " +
+		racer1.Username + " " + ratingGetString(racer1.Rating) + " --> " + ratingGetString(newRacer1.Rating) + ", " +
+		racer2.Username + " " + ratingGetString(racer2.Rating) + " --> " + ratingGetString(newRacer2.Rating))
+}
+
+// Apply the result of one match to the racer's rating. The score is 1 for a win and 0 for a loss.
+func ratingApply(racer *User, opponent *User, score float64) {
+	// Step 2: convert to the Glicko-2 scale.
+	mu := (racer.Rating - ratingDefault) / ratingScale
+	phi := racer.RatingDeviation / ratingScale
+	sigma := racer.RatingVolatility
+	opponentMu := (opponent.Rating - ratingDefault) / ratingScale
+	opponentPhi := opponent.RatingDeviation / ratingScale
+
+	// Steps 3 and 4: the estimated variance and the estimated improvement.
+	g := ratingG(opponentPhi)
+	expected := 1 / (1 + math.Exp(-g*(mu-opponentMu)))
+	v := 1 / (g * g * expected * (1 - expected))
+	delta := v * g * (score - expected)
+
+	// Step 5: the new volatility.
+	newSigma := ratingGetVolatility(phi, sigma, v, delta)
+
+	// Steps 6 and 7: the new deviation and rating.
+	phiStar := math.Sqrt(phi*phi + newSigma*newSigma)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	newMu := mu + newPhi*newPhi*g*(score-expected)
+
+	// Step 8: convert back to the Glicko scale.
+	racer.Rating = newMu*ratingScale + ratingDefault
+	racer.RatingDeviation = math.Min(newPhi*ratingScale, ratingDeviationDefault)
+	racer.RatingVolatility = newSigma
+	racer.RatedMatches++
+}
+
+func ratingG(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// Find the new volatility with the Illinois algorithm, as described in step 5 of the paper.
+func ratingGetVolatility(phi float64, sigma float64, v float64, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		return ex*(delta*delta-phi*phi-v-ex)/(2*math.Pow(phi*phi+v+ex, 2)) - (x-a)/(ratingTau*ratingTau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*ratingTau) < 0 {
+			k++
+		}
+		B = a - k*ratingTau
+	}
+
+	fA := f(A)
+	fB := f(B)
+	for math.Abs(B-A) > ratingConvergenceTolerance {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A = B
+			fA = fB
+		} else {
+			fA /= 2
+		}
+		B = C
+		fB = fC
+	}
+
+	return math.Exp(A / 2)
+}
+
+// Get a rating rounded to the nearest whole number, e.g. "1623".
+func ratingGetString(rating float64) string {
+	return strconv.Itoa(int(math.Round(rating)))
+}
+
+// Get a rating with its uncertainty, e.g. "1623 (± 98)". The uncertainty is twice the deviation,
+// which is a 95% confidence interval.
+func ratingGetDescription(user *User) string {
+	return ratingGetString(user.Rating) + " (± " + ratingGetString(2*user.RatingDeviation) + ")"
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Listen for SIGHUP so that the configuration can be reloaded without restarting the bot.
+// (e.g. "kill -HUP [pid]")
+func reloadInit() {
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc, syscall.SIGHUP)
+	go func() {
+		for range sc {
+			log.Info("Received SIGHUP; reloading the configuration.")
+
+			commandMutex.Lock()
+			msg, applied, err := reload()
+			commandMutex.Unlock()
+
+			if err != nil {
+				log.Error(reloadGetErrorMsg(applied, err))
+			} else {
+				log.Info(msg)
+			}
+		}
+	}()
+}
+
+// Re-read the ".env" file and the tournament configuration, swap in the new tournaments, and make
+// sure that every scheduled match has exactly one timer. Everything is read and checked before
+// anything is swapped in, so the old configuration (and the old environment) is kept if any of it
+// is invalid. Returns whether the new configuration is being used, since it is already swapped in
+// if the timers fail to update. This must be called while holding the "commandMutex".
+func reload() (string, bool, error) {
+	// Overwrite the existing environment variables with the values in the ".env" file.
+	// (The Discord and database settings are only read on startup, so changing them still requires
+	// a restart.)
+	oldEnv := os.Environ()
+	if err := godotenv.Overload(path.Join(projectPath, ".env")); err != nil {
+		reloadRestoreEnv(oldEnv)
+		return "", false, err
+	}
+
+	var newTournaments map[string]Tournament
+	var newSeasons map[string]Season
+	if v1, v2, err := tournamentLoad(); err != nil {
+		reloadRestoreEnv(oldEnv)
+		return "", false, err
+	} else {
+		newTournaments = v1
+		newSeasons = v2
+	}
+
+	var newReminderOffsets []time.Duration
+	var newReminderDM bool
+	if v1, v2, err := reminderRead(); err != nil {
+		reloadRestoreEnv(oldEnv)
+		return "", false, err
+	} else {
+		newReminderOffsets = v1
+		newReminderDM = v2
+	}
+
+	var newArchiveCategoryID string
+	if v, err := archiveRead(); err != nil {
+		reloadRestoreEnv(oldEnv)
+		return "", false, err
+	} else {
+		newArchiveCategoryID = v
+	}
+
+	var newBracketPollInterval time.Duration
+	if v, err := bracketPollRead(); err != nil {
+		reloadRestoreEnv(oldEnv)
+		return "", false, err
+	} else {
+		newBracketPollInterval = v
+	}
+
+	tournaments = newTournaments
+	seasons = newSeasons
+	reminderOffsets = newReminderOffsets
+	reminderDM = newReminderDM
+	archiveCategoryID = newArchiveCategoryID
+	bracketPollInterval = newBracketPollInterval
+
+	if err := matchReconcileTimers(); err != nil {
+		return "", true, err
+	}
+
+	if err := seasonScheduleStandingsPosts(); err != nil {
+		return "", true, err
+	}
+
+	if err := bracketPollSchedule(); err != nil {
+		return "", true, err
+	}
+
+	tournamentNames := make([]string, 0)
+	for _, tournament := range tournaments {
+		tournamentNames = append(tournamentNames, tournament.Name)
+	}
+	sort.Strings(tournamentNames)
+
+	msg := "Reloaded the configuration with " + strconv.Itoa(len(tournamentNames)) + " tournament"
+	if len(tournamentNames) != 1 {
+		msg += "s"
+	}
+	msg += ":\n"
+	for _, name := range tournamentNames {
+		msg += "- " + name + "\n"
+	}
+
+	numTimers := 0
+	for _, timer := range schedulerGetAll() {
+		if timer.Action == TimerActionMatchStart {
+			numTimers++
+		}
+	}
+	msg += "Scheduled matches waiting to start: " + strconv.Itoa(numTimers)
+
+	return msg, true, nil
+}
+
+func reloadGetErrorMsg(applied bool, err error) string {
+	if applied {
+		return "Reloaded the configuration, but failed to update the timers for it: " + err.Error()
+	}
+
+	return "Failed to reload the configuration (the old configuration is still being used): " + err.Error()
+}
+
+// Put the environment variables back to the way that they were before the ".env" file was read.
+func reloadRestoreEnv(env []string) {
+	os.Clearenv()
+	for _, variable := range env {
+		if key, value, ok := strings.Cut(variable, "="); ok {
+			os.Setenv(key, value)
+		}
+	}
+}
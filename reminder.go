@@ -0,0 +1,216 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// How long before the scheduled time of a match that the reminders are sent. (e.g. 24 hours, 1
+	// hour, and 15 minutes) These are sorted from the earliest reminder to the latest.
+	reminderOffsets = make([]time.Duration, 0)
+
+	// Whether or not the reminders are also sent to the racers and the casters in a direct message.
+	reminderDM bool
+)
+
+func reminderInit() {
+	if v1, v2, err := reminderRead(); err != nil {
+		log.Fatal(err.Error())
+		return
+	} else {
+		reminderOffsets = v1
+		reminderDM = v2
+	}
+}
+
+// Read the reminder offsets and whether to send direct messages from the environment variables.
+func reminderRead() ([]time.Duration, bool, error) {
+	offsets := make([]time.Duration, 0)
+	if offsetsString := os.Getenv("REMINDER_OFFSETS"); offsetsString != "" {
+		for _, offsetString := range strings.Split(offsetsString, ",") {
+			offsetString = strings.TrimSpace(offsetString)
+			var offset time.Duration
+			if v, err := time.ParseDuration(offsetString); err != nil {
+				return nil, false, errors.New("The \"REMINDER_OFFSETS\" environment variable has an invalid duration of \"" + offsetString + "\": " + err.Error())
+			} else {
+				offset = v
+			}
+			if offset <= 0 {
+				return nil, false, errors.New("The \"REMINDER_OFFSETS\" environment variable has a duration of \"" + offsetString + "\", but it must be positive.")
+			}
+
+			alreadyAdded := false
+			for _, existingOffset := range offsets {
+				if existingOffset == offset {
+					alreadyAdded = true
+					break
+				}
+			}
+			if !alreadyAdded {
+				offsets = append(offsets, offset)
+			}
+		}
+	}
+	sort.Slice(offsets, func(i, j int) bool {
+		return offsets[i] > offsets[j]
+	})
+
+	dm := false
+	if dmString := os.Getenv("REMINDER_DM"); dmString != "" {
+		if v, err := strconv.ParseBool(dmString); err != nil {
+			return nil, false, errors.New("The \"REMINDER_DM\" environment variable must be \"true\" or \"false\".")
+		} else {
+			dm = v
+		}
+	}
+
+	return offsets, dm, nil
+}
+
+// Make sure that the race has exactly one reminder timer for each of the configured offsets.
+// Reminders that would have been sent in the past are skipped. (e.g. a match that was scheduled 30
+// minutes in advance will not get a reminder for 1 hour before.)
+func reminderSchedule(race *Race) error {
+	scheduled := race.DatetimeScheduled.Time.UTC().Format(time.RFC3339)
+	payloads := make([]string, 0)
+	for _, offset := range reminderOffsets {
+		payloads = append(payloads, scheduled+","+offset.String())
+	}
+
+	alreadyScheduled := make(map[string]bool)
+	for _, timer := range schedulerGetAll() {
+		if timer.Action != TimerActionReminder || timer.ChannelID != race.ChannelID {
+			continue
+		}
+		if stringInSlice(timer.Payload, payloads) && !alreadyScheduled[timer.Payload] {
+			alreadyScheduled[timer.Payload] = true
+			continue
+		}
+		if err := schedulerCancel(timer.ID); err != nil {
+			return err
+		}
+	}
+
+	for i, offset := range reminderOffsets {
+		if alreadyScheduled[payloads[i]] {
+			continue
+		}
+
+		datetimeFire := race.DatetimeScheduled.Time.Add(-offset)
+		if datetimeFire.Before(time.Now()) {
+			continue
+		}
+		if _, err := schedulerAdd(TimerActionReminder, race.ChannelID, payloads[i], datetimeFire); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func reminderTimerFired(timer *ScheduledTimer) {
+	// The payload is the scheduled time of the match and the offset, separated by a comma.
+	// (e.g. "2018-06-01T20:00:00Z,1h0m0s")
+	var origStartTime time.Time
+	var offset time.Duration
+	payload := strings.Split(timer.Payload, ",")
+	if len(payload) != 2 {
+		log.Error("Timer " + strconv.Itoa(timer.ID) + " has an invalid reminder payload of \"" + timer.Payload + "\".")
+		return
+	}
+	if v, err := time.Parse(time.RFC3339, payload[0]); err != nil {
+		log.Error("Failed to parse the scheduled time of \"" + payload[0] + "\" for timer " + strconv.Itoa(timer.ID) + ": " + err.Error())
+		return
+	} else {
+		origStartTime = v
+	}
+	if v, err := time.ParseDuration(payload[1]); err != nil {
+		log.Error("Failed to parse the offset of \"" + payload[1] + "\" for timer " + strconv.Itoa(timer.ID) + ": " + err.Error())
+		return
+	} else {
+		offset = v
+	}
+
+	// Re-get the race from the database.
+	var race *Race
+	if v, err := getRace(timer.ChannelID); err != nil {
+		log.Error("Failed to re-get the race from the database: " + err.Error())
+		return
+	} else {
+		race = v
+	}
+
+	// Check to see if the race has been rescheduled or has started already.
+	if race.State != RaceStateScheduled || !origStartTime.Equal(race.DatetimeScheduled.Time) {
+		return
+	}
+
+	reminderSend(race, offset)
+}
+
+func reminderSend(race *Race, offset time.Duration) {
+	datetime := race.DatetimeScheduled.Time
+	inString := getDurationString(offset)
+
+	// Everyone in the race channel is shown the time in their own timezone.
+	msg := "**Reminder**: this match is scheduled to start in **" + inString + "**:\n"
+	msg += "- " + race.Racer1.Mention() + " - " + getDate(datetime, race.Racer1.GetTimezone()) + "\n"
+	msg += "- " + race.Racer2.Mention() + " - " + getDate(datetime, race.Racer2.GetTimezone()) + "\n"
+	for _, cast := range race.Casts {
+		if cast.R1Permission && cast.R2Permission {
+			msg += "- " + cast.Caster.Mention() + " (" + getLanguageName(race, cast.Language) + " caster) - " + getDate(datetime, cast.Caster.GetTimezone()) + "\n"
+		}
+	}
+	discordSend(race.ChannelID, msg)
+
+	if !reminderDM {
+		return
+	}
+
+	for _, racer := range []*User{race.Racer1, race.Racer2} {
+		msg := "Reminder: your match **" + race.Name() + "** in **" + race.TournamentName + "** is scheduled to start in **" + inString + "**:\n"
+		msg += getDate(datetime, racer.GetTimezone()) + "\n"
+		msg += "Match channel: <#" + race.ChannelID + ">"
+		discordSendDM(racer.DiscordID, msg)
+	}
+
+	for _, cast := range race.Casts {
+		if !cast.R1Permission || !cast.R2Permission {
+			continue
+		}
+
+		msg := "Reminder: you are scheduled to cast the match **" + race.Name() + "** in **" + race.TournamentName + "** in " + getLanguageName(race, cast.Language) + ". It starts in **" + inString + "**:\n"
+		msg += getDate(datetime, cast.Caster.GetTimezone()) + "\n"
+		msg += "Match channel: <#" + race.ChannelID + ">"
+		discordSendDM(cast.Caster.DiscordID, msg)
+	}
+}
+
+// Get a human readable duration, e.g. "1 hour and 30 minutes".
+func getDurationString(duration time.Duration) string {
+	hours := int(duration / time.Hour)
+	minutes := int((duration % time.Hour) / time.Minute)
+
+	parts := make([]string, 0)
+	if hours > 0 {
+		parts = append(parts, strconv.Itoa(hours)+" hour"+getPlural(hours))
+	}
+	if minutes > 0 || hours == 0 {
+		parts = append(parts, strconv.Itoa(minutes)+" minute"+getPlural(minutes))
+	}
+
+	return strings.Join(parts, " and ")
+}
+
+func getPlural(amount int) string {
+	if amount == 1 {
+		return ""
+	}
+
+	return "s"
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"container/heap"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TimerAction is the name of the function that runs when a timer fires. It is stored in the
+// database, so the names should not be changed.
+type TimerAction string
+
+const (
+	TimerActionMatchStart   TimerAction = "matchStart"
+	TimerActionReminder     TimerAction = "reminder"
+	TimerActionTurnWarning  TimerAction = "turnWarning"
+	TimerActionTurnTimeout  TimerAction = "turnTimeout"
+	TimerActionScoreConfirm TimerAction = "scoreConfirm"
+
+	// Timers that are not for a race
+	TimerActionStandingsPost TimerAction = "standingsPost"
+	TimerActionBracketPoll   TimerAction = "bracketPoll"
+)
+
+// ScheduledTimer is a delayed action that is persisted in the "tournament_timers" table so that it
+// survives a restart.
+type ScheduledTimer struct {
+	ID           int
+	Action       TimerAction
+	ChannelID    string // The race channel, if any.
+	Payload      string // Extra data for the action.
+	DatetimeFire time.Time
+}
+
+var (
+	// The registered functions for each type of timer. Each function is called while holding the
+	// "commandMutex".
+	schedulerActions = make(map[TimerAction]func(*ScheduledTimer))
+
+	// All of the pending timers are kept in a heap, sorted by when they fire. Only the earliest one
+	// has a real timer running.
+	schedulerQueue = make(SchedulerQueue, 0)
+	schedulerTimer *time.Timer
+	schedulerMutex = new(sync.Mutex)
+)
+
+func schedulerInit() {
+	schedulerActions[TimerActionMatchStart] = matchStartTimerFired
+	schedulerActions[TimerActionReminder] = reminderTimerFired
+	schedulerActions[TimerActionTurnWarning] = turnWarningTimerFired
+	schedulerActions[TimerActionTurnTimeout] = turnTimeoutTimerFired
+	schedulerActions[TimerActionScoreConfirm] = scoreConfirmTimerFired
+	schedulerActions[TimerActionStandingsPost] = seasonStandingsPostTimerFired
+	schedulerActions[TimerActionBracketPoll] = bracketPollTimerFired
+
+	var timers []*ScheduledTimer
+	if v, err := modals.Timers.GetAll(); err != nil {
+		log.Fatal("Failed to get the timers from the database: " + err.Error())
+		return
+	} else {
+		timers = v
+	}
+
+	schedulerMutex.Lock()
+	defer schedulerMutex.Unlock()
+
+	for _, timer := range timers {
+		heap.Push(&schedulerQueue, timer)
+	}
+	schedulerArm()
+	log.Info("Loaded " + strconv.Itoa(len(timers)) + " timer(s) from the database.")
+}
+
+// Add a new timer. If the time is in the past, it fires right away.
+func schedulerAdd(action TimerAction, channelID string, payload string, datetimeFire time.Time) (*ScheduledTimer, error) {
+	if _, ok := schedulerActions[action]; !ok {
+		return nil, errors.New("There is no timer action called \"" + string(action) + "\".")
+	}
+
+	timer := &ScheduledTimer{
+		Action:       action,
+		ChannelID:    channelID,
+		Payload:      payload,
+		DatetimeFire: datetimeFire.UTC(),
+	}
+	if err := modals.Timers.Insert(timer); err != nil {
+		return nil, err
+	}
+
+	schedulerMutex.Lock()
+	defer schedulerMutex.Unlock()
+
+	heap.Push(&schedulerQueue, timer)
+	schedulerArm()
+
+	return timer, nil
+}
+
+// Cancel a timer so that it never fires.
+func schedulerCancel(id int) error {
+	schedulerMutex.Lock()
+	defer schedulerMutex.Unlock()
+
+	i := schedulerQueue.find(id)
+	if i == -1 {
+		return errors.New("There is no timer with an ID of " + strconv.Itoa(id) + ".")
+	}
+
+	if err := modals.Timers.Delete(id); err != nil {
+		return err
+	}
+	heap.Remove(&schedulerQueue, i)
+	schedulerArm()
+
+	return nil
+}
+
+// Cancel every timer of the specified type for a race channel. (An empty action cancels every
+// timer for the channel.)
+func schedulerCancelAll(channelID string, action TimerAction) error {
+	for _, timer := range schedulerGetAll() {
+		if timer.ChannelID != channelID || (action != "" && timer.Action != action) {
+			continue
+		}
+		if err := schedulerCancel(timer.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Change when a timer fires.
+func schedulerReschedule(id int, datetimeFire time.Time) error {
+	schedulerMutex.Lock()
+	defer schedulerMutex.Unlock()
+
+	i := schedulerQueue.find(id)
+	if i == -1 {
+		return errors.New("There is no timer with an ID of " + strconv.Itoa(id) + ".")
+	}
+
+	datetimeFire = datetimeFire.UTC()
+	if err := modals.Timers.SetDatetimeFire(id, datetimeFire); err != nil {
+		return err
+	}
+	schedulerQueue[i].DatetimeFire = datetimeFire
+	heap.Fix(&schedulerQueue, i)
+	schedulerArm()
+
+	return nil
+}
+
+// Get a copy of all of the pending timers, sorted by when they fire.
+func schedulerGetAll() []*ScheduledTimer {
+	schedulerMutex.Lock()
+	defer schedulerMutex.Unlock()
+
+	queue := make(SchedulerQueue, len(schedulerQueue))
+	for i, timer := range schedulerQueue {
+		timerCopy := *timer
+		queue[i] = &timerCopy
+	}
+
+	timers := make([]*ScheduledTimer, 0)
+	for queue.Len() > 0 {
+		timers = append(timers, heap.Pop(&queue).(*ScheduledTimer))
+	}
+
+	return timers
+}
+
+// Set the real timer to go off when the earliest timer in the queue is due. This must be called
+// while holding the "schedulerMutex".
+func schedulerArm() {
+	if schedulerTimer != nil {
+		schedulerTimer.Stop()
+		schedulerTimer = nil
+	}
+	if len(schedulerQueue) == 0 {
+		return
+	}
+
+	duration := time.Until(schedulerQueue[0].DatetimeFire)
+	if duration < 0 {
+		duration = 0
+	}
+	schedulerTimer = time.AfterFunc(duration, schedulerTick)
+}
+
+func schedulerTick() {
+	// Take every timer that is due off of the queue.
+	schedulerMutex.Lock()
+	due := make([]*ScheduledTimer, 0)
+	now := time.Now()
+	for len(schedulerQueue) > 0 && !schedulerQueue[0].DatetimeFire.After(now) {
+		due = append(due, heap.Pop(&schedulerQueue).(*ScheduledTimer))
+	}
+	schedulerArm()
+	schedulerMutex.Unlock()
+
+	for _, timer := range due {
+		// Delete it first so that a timer that causes a crash does not fire again on every restart.
+		if err := modals.Timers.Delete(timer.ID); err != nil {
+			log.Error("Failed to delete timer " + strconv.Itoa(timer.ID) + " from the database: " + err.Error())
+		}
+
+		action, ok := schedulerActions[timer.Action]
+		if !ok {
+			log.Error("Timer " + strconv.Itoa(timer.ID) + " has an unknown action of \"" + string(timer.Action) + "\".")
+			continue
+		}
+
+		log.Info("Timer " + strconv.Itoa(timer.ID) + " (" + string(timer.Action) + ") fired for channel \"" + timer.ChannelID + "\".")
+
+		// The timer runs in its own goroutine, so we have to wait for any command that is in
+		// progress.
+		commandMutex.Lock()
+		action(timer)
+		commandMutex.Unlock()
+	}
+}
+
+// SchedulerQueue implements "heap.Interface", ordered by when the timers fire.
+type SchedulerQueue []*ScheduledTimer
+
+func (q SchedulerQueue) Len() int { return len(q) }
+
+func (q SchedulerQueue) Less(i, j int) bool {
+	if q[i].DatetimeFire.Equal(q[j].DatetimeFire) {
+		return q[i].ID < q[j].ID
+	}
+	return q[i].DatetimeFire.Before(q[j].DatetimeFire)
+}
+
+func (q SchedulerQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *SchedulerQueue) Push(x interface{}) {
+	*q = append(*q, x.(*ScheduledTimer))
+}
+
+func (q *SchedulerQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	timer := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return timer
+}
+
+func (q SchedulerQueue) find(id int) int {
+	for i, timer := range q {
+		if timer.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
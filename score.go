@@ -0,0 +1,235 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Parse a score in the format of "#-#" (e.g. "3-2" or "10-8"). Neither number can be more than the
+// number of wins that are needed to win the match, and there cannot be a tie.
+func scoreParse(race *Race, score string) (int, int, bool) {
+	numbers := strings.Split(score, "-")
+	if len(numbers) != 2 {
+		return 0, 0, false
+	}
+	minWinNum := 0
+	maxWinNum := tournaments[race.ChallongeURL].BestOf/2 + 1
+
+	wins := make([]int, 0)
+	for _, number := range numbers {
+		if v, err := strconv.Atoi(number); err != nil {
+			return 0, 0, false
+		} else if v < minWinNum || v > maxWinNum {
+			return 0, 0, false
+		} else {
+			wins = append(wins, v)
+		}
+	}
+
+	if wins[0] == wins[1] {
+		return 0, 0, false
+	}
+
+	return wins[0], wins[1], true
+}
+
+// Store a score on the race (with racer 1's wins first). The reporter is the racer number of the
+// person who reported it, or 0 if it was forced by an admin.
+func scoreSet(race *Race, p1Wins int, p2Wins int, reporter int) error {
+	winner := 1
+	if p2Wins > p1Wins {
+		winner = 2
+	}
+	score := strconv.Itoa(p1Wins) + "-" + strconv.Itoa(p2Wins)
+
+	if err := modals.Races.SetScore(race.ChannelID, score, winner, reporter); err != nil {
+		return err
+	}
+
+	race.Score = sql.NullString{
+		String: score,
+		Valid:  true,
+	}
+	race.Winner = winner
+	race.ScoreReporter = reporter
+
+	return nil
+}
+
+// Submit the score that is stored on the race to the bracket and mark the race as completed.
+func scoreSubmit(race *Race, trigger RaceTrigger) error {
+	if !race.Score.Valid {
+		return errors.New("Race \"" + race.Name() + "\" does not have a score.")
+	}
+
+	// Get the Challonge participant ID of the winner.
+	var winnerID float64
+	if race.Winner == 1 {
+		winnerID = race.Racer1ChallongeID
+	} else if race.Winner == 2 {
+		winnerID = race.Racer2ChallongeID
+	}
+
+	// If the games were reported one by one, the bracket gets the result of each game.
+	// (e.g. "1-0,0-1,1-0,1-0") This is skipped if an admin forced a score that does not match the
+	// games.
+	scoresCSV := race.Score.String
+	if games, err := modals.RaceGames.GetAll(race.ChannelID); err != nil {
+		return errors.New("Failed to get the games from the database: " + err.Error())
+	} else if p1Wins, p2Wins := gameGetWins(games); len(games) > 0 && strconv.Itoa(p1Wins)+"-"+strconv.Itoa(p2Wins) == race.Score.String {
+		scoresCSV = gameGetScoresCSV(games)
+	}
+
+	// Update the match on the bracket.
+	if err := getBracketProvider(tournaments[race.ChallongeURL]).ReportScore(tournaments[race.ChallongeURL], race.ChallongeMatchID, scoresCSV, winnerID); err != nil {
+		return errors.New("Failed to report the score to the bracket: " + err.Error())
+	}
+
+	// Set the state.
+	if err := raceTransition(race, trigger, RaceStateCompleted); err != nil {
+		return errors.New("Failed to set the state for race \"" + race.Name() + "\": " + err.Error())
+	}
+
+	if err := modals.Races.SetDatetimeCompleted(race.ChannelID); err != nil {
+		return errors.New("Failed to set the completion time for race \"" + race.Name() + "\": " + err.Error())
+	}
+
+	if err := schedulerCancelAll(race.ChannelID, TimerActionScoreConfirm); err != nil {
+		return errors.New("Failed to cancel the score confirmation timer for race \"" + race.Name() + "\": " + err.Error())
+	}
+
+	ratingUpdate(race)
+	scoreOpenNextMatches(race)
+
+	return nil
+}
+
+// Reporting a score can open up the next match of either racer on the bracket (e.g. the winner's
+// next round or the loser's first match in the losers bracket), so those matches get a channel
+// right away instead of waiting for the next "!startround". Failing to do so should never stop the
+// score from being submitted, so errors are only logged. (The poller or an admin can still create
+// the channels later.)
+func scoreOpenNextMatches(race *Race) {
+	tournament, ok := tournaments[race.ChallongeURL]
+	if !ok {
+		return
+	}
+
+	// Swiss and round-robin rounds are always started by an admin.
+	if pairingIsNative(tournament) {
+		return
+	}
+
+	m := &discordgo.MessageCreate{
+		Message: &discordgo.Message{
+			ChannelID: race.ChannelID,
+		},
+	}
+	startRound(m, tournament, StartRoundOptions{
+		Automatic:      true,
+		ParticipantIDs: []float64{race.Racer1ChallongeID, race.Racer2ChallongeID},
+	})
+}
+
+// Store a score that was reported by one of the racers and ask their opponent to confirm it. The
+// message is prepended to the announcement.
+func scoreReport(race *Race, reporter *discordgo.User, racerNum int, p1Wins int, p2Wins int, msg string) {
+	// Store the score until the opponent confirms it.
+	if err := scoreSet(race, p1Wins, p2Wins, racerNum); err != nil {
+		msg := "Failed to set the score for race \"" + race.Name() + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(race.ChannelID, msg)
+		return
+	}
+
+	// Set the state.
+	if err := raceTransition(race, RaceTriggerScoreReported, RaceStateScorePending); err != nil {
+		msg := "Failed to set the state for race \"" + race.Name() + "\": " + err.Error()
+		log.Error(msg)
+		discordSend(race.ChannelID, msg)
+		return
+	}
+
+	raceEventRecord(race, reporter, RaceEventTypeScoreReported, racerNum, race.Score.String, nil)
+
+	if err := scoreScheduleConfirm(race); err != nil {
+		msg := "Failed to schedule the automatic confirmation of the score: " + err.Error()
+		log.Error(msg)
+		discordSend(race.ChannelID, msg)
+		return
+	}
+
+	opponent := race.Racer2
+	if racerNum == 2 {
+		opponent = race.Racer1
+	}
+	msg += reporter.Mention() + " reported a score of \"" + race.Score.String + "\" (with " + scoreGetWinnerName(race) + " winning the match).\n"
+	msg += opponent.Mention() + ", please confirm it with `!scoreok`, or use `!scoredispute` if it is wrong."
+	msg += "\nIf it is not disputed within " + getDurationString(tournaments[race.ChallongeURL].ScoreConfirmTimeout) + ", it will be confirmed automatically."
+	discordSend(race.ChannelID, msg)
+}
+
+func scoreGetWinnerName(race *Race) string {
+	if race.Winner == 1 {
+		return race.Racer1.Username
+	}
+
+	return race.Racer2.Username
+}
+
+func scoreGetSubmittedMsg(race *Race) string {
+	return "The score of \"" + race.Score.String + "\" was successfully submitted (with " + scoreGetWinnerName(race) + " winning the match)."
+}
+
+// Confirm the reported score automatically if the opponent does not answer in time.
+func scoreScheduleConfirm(race *Race) error {
+	if err := schedulerCancelAll(race.ChannelID, TimerActionScoreConfirm); err != nil {
+		return err
+	}
+
+	timeout := tournaments[race.ChallongeURL].ScoreConfirmTimeout
+
+	// The payload is the score and the racer that reported it, so that a timer for a score that
+	// was disputed and then reported again does nothing.
+	payload := race.Score.String + "," + strconv.Itoa(race.ScoreReporter)
+	_, err := schedulerAdd(TimerActionScoreConfirm, race.ChannelID, payload, time.Now().Add(timeout))
+	return err
+}
+
+func scoreConfirmTimerFired(timer *ScheduledTimer) {
+	// Re-get the race from the database.
+	var race *Race
+	if v, err := getRace(timer.ChannelID); err != nil {
+		log.Error("Failed to re-get the race from the database: " + err.Error())
+		return
+	} else {
+		race = v
+	}
+
+	// Check to see if the score was already confirmed or disputed.
+	if race.State != RaceStateScorePending {
+		return
+	}
+	payload := strings.Split(timer.Payload, ",")
+	if len(payload) != 2 || payload[0] != race.Score.String || payload[1] != strconv.Itoa(race.ScoreReporter) {
+		return
+	}
+
+	if err := scoreSubmit(race, RaceTriggerScoreConfirmed); err != nil {
+		log.Error(err.Error())
+		discordSend(race.ChannelID, err.Error())
+		return
+	}
+
+	raceEventRecord(race, nil, RaceEventTypeScoreAutoConfirmed, race.ScoreReporter, race.Score.String, nil)
+
+	timeout := tournaments[race.ChallongeURL].ScoreConfirmTimeout
+	msg := "The score was not disputed within " + getDurationString(timeout) + ", so it was confirmed automatically.\n"
+	msg += scoreGetSubmittedMsg(race)
+	discordSend(race.ChannelID, msg)
+}
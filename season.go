@@ -0,0 +1,327 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+	A season groups tournaments together (e.g. a weekly bracket for every week of the league) and
+	keeps standings across all of them. Racers get points for every match that they win and for
+	their final placement in each tournament, as configured by the scoring table of the season.
+*/
+
+type Season struct {
+	Name string
+
+	// Every tournament in the season, including the ones that are over. (Only the Challonge URL,
+	// the ID, and the name are filled in.)
+	Tournaments []Tournament
+
+	PointsPerMatchWin int
+	PlacementPoints   []int // Starting with first place.
+
+	// Whether or not the standings are posted in the general channel every week, and when. (The
+	// time is the time of day in UTC.)
+	StandingsPost        bool
+	StandingsPostWeekday time.Weekday
+	StandingsPostTime    time.Duration
+}
+
+// SeasonStanding is one line of the standings.
+type SeasonStanding struct {
+	Name            string
+	Points          int
+	MatchWins       int
+	MatchLosses     int
+	PlacementPoints int
+	NumTournaments  int // The number of tournaments that they have a final placement in.
+}
+
+var (
+	// Indexed by name.
+	// (This is replaced along with the "tournaments" map, so it should only be accessed while
+	// holding the "commandMutex".)
+	seasons = make(map[string]Season)
+)
+
+const (
+	// Tournaments that are assigned to a season without any scoring table get a point for every
+	// match win.
+	seasonDefaultPointsPerMatchWin = 1
+)
+
+func seasonInit() {
+	if err := seasonScheduleStandingsPosts(); err != nil {
+		log.Fatal("Failed to schedule the standings posts: " + err.Error())
+		return
+	}
+}
+
+// Combine the seasons from the configuration file with the seasons of the tournaments and find the
+// IDs of every tournament in the bracket provider.
+func seasonResolve(configSeasons []Season, newTournaments map[string]Tournament, bracketTournaments []*BracketTournament) (map[string]Season, error) {
+	newSeasons := make(map[string]Season)
+	for _, season := range configSeasons {
+		newSeasons[season.Name] = season
+	}
+
+	// Tournaments can be assigned to a season that does not have a scoring table.
+	for _, tournament := range newTournaments {
+		if tournament.Season == "" {
+			continue
+		}
+
+		season, ok := newSeasons[tournament.Season]
+		if !ok {
+			season = Season{
+				Name:              tournament.Season,
+				PointsPerMatchWin: seasonDefaultPointsPerMatchWin,
+			}
+		}
+
+		alreadyAdded := false
+		for _, seasonTournament := range season.Tournaments {
+			if seasonTournament.ChallongeURL == tournament.ChallongeURL {
+				alreadyAdded = true
+				break
+			}
+		}
+		if !alreadyAdded {
+			season.Tournaments = append(season.Tournaments, Tournament{
+				ChallongeURL: tournament.ChallongeURL,
+			})
+		}
+		newSeasons[season.Name] = season
+	}
+
+	for name, season := range newSeasons {
+		for i, seasonTournament := range season.Tournaments {
+			found := false
+			for _, bracketTournament := range bracketTournaments {
+				if bracketTournament.URL == seasonTournament.ChallongeURL {
+					found = true
+					season.Tournaments[i].Name = bracketTournament.Name
+					season.Tournaments[i].ChallongeID = bracketTournament.ID
+					break
+				}
+			}
+			if !found {
+				return nil, errors.New("Failed to find the \"" + seasonTournament.ChallongeURL + "\" tournament of season \"" + name + "\" in the bracket provider's tournament list.")
+			}
+		}
+	}
+
+	return newSeasons, nil
+}
+
+// Parse a weekly time, e.g. "Monday 18:00".
+func seasonParseStandingsPost(standingsPost string) (time.Weekday, time.Duration, error) {
+	fields := strings.Fields(standingsPost)
+	if len(fields) != 2 {
+		return 0, 0, errors.New("\"" + standingsPost + "\" is not a day and a time.")
+	}
+
+	weekday := time.Weekday(-1)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(fields[0], day.String()) {
+			weekday = day
+			break
+		}
+	}
+	if weekday == -1 {
+		return 0, 0, errors.New("\"" + fields[0] + "\" is not a day of the week.")
+	}
+
+	var timeOfDay time.Time
+	if v, err := time.Parse("15:04", fields[1]); err != nil {
+		return 0, 0, errors.New("\"" + fields[1] + "\" is not a time in the format of \"HH:MM\".")
+	} else {
+		timeOfDay = v
+	}
+
+	return weekday, time.Duration(timeOfDay.Hour())*time.Hour + time.Duration(timeOfDay.Minute())*time.Minute, nil
+}
+
+// Get the next time that the standings of the season should be posted.
+func seasonGetNextStandingsPost(season Season, now time.Time) time.Time {
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	daysUntil := (int(season.StandingsPostWeekday) - int(now.Weekday()) + 7) % 7
+	next := midnight.AddDate(0, 0, daysUntil).Add(season.StandingsPostTime)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+
+	return next
+}
+
+// Make sure that every season with a weekly standings post has exactly one timer for the next
+// post, and that there are no timers left over for seasons that were removed. (A post that was
+// missed while the bot was offline is kept, so that it is sent as soon as possible.)
+func seasonScheduleStandingsPosts() error {
+	now := time.Now()
+	alreadyScheduled := make(map[string]bool)
+	for _, timer := range schedulerGetAll() {
+		if timer.Action != TimerActionStandingsPost {
+			continue
+		}
+
+		season, ok := seasons[timer.Payload]
+		if ok && season.StandingsPost && !alreadyScheduled[season.Name] &&
+			(timer.DatetimeFire.Equal(seasonGetNextStandingsPost(season, now)) || timer.DatetimeFire.Before(now)) {
+
+			alreadyScheduled[season.Name] = true
+			continue
+		}
+		if err := schedulerCancel(timer.ID); err != nil {
+			return err
+		}
+	}
+
+	for _, season := range seasons {
+		if !season.StandingsPost || alreadyScheduled[season.Name] {
+			continue
+		}
+		if _, err := schedulerAdd(TimerActionStandingsPost, "", season.Name, seasonGetNextStandingsPost(season, now)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func seasonStandingsPostTimerFired(timer *ScheduledTimer) {
+	// The payload is the name of the season.
+	season, ok := seasons[timer.Payload]
+	if !ok || !season.StandingsPost {
+		return
+	}
+
+	if msg, err := seasonGetStandingsMsg(season); err != nil {
+		log.Error("Failed to get the standings for season \"" + season.Name + "\": " + err.Error())
+	} else {
+		discordSend(discordGeneralChannelID, "**Weekly standings** - "+msg)
+	}
+
+	// Schedule the post for next week. (The timer that fired has already been removed.)
+	if err := seasonScheduleStandingsPosts(); err != nil {
+		log.Error("Failed to schedule the next standings post for season \"" + season.Name + "\": " + err.Error())
+	}
+}
+
+// Get the points of every racer in the season, from first place to last.
+func seasonGetStandings(season Season) ([]*SeasonStanding, error) {
+	standingMap := make(map[string]*SeasonStanding)
+	getStanding := func(name string) *SeasonStanding {
+		key := strings.ToLower(name)
+		if _, ok := standingMap[key]; !ok {
+			standingMap[key] = &SeasonStanding{
+				Name: name,
+			}
+		}
+		return standingMap[key]
+	}
+
+	// Match wins come from the completed races in the database.
+	challongeURLs := make([]string, 0)
+	for _, tournament := range season.Tournaments {
+		challongeURLs = append(challongeURLs, tournament.ChallongeURL)
+	}
+	var records []*StatsMatchRecord
+	if v, err := modals.Stats.GetMatchRecords(season.Name, challongeURLs); err != nil {
+		return nil, errors.New("Failed to get the match records from the database: " + err.Error())
+	} else {
+		records = v
+	}
+	for _, record := range records {
+		standing := getStanding(record.Username)
+		standing.MatchWins += record.Wins
+		standing.MatchLosses += record.Losses
+		standing.Points += record.Wins * season.PointsPerMatchWin
+	}
+
+	// Placements come from the bracket, once a tournament is over.
+	for _, tournament := range season.Tournaments {
+		// Swiss and round-robin tournaments have their final ranks in the pairings, so the tournament
+		// needs its format. (Tournaments that are over are no longer configured, so they use the
+		// bracket provider.)
+		provider := bracketProvider
+		if currentTournament, ok := tournaments[tournament.ChallongeURL]; ok {
+			tournament = currentTournament
+			provider = getBracketProvider(tournament)
+		}
+
+		var participants []*BracketParticipant
+		if v, err := provider.GetParticipants(tournament); err != nil {
+			return nil, errors.New("Failed to get the participants for tournament \"" + tournament.Name + "\": " + err.Error())
+		} else {
+			participants = v
+		}
+
+		for _, participant := range participants {
+			if participant.FinalRank == 0 {
+				continue
+			}
+
+			standing := getStanding(participant.Name)
+			standing.NumTournaments++
+			if participant.FinalRank <= len(season.PlacementPoints) {
+				points := season.PlacementPoints[participant.FinalRank-1]
+				standing.PlacementPoints += points
+				standing.Points += points
+			}
+		}
+	}
+
+	standings := make([]*SeasonStanding, 0)
+	for _, standing := range standingMap {
+		standings = append(standings, standing)
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		if standings[i].Points != standings[j].Points {
+			return standings[i].Points > standings[j].Points
+		}
+		if standings[i].MatchWins != standings[j].MatchWins {
+			return standings[i].MatchWins > standings[j].MatchWins
+		}
+		return strings.ToLower(standings[i].Name) < strings.ToLower(standings[j].Name)
+	})
+
+	return standings, nil
+}
+
+func seasonGetStandingsMsg(season Season) (string, error) {
+	var standings []*SeasonStanding
+	if v, err := seasonGetStandings(season); err != nil {
+		return "", err
+	} else {
+		standings = v
+	}
+
+	msg := "Standings for **" + season.Name + "** (" + strconv.Itoa(len(season.Tournaments)) + " tournament" + getPlural(len(season.Tournaments)) + "):\n"
+	if len(standings) == 0 {
+		msg += "Nobody has scored any points yet."
+		return msg, nil
+	}
+
+	// The standings are put in a code block so that the columns line up.
+	table := [][]string{
+		{"#", "Name", "Points", "Matches", "Placement points"},
+	}
+	for i, standing := range standings {
+		table = append(table, []string{
+			strconv.Itoa(i + 1),
+			standing.Name,
+			strconv.Itoa(standing.Points),
+			strconv.Itoa(standing.MatchWins) + "-" + strconv.Itoa(standing.MatchLosses),
+			strconv.Itoa(standing.PlacementPoints),
+		})
+	}
+	msg += getTableMsg(table)
+
+	return msg, nil
+}
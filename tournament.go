@@ -0,0 +1,350 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Tournament struct {
+	Name              string
+	ChallongeURL      string
+	ChallongeID       float64
+	Ruleset           Ruleset
+	DiscordCategoryID string
+	BestOf            int
+	Season            string // Optional; used to group tournaments together in the "!stats" command.
+
+	// How the matches are paired. Swiss and round-robin tournaments are paired by the bot itself
+	// (see "pairing.go"), so the bracket provider is only used for the list of participants.
+	Format    TournamentFormat
+	NumRounds int // The number of Swiss rounds. (0 means enough rounds to find a single winner.)
+
+	// Whether or not a racer can have more than one match channel at a time, e.g. in a group stage
+	// or a round-robin where every match is open at once.
+	ConcurrentMatches bool
+
+	// The draft format
+	Type              TournamentType
+	NumCharacterBans  int
+	NumBuildBans      int
+	NumCharacterVetos int
+	NumBuildVetos     int
+
+	// How long the active racer has to ban, pick, or veto before the bot does it for them, and how
+	// long before that to warn them. (0 means that there is no limit.)
+	TurnTimeout time.Duration
+	TurnWarning time.Duration
+
+	// How long the opponent has to confirm or dispute a reported score before it is confirmed
+	// automatically. (If it is not configured, "tournamentDefaultScoreConfirmTimeout" is used.)
+	ScoreConfirmTimeout time.Duration
+
+	Characters []string
+	Builds     []string
+	Languages  map[string]string // Indexed by language code, e.g. "en"
+}
+
+var (
+	// Indexed by Challonge URL suffix.
+	// (This is replaced as a whole by the "!reload" command, so it should only be accessed while
+	// holding the "commandMutex".)
+	tournaments = make(map[string]Tournament)
+)
+
+const (
+	// A reported score is always confirmed automatically at some point, so that a match is not stuck
+	// waiting on an opponent who never comes back.
+	tournamentDefaultScoreConfirmTimeout = time.Hour
+)
+
+func tournamentInit() {
+	if v1, v2, err := tournamentLoad(); err != nil {
+		log.Fatal(err.Error())
+		return
+	} else {
+		tournaments = v1
+		seasons = v2
+	}
+}
+
+// Read the tournament configuration and resolve the IDs of the tournaments with the bracket
+// provider. The seasons that the tournaments belong to are returned as well. Nothing is changed if
+// there is an error, so this is safe to use when reloading.
+func tournamentLoad() (map[string]Tournament, map[string]Season, error) {
+	// The tournaments are declared in a configuration file. If there is no configuration file, we
+	// fall back to the comma-separated lists in the ".env" file.
+	var config *TournamentConfig
+	var configSource string
+	configPath := os.Getenv("TOURNAMENT_CONFIG")
+	configPathSpecified := len(configPath) > 0
+	if !configPathSpecified {
+		configPath = path.Join(projectPath, "tournaments.json")
+	}
+	if _, err := os.Stat(configPath); os.IsNotExist(err) && !configPathSpecified {
+		log.Info("No tournament configuration file was found at \"" + configPath + "\", so using the environment variables.")
+		if v, err := tournamentGetFromEnv(); err != nil {
+			return nil, nil, err
+		} else {
+			config = v
+			configSource = "the environment variables"
+		}
+	} else if err != nil {
+		return nil, nil, errors.New("Failed to check if the \"" + configPath + "\" file exists: " + err.Error())
+	} else if v, err := tournamentConfigRead(configPath); err != nil {
+		return nil, nil, err
+	} else {
+		config = v
+		configSource = "the \"" + configPath + "\" file"
+	}
+
+	var configTournaments []Tournament
+	var configSeasons []Season
+	if v1, v2, err := tournamentConfigLoad(config, configSource); err != nil {
+		return nil, nil, err
+	} else {
+		configTournaments = v1
+		configSeasons = v2
+	}
+
+	// Get all of the tournaments from the bracket provider.
+	var bracketTournaments []*BracketTournament
+	if v, err := bracketProvider.GetTournaments(); err != nil {
+		return nil, nil, errors.New("Failed to get the tournaments from the bracket provider: " + err.Error())
+	} else {
+		bracketTournaments = v
+	}
+
+	// Figure out the name and the ID for all of the configured tournaments.
+	newTournaments := make(map[string]Tournament)
+	for _, tournament := range configTournaments {
+		found := false
+		for _, bracketTournament := range bracketTournaments {
+			if bracketTournament.URL == tournament.ChallongeURL {
+				found = true
+				tournament.Name = bracketTournament.Name
+				tournament.ChallongeID = bracketTournament.ID
+				newTournaments[tournament.ChallongeURL] = tournament
+				break
+			}
+		}
+		if !found {
+			return nil, nil, errors.New("Failed to find the \"" + tournament.ChallongeURL + "\" tournament in the bracket provider's tournament list.")
+		}
+	}
+
+	var newSeasons map[string]Season
+	if v, err := seasonResolve(configSeasons, newTournaments, bracketTournaments); err != nil {
+		return nil, nil, err
+	} else {
+		newSeasons = v
+	}
+
+	return newTournaments, newSeasons, nil
+}
+
+// Read the tournament configuration from the comma-separated lists in the environment variables.
+// The values are only parsed here; they are validated along with the configuration file.
+func tournamentGetFromEnv() (*TournamentConfig, error) {
+	tournamentURLsString := os.Getenv("TOURNAMENT_CHALLONGE_URLS")
+	if len(tournamentURLsString) == 0 {
+		return nil, errors.New("The \"TOURNAMENT_CHALLONGE_URLS\" environment variable is blank. Set it in the \".env\" file.")
+	}
+	tournamentURLs := strings.Split(tournamentURLsString, ",")
+	numTournaments := len(tournamentURLs)
+
+	// The rest of the lists can either have a single value that applies to every tournament or one
+	// value per tournament.
+	var tournamentRulesetStrings, tournamentDiscordCategoryIDs, tournamentTypeStrings []string
+	var tournamentBestOf, numCharacterBans, numBuildBans, numCharacterVetos, numBuildVetos []int
+	for _, list := range []struct {
+		envVarName string
+		values     *[]string
+	}{
+		{"TOURNAMENT_RULESETS", &tournamentRulesetStrings},
+		{"TOURNAMENT_DISCORD_CATEGORY_IDS", &tournamentDiscordCategoryIDs},
+		{"TOURNAMENT_TYPE", &tournamentTypeStrings},
+	} {
+		if v, err := tournamentGetEnvList(list.envVarName, numTournaments); err != nil {
+			return nil, err
+		} else {
+			*list.values = v
+		}
+	}
+	for _, list := range []struct {
+		envVarName string
+		values     *[]int
+	}{
+		{"TOURNAMENT_BEST_OF", &tournamentBestOf},
+		{"NUM_CHARACTER_BANS", &numCharacterBans},
+		{"NUM_BUILD_BANS", &numBuildBans},
+		{"NUM_CHARACTER_VETOS", &numCharacterVetos},
+		{"NUM_BUILD_VETOS", &numBuildVetos},
+	} {
+		if v, err := tournamentGetEnvIntList(list.envVarName, numTournaments); err != nil {
+			return nil, err
+		} else {
+			*list.values = v
+		}
+	}
+
+	// The rest of the lists are optional.
+	var seasons, formats, turnTimeouts, turnWarnings, scoreConfirmTimeouts []string
+	for _, list := range []struct {
+		envVarName string
+		values     *[]string
+	}{
+		{"TOURNAMENT_SEASONS", &seasons},
+		{"TOURNAMENT_FORMATS", &formats},
+		{"TOURNAMENT_TURN_TIMEOUT", &turnTimeouts},
+		{"TOURNAMENT_TURN_WARNING", &turnWarnings},
+		{"TOURNAMENT_SCORE_CONFIRM_TIMEOUT", &scoreConfirmTimeouts},
+	} {
+		if v, err := tournamentGetEnvOptionalList(list.envVarName, numTournaments); err != nil {
+			return nil, err
+		} else {
+			*list.values = v
+		}
+	}
+
+	var numRounds []int
+	if len(os.Getenv("TOURNAMENT_NUM_ROUNDS")) == 0 {
+		for len(numRounds) < numTournaments {
+			numRounds = append(numRounds, 0)
+		}
+	} else if v, err := tournamentGetEnvIntList("TOURNAMENT_NUM_ROUNDS", numTournaments); err != nil {
+		return nil, err
+	} else {
+		numRounds = v
+	}
+
+	var concurrentMatches []bool
+	if len(os.Getenv("TOURNAMENT_CONCURRENT_MATCHES")) == 0 {
+		for len(concurrentMatches) < numTournaments {
+			concurrentMatches = append(concurrentMatches, false)
+		}
+	} else if v, err := tournamentGetEnvList("TOURNAMENT_CONCURRENT_MATCHES", numTournaments); err != nil {
+		return nil, err
+	} else {
+		for _, value := range v {
+			if b, err := strconv.ParseBool(strings.TrimSpace(value)); err != nil {
+				return nil, errors.New("One of the values in the \"TOURNAMENT_CONCURRENT_MATCHES\" environment variable is not \"true\" or \"false\".")
+			} else {
+				concurrentMatches = append(concurrentMatches, b)
+			}
+		}
+	}
+
+	config := &TournamentConfig{
+		Tournaments: make([]TournamentConfigEntry, 0),
+	}
+	for i, tournamentURL := range tournamentURLs {
+		config.Tournaments = append(config.Tournaments, TournamentConfigEntry{
+			ChallongeURL:        tournamentURL,
+			Ruleset:             tournamentRulesetStrings[i],
+			BestOf:              tournamentBestOf[i],
+			DiscordCategoryID:   tournamentDiscordCategoryIDs[i],
+			Season:              seasons[i],
+			Format:              formats[i],
+			NumRounds:           numRounds[i],
+			ConcurrentMatches:   concurrentMatches[i],
+			Type:                tournamentTypeStrings[i],
+			NumCharacterBans:    numCharacterBans[i],
+			NumBuildBans:        numBuildBans[i],
+			NumCharacterVetos:   numCharacterVetos[i],
+			NumBuildVetos:       numBuildVetos[i],
+			TurnTimeout:         turnTimeouts[i],
+			TurnWarning:         turnWarnings[i],
+			ScoreConfirmTimeout: scoreConfirmTimeouts[i],
+		})
+	}
+
+	return config, nil
+}
+
+// If a tournament does not restrict the characters, builds, or languages, then everything is
+// allowed. Scores are confirmed automatically after the default timeout if it is not configured.
+func tournamentFillDefaults(tournament *Tournament) {
+	if tournament.ScoreConfirmTimeout == 0 {
+		tournament.ScoreConfirmTimeout = tournamentDefaultScoreConfirmTimeout
+	}
+	if len(tournament.Characters) == 0 {
+		tournament.Characters = append([]string{}, characters...)
+	}
+	if len(tournament.Builds) == 0 {
+		tournament.Builds = make([]string, 0)
+		for _, build := range builds {
+			tournament.Builds = append(tournament.Builds, build.Name)
+		}
+	}
+	if len(tournament.Languages) == 0 {
+		tournament.Languages = languageMap
+	}
+}
+
+// Get a comma separated environment variable with one value for each tournament. If there is only
+// one value, it is used for every tournament.
+func tournamentGetEnvList(envVarName string, numTournaments int) ([]string, error) {
+	valuesString := os.Getenv(envVarName)
+	if len(valuesString) == 0 {
+		return nil, errors.New("The \"" + envVarName + "\" environment variable is blank. Set it in the \".env\" file.")
+	}
+	values := strings.Split(valuesString, ",")
+
+	if len(values) == 1 {
+		for len(values) < numTournaments {
+			values = append(values, values[0])
+		}
+	} else if len(values) != numTournaments {
+		return nil, errors.New("The \"" + envVarName + "\" environment variable must have either one value or " + strconv.Itoa(numTournaments) + " values (one for each tournament).")
+	}
+
+	return values, nil
+}
+
+func tournamentGetEnvIntList(envVarName string, numTournaments int) ([]int, error) {
+	var valueStrings []string
+	if v, err := tournamentGetEnvList(envVarName, numTournaments); err != nil {
+		return nil, err
+	} else {
+		valueStrings = v
+	}
+
+	values := make([]int, 0)
+	for _, valueString := range valueStrings {
+		if v, err := strconv.Atoi(valueString); err != nil {
+			return nil, errors.New("One of the values in the \"" + envVarName + "\" environment variable is not a number.")
+		} else {
+			values = append(values, v)
+		}
+	}
+
+	return values, nil
+}
+
+// Get a list that can be blank, in which case every value is blank.
+func tournamentGetEnvOptionalList(envVarName string, numTournaments int) ([]string, error) {
+	values := make([]string, 0)
+	if len(os.Getenv(envVarName)) == 0 {
+		for len(values) < numTournaments {
+			values = append(values, "")
+		}
+		return values, nil
+	}
+
+	var valueStrings []string
+	if v, err := tournamentGetEnvList(envVarName, numTournaments); err != nil {
+		return nil, err
+	} else {
+		valueStrings = v
+	}
+
+	for _, valueString := range valueStrings {
+		values = append(values, strings.TrimSpace(valueString))
+	}
+
+	return values, nil
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TournamentConfig is the format of the tournament configuration file (see
+// "install/tournaments_example.json"). Each tournament is declared as one record, which avoids
+// having to keep the comma-separated lists in the ".env" file aligned with each other.
+type TournamentConfig struct {
+	Tournaments []TournamentConfigEntry `json:"tournaments"`
+	Seasons     []SeasonConfigEntry     `json:"seasons"` // Optional
+}
+
+type TournamentConfigEntry struct {
+	ChallongeURL      string `json:"challonge_url"`
+	Ruleset           string `json:"ruleset"`
+	BestOf            int    `json:"best_of"`
+	DiscordCategoryID string `json:"discord_category_id"`
+	Season            string `json:"season"` // Optional, e.g. "Season 1"
+
+	// Optional; either "bracket" (the default), "swiss", or "roundRobin". "num_rounds" is the number
+	// of Swiss rounds; if it is not specified, there are enough rounds to find a single winner.
+	Format    string `json:"format"`
+	NumRounds int    `json:"num_rounds"`
+
+	// Optional; if true, a racer can have more than one match channel at a time. This is needed for
+	// group stages and round-robins on Challonge, where every match of a group is open at once.
+	ConcurrentMatches bool `json:"concurrent_matches"`
+
+	// The draft format
+	Type              string `json:"type"`
+	NumCharacterBans  int    `json:"num_character_bans"`
+	NumBuildBans      int    `json:"num_build_bans"`
+	NumCharacterVetos int    `json:"num_character_vetos"`
+	NumBuildVetos     int    `json:"num_build_vetos"`
+
+	// These are optional durations, e.g. "10m". If "turn_timeout" is not specified, there is no
+	// limit on how long a racer can take to ban, pick, or veto.
+	TurnTimeout string `json:"turn_timeout"`
+	TurnWarning string `json:"turn_warning"`
+
+	// An optional duration, e.g. "1h". A reported score that the opponent does not confirm or
+	// dispute within it is submitted automatically. If it is not specified, it defaults to one
+	// hour.
+	ScoreConfirmTimeout string `json:"score_confirm_timeout"`
+
+	// These are optional; if they are not specified, every character, build, and language is
+	// allowed.
+	Characters []string          `json:"characters"`
+	Builds     []string          `json:"builds"`
+	Languages  map[string]string `json:"languages"` // e.g. "en": "English"
+}
+
+// A season groups tournaments together for the "!standings" command. Tournaments join a season with
+// their "season" field; tournaments that are over (and no longer listed in "tournaments") can be
+// added to the "tournaments" list of the season so that they still count.
+type SeasonConfigEntry struct {
+	Name              string   `json:"name"`
+	Tournaments       []string `json:"tournaments"` // The Challonge URL suffixes
+	PointsPerMatchWin int      `json:"points_per_match_win"`
+
+	// The points for the final placement in each tournament, starting with first place. (e.g.
+	// [10, 7, 5, 5] gives 10 points for winning, 7 for second place, and 5 for third and fourth.)
+	PlacementPoints []int `json:"placement_points"`
+
+	// Optional; the standings are posted in the general channel every week at this time (in UTC),
+	// e.g. "Monday 18:00".
+	StandingsPost string `json:"standings_post"`
+}
+
+// Read the tournament configuration file. A field that is misspelled is an error rather than being
+// silently ignored.
+func tournamentConfigRead(filePath string) (*TournamentConfig, error) {
+	var raw []byte
+	if v, err := ioutil.ReadFile(filePath); err != nil {
+		return nil, err
+	} else {
+		raw = v
+	}
+
+	var config TournamentConfig
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, errors.New("Failed to unmarshal the tournament configuration file \"" + filePath + "\": " + err.Error())
+	}
+
+	return &config, nil
+}
+
+// Validate the tournament configuration and convert it to tournaments and seasons. The source is
+// the configuration file or the environment variables, which are validated the same way. (The
+// names and the IDs of the tournaments are filled in later from the bracket provider.)
+func tournamentConfigLoad(config *TournamentConfig, source string) ([]Tournament, []Season, error) {
+	if errs := tournamentConfigValidate(config); len(errs) > 0 {
+		msg := "The tournament configuration from " + source + " is invalid:\n"
+		for _, err := range errs {
+			msg += "- " + err + "\n"
+		}
+		return nil, nil, errors.New(strings.TrimSpace(msg))
+	}
+
+	configTournaments := make([]Tournament, 0)
+	for _, entry := range config.Tournaments {
+		tournament := Tournament{
+			ChallongeURL:        entry.ChallongeURL,
+			Ruleset:             Ruleset(entry.Ruleset),
+			DiscordCategoryID:   entry.DiscordCategoryID,
+			BestOf:              entry.BestOf,
+			Season:              entry.Season,
+			Format:              TournamentFormat(entry.Format),
+			NumRounds:           entry.NumRounds,
+			ConcurrentMatches:   entry.ConcurrentMatches,
+			Type:                TournamentType(entry.Type),
+			NumCharacterBans:    entry.NumCharacterBans,
+			NumBuildBans:        entry.NumBuildBans,
+			NumCharacterVetos:   entry.NumCharacterVetos,
+			NumBuildVetos:       entry.NumBuildVetos,
+			TurnTimeout:         tournamentConfigParseDuration(entry.TurnTimeout),
+			TurnWarning:         tournamentConfigParseDuration(entry.TurnWarning),
+			ScoreConfirmTimeout: tournamentConfigParseDuration(entry.ScoreConfirmTimeout),
+			Characters:          entry.Characters,
+			Builds:              entry.Builds,
+			Languages:           entry.Languages,
+		}
+		if tournament.Format == "" {
+			tournament.Format = TournamentFormatBracket
+		}
+		tournamentFillDefaults(&tournament)
+		configTournaments = append(configTournaments, tournament)
+	}
+
+	configSeasons := make([]Season, 0)
+	for _, entry := range config.Seasons {
+		season := Season{
+			Name:              entry.Name,
+			PointsPerMatchWin: entry.PointsPerMatchWin,
+			PlacementPoints:   entry.PlacementPoints,
+		}
+		for _, challongeURL := range entry.Tournaments {
+			season.Tournaments = append(season.Tournaments, Tournament{
+				ChallongeURL: challongeURL,
+			})
+		}
+		if entry.StandingsPost != "" {
+			weekday, timeOfDay, _ := seasonParseStandingsPost(entry.StandingsPost)
+			season.StandingsPost = true
+			season.StandingsPostWeekday = weekday
+			season.StandingsPostTime = timeOfDay
+		}
+		configSeasons = append(configSeasons, season)
+	}
+
+	return configTournaments, configSeasons, nil
+}
+
+// Returns every problem with the configuration so that they can all be fixed at once.
+func tournamentConfigValidate(config *TournamentConfig) []string {
+	errs := make([]string, 0)
+	if len(config.Tournaments) == 0 {
+		errs = append(errs, "There are no tournaments listed in the \"tournaments\" array.")
+		return errs
+	}
+
+	challongeURLs := make([]string, 0)
+	for i, entry := range config.Tournaments {
+		prefix := "Tournament #" + strconv.Itoa(i+1)
+		if entry.ChallongeURL != "" {
+			prefix += " (\"" + entry.ChallongeURL + "\")"
+		}
+		prefix += ": "
+
+		if entry.ChallongeURL == "" {
+			errs = append(errs, prefix+"\"challonge_url\" is blank.")
+		} else if strings.Contains(entry.ChallongeURL, "/") {
+			errs = append(errs, prefix+"\"challonge_url\" must contain only the URL suffix and not the entire URL.")
+		} else if stringInSlice(entry.ChallongeURL, challongeURLs) {
+			errs = append(errs, prefix+"\"challonge_url\" is listed more than once.")
+		}
+		challongeURLs = append(challongeURLs, entry.ChallongeURL)
+
+		if entry.Ruleset != RulesetSeeded && entry.Ruleset != RulesetUnseeded && entry.Ruleset != RulesetTeam {
+			errs = append(errs, prefix+"\"ruleset\" is set to \""+entry.Ruleset+"\", but it must be \""+RulesetSeeded+"\", \""+RulesetUnseeded+"\", or \""+RulesetTeam+"\".")
+		}
+
+		if entry.BestOf <= 0 || entry.BestOf%2 == 0 {
+			errs = append(errs, prefix+"\"best_of\" is set to "+strconv.Itoa(entry.BestOf)+", but it must be a positive odd number.")
+		}
+
+		if entry.DiscordCategoryID == "" {
+			errs = append(errs, prefix+"\"discord_category_id\" is blank.")
+		}
+
+		if entry.Type != TournamentTypeBanPick && entry.Type != TournamentTypeVeto {
+			errs = append(errs, prefix+"\"type\" is set to \""+entry.Type+"\", but it must be \""+TournamentTypeBanPick+"\" or \""+TournamentTypeVeto+"\".")
+		}
+
+		if entry.Format != "" && entry.Format != TournamentFormatBracket && entry.Format != TournamentFormatSwiss && entry.Format != TournamentFormatRoundRobin {
+			errs = append(errs, prefix+"\"format\" is set to \""+entry.Format+"\", but it must be \""+TournamentFormatBracket+"\", \""+TournamentFormatSwiss+"\", or \""+TournamentFormatRoundRobin+"\".")
+		}
+		if entry.NumRounds < 0 {
+			errs = append(errs, prefix+"\"num_rounds\" cannot be negative.")
+		} else if entry.NumRounds > 0 && entry.Format != TournamentFormatSwiss {
+			errs = append(errs, prefix+"\"num_rounds\" can only be specified for Swiss tournaments.")
+		}
+
+		if entry.NumCharacterBans < 0 {
+			errs = append(errs, prefix+"\"num_character_bans\" cannot be negative.")
+		}
+		if entry.NumBuildBans < 0 {
+			errs = append(errs, prefix+"\"num_build_bans\" cannot be negative.")
+		}
+		if entry.NumCharacterVetos < 0 {
+			errs = append(errs, prefix+"\"num_character_vetos\" cannot be negative.")
+		}
+		if entry.NumBuildVetos < 0 {
+			errs = append(errs, prefix+"\"num_build_vetos\" cannot be negative.")
+		}
+
+		var turnTimeout, turnWarning time.Duration
+		if v, err := time.ParseDuration(entry.TurnTimeout); entry.TurnTimeout != "" && err != nil {
+			errs = append(errs, prefix+"\"turn_timeout\" is set to \""+entry.TurnTimeout+"\", which is not a valid duration.")
+		} else if v < 0 {
+			errs = append(errs, prefix+"\"turn_timeout\" cannot be negative.")
+		} else {
+			turnTimeout = v
+		}
+		if v, err := time.ParseDuration(entry.TurnWarning); entry.TurnWarning != "" && err != nil {
+			errs = append(errs, prefix+"\"turn_warning\" is set to \""+entry.TurnWarning+"\", which is not a valid duration.")
+		} else if v < 0 {
+			errs = append(errs, prefix+"\"turn_warning\" cannot be negative.")
+		} else {
+			turnWarning = v
+		}
+		if turnWarning > 0 && turnWarning >= turnTimeout {
+			errs = append(errs, prefix+"\"turn_warning\" must be shorter than \"turn_timeout\".")
+		}
+		if v, err := time.ParseDuration(entry.ScoreConfirmTimeout); entry.ScoreConfirmTimeout != "" && err != nil {
+			errs = append(errs, prefix+"\"score_confirm_timeout\" is set to \""+entry.ScoreConfirmTimeout+"\", which is not a valid duration.")
+		} else if entry.ScoreConfirmTimeout != "" && v <= 0 {
+			errs = append(errs, prefix+"\"score_confirm_timeout\" must be positive. (Leave it out to use the default of "+getDurationString(tournamentDefaultScoreConfirmTimeout)+".)")
+		}
+
+		for _, character := range entry.Characters {
+			if !stringInSlice(character, characters) {
+				errs = append(errs, prefix+"\"characters\" contains \""+character+"\", which is not a valid character.")
+			}
+		}
+		for _, buildName := range entry.Builds {
+			if !buildExists(buildName) {
+				errs = append(errs, prefix+"\"builds\" contains \""+buildName+"\", which is not a valid build.")
+			}
+		}
+		for code, name := range entry.Languages {
+			if code == "" || name == "" {
+				errs = append(errs, prefix+"\"languages\" contains a blank language code or name.")
+			}
+		}
+
+		// Check to see if there are enough characters and builds for the draft.
+		numCharacters := len(entry.Characters)
+		if numCharacters == 0 {
+			numCharacters = len(characters)
+		}
+		numBuilds := len(entry.Builds)
+		if numBuilds == 0 {
+			numBuilds = len(builds)
+		}
+		if entry.Type == TournamentTypeBanPick {
+			if numCharacters < entry.NumCharacterBans*2+entry.BestOf {
+				errs = append(errs, prefix+"There are not enough characters for "+strconv.Itoa(entry.NumCharacterBans)+" bans per racer and "+strconv.Itoa(entry.BestOf)+" picks.")
+			}
+			if entry.Ruleset == RulesetSeeded && numBuilds < entry.NumBuildBans*2+entry.BestOf {
+				errs = append(errs, prefix+"There are not enough builds for "+strconv.Itoa(entry.NumBuildBans)+" bans per racer and "+strconv.Itoa(entry.BestOf)+" picks.")
+			}
+		} else if entry.Type == TournamentTypeVeto {
+			if numCharacters < entry.NumCharacterVetos*2+entry.BestOf {
+				errs = append(errs, prefix+"There are not enough characters for "+strconv.Itoa(entry.NumCharacterVetos)+" vetos per racer and "+strconv.Itoa(entry.BestOf)+" rounds.")
+			}
+			if entry.Ruleset == RulesetSeeded && numBuilds < entry.NumBuildVetos*2+entry.BestOf {
+				errs = append(errs, prefix+"There are not enough builds for "+strconv.Itoa(entry.NumBuildVetos)+" vetos per racer and "+strconv.Itoa(entry.BestOf)+" rounds.")
+			}
+		}
+	}
+
+	seasonNames := make([]string, 0)
+	for i, entry := range config.Seasons {
+		prefix := "Season #" + strconv.Itoa(i+1)
+		if entry.Name != "" {
+			prefix += " (\"" + entry.Name + "\")"
+		}
+		prefix += ": "
+
+		if entry.Name == "" {
+			errs = append(errs, prefix+"\"name\" is blank.")
+		} else if stringInSlice(entry.Name, seasonNames) {
+			errs = append(errs, prefix+"\"name\" is listed more than once.")
+		}
+		seasonNames = append(seasonNames, entry.Name)
+
+		for _, challongeURL := range entry.Tournaments {
+			if challongeURL == "" || strings.Contains(challongeURL, "/") {
+				errs = append(errs, prefix+"\"tournaments\" contains \""+challongeURL+"\", but it must contain only the URL suffixes and not the entire URLs.")
+			}
+		}
+
+		if entry.PointsPerMatchWin < 0 {
+			errs = append(errs, prefix+"\"points_per_match_win\" cannot be negative.")
+		}
+		for _, points := range entry.PlacementPoints {
+			if points < 0 {
+				errs = append(errs, prefix+"\"placement_points\" cannot contain negative numbers.")
+				break
+			}
+		}
+
+		if entry.StandingsPost != "" {
+			if _, _, err := seasonParseStandingsPost(entry.StandingsPost); err != nil {
+				errs = append(errs, prefix+"\"standings_post\" is set to \""+entry.StandingsPost+"\", but it must be a day and a time, e.g. \"Monday 18:00\".")
+			}
+		}
+	}
+
+	return errs
+}
+
+// The durations have already been validated, so a blank or invalid one is treated as 0.
+func tournamentConfigParseDuration(durationString string) time.Duration {
+	duration, _ := time.ParseDuration(durationString)
+	return duration
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestTournamentConfigRead(t *testing.T) {
+	// "best_of" is misspelled.
+	filePath := path.Join(t.TempDir(), "tournaments.json")
+	raw := `{"tournaments": [{"challonge_url": "test", "ruleset": "unseeded", "bestof": 3}]}`
+	if err := os.WriteFile(filePath, []byte(raw), 0644); err != nil {
+		t.Fatalf("Failed to write the configuration file: %v", err)
+	}
+
+	want := "json: unknown field \"bestof\""
+	if _, err := tournamentConfigRead(filePath); err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("got an error of %v, want it to contain %q", err, want)
+	}
+}
+
+func TestTournamentGetFromEnv(t *testing.T) {
+	t.Setenv("TOURNAMENT_CHALLONGE_URLS", "test1,test2")
+	t.Setenv("TOURNAMENT_RULESETS", "unseeded")
+	t.Setenv("TOURNAMENT_DISCORD_CATEGORY_IDS", "1,2")
+	t.Setenv("TOURNAMENT_TYPE", "veto")
+	t.Setenv("TOURNAMENT_BEST_OF", "3,2")
+	t.Setenv("NUM_CHARACTER_BANS", "0")
+	t.Setenv("NUM_BUILD_BANS", "0")
+	t.Setenv("NUM_CHARACTER_VETOS", "1,-1")
+	t.Setenv("NUM_BUILD_VETOS", "0")
+
+	var config *TournamentConfig
+	if v, err := tournamentGetFromEnv(); err != nil {
+		t.Fatalf("Failed to read the configuration from the environment variables: %v", err)
+	} else {
+		config = v
+	}
+
+	// The environment variables are held to the same rules as the configuration file.
+	_, _, err := tournamentConfigLoad(config, "the environment variables")
+	if err == nil {
+		t.Fatal("got no error for an even \"best_of\" and negative vetos")
+	}
+	want := "The tournament configuration from the environment variables is invalid:\n"
+	want += "- Tournament #2 (\"test2\"): \"best_of\" is set to 2, but it must be a positive odd number.\n"
+	want += "- Tournament #2 (\"test2\"): \"num_character_vetos\" cannot be negative."
+	if err.Error() != want {
+		t.Errorf("got an error of %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,10 @@
+package main
+
+type TournamentFormat string
+
+// See the comment in ".env_template".
+const (
+	TournamentFormatBracket    = "bracket"
+	TournamentFormatSwiss      = "swiss"
+	TournamentFormatRoundRobin = "roundRobin"
+)
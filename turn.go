@@ -0,0 +1,228 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+/*
+	If the active racer does not ban, pick, or veto in time, the bot does it for them so that the
+	match does not stall. The length of a turn is configured per tournament with "TurnTimeout" and
+	the racer is warned "TurnWarning" before it runs out.
+*/
+
+// Make sure that the active racer has a turn timer (and a warning timer) if the race is waiting on
+// them, and that there are no timers left over from previous turns. This is called at the end of
+// every command that changes the draft. Errors are only logged, since a missing timer should never
+// stop the match from continuing.
+func turnSchedule(race *Race) {
+	tournament := tournaments[race.ChallongeURL]
+	waiting := turnIsWaiting(race) && tournament.TurnTimeout > 0
+	key := turnGetKey(race)
+
+	alreadyScheduled := false
+	for _, timer := range schedulerGetAll() {
+		if timer.ChannelID != race.ChannelID ||
+			(timer.Action != TimerActionTurnWarning && timer.Action != TimerActionTurnTimeout) {
+
+			continue
+		}
+		if waiting && timer.Payload == key {
+			alreadyScheduled = true
+			continue
+		}
+		if err := schedulerCancel(timer.ID); err != nil {
+			log.Error("Failed to cancel the turn timer for race \"" + race.Name() + "\": " + err.Error())
+		}
+	}
+	if !waiting || alreadyScheduled {
+		return
+	}
+
+	now := time.Now()
+	if tournament.TurnWarning > 0 && tournament.TurnWarning < tournament.TurnTimeout {
+		datetimeFire := now.Add(tournament.TurnTimeout - tournament.TurnWarning)
+		if _, err := schedulerAdd(TimerActionTurnWarning, race.ChannelID, key, datetimeFire); err != nil {
+			log.Error("Failed to schedule the turn warning for race \"" + race.Name() + "\": " + err.Error())
+		}
+	}
+	if _, err := schedulerAdd(TimerActionTurnTimeout, race.ChannelID, key, now.Add(tournament.TurnTimeout)); err != nil {
+		log.Error("Failed to schedule the turn timeout for race \"" + race.Name() + "\": " + err.Error())
+	}
+}
+
+// Get whether or not the race is waiting on the active racer to do something.
+func turnIsWaiting(race *Race) bool {
+	return race.State == RaceStateBanningCharacters ||
+		race.State == RaceStatePickingCharacters ||
+		race.State == RaceStateVetoCharacters ||
+		race.State == RaceStateBanningBuilds ||
+		race.State == RaceStatePickingBuilds ||
+		race.State == RaceStateVetoBuilds
+}
+
+// Get a string that changes every time that a turn passes. It is stored as the payload of the turn
+// timers so that a timer for a turn that has already been played does nothing.
+func turnGetKey(race *Race) string {
+	values := []string{
+		string(race.State),
+		strconv.Itoa(race.ActiveRacer),
+		strconv.Itoa(len(race.CharactersRemaining)),
+		strconv.Itoa(len(race.Characters)),
+		strconv.Itoa(len(race.BuildsRemaining)),
+		strconv.Itoa(len(race.Builds)),
+		strconv.Itoa(race.Racer1Bans),
+		strconv.Itoa(race.Racer2Bans),
+		strconv.Itoa(race.Racer1Vetos),
+		strconv.Itoa(race.Racer2Vetos),
+		strconv.Itoa(race.NumVoted),
+	}
+
+	return strings.Join(values, "|")
+}
+
+// Re-get the race from the database and return it if the turn that the timer was for is still
+// going on. Otherwise, return nil.
+func turnGetRace(timer *ScheduledTimer) *Race {
+	var race *Race
+	if v, err := getRace(timer.ChannelID); err != nil {
+		log.Error("Failed to re-get the race from the database: " + err.Error())
+		return nil
+	} else {
+		race = v
+	}
+
+	if !turnIsWaiting(race) || turnGetKey(race) != timer.Payload {
+		return nil
+	}
+
+	return race
+}
+
+func turnGetActiveRacer(race *Race) *User {
+	if race.ActiveRacer == 1 {
+		return race.Racer1
+	}
+
+	return race.Racer2
+}
+
+// Get a description of what the active racer needs to do, e.g. "ban a character".
+func turnGetAction(race *Race) string {
+	switch race.State {
+	case RaceStateBanningCharacters:
+		return "ban a character"
+	case RaceStatePickingCharacters:
+		return "pick a character"
+	case RaceStateBanningBuilds:
+		return "ban a build"
+	case RaceStatePickingBuilds:
+		return "pick a build"
+	}
+
+	return "answer with `!yes` or `!no`"
+}
+
+// Get a description of what the bot will do when the turn runs out.
+func turnGetAutoAction(race *Race) string {
+	switch race.State {
+	case RaceStateBanningCharacters:
+		return "a random character will be banned for you"
+	case RaceStatePickingCharacters:
+		return "a random character will be picked for you"
+	case RaceStateBanningBuilds:
+		return "a random build will be banned for you"
+	case RaceStatePickingBuilds:
+		return "a random build will be picked for you"
+	}
+
+	return "it will count as a `!no`"
+}
+
+func turnWarningTimerFired(timer *ScheduledTimer) {
+	race := turnGetRace(timer)
+	if race == nil {
+		return
+	}
+
+	warning := tournaments[race.ChallongeURL].TurnWarning
+	msg := turnGetActiveRacer(race).Mention() + ", you have " + getDurationString(warning) + " left to " + turnGetAction(race) + ". "
+	msg += "If you do not, " + turnGetAutoAction(race) + "."
+	discordSend(race.ChannelID, msg)
+}
+
+func turnTimeoutTimerFired(timer *ScheduledTimer) {
+	race := turnGetRace(timer)
+	if race == nil {
+		return
+	}
+
+	// Find the Discord user of the active racer, so that the action can be performed on their
+	// behalf (in the same way as the force commands).
+	racer := turnGetActiveRacer(race)
+	var members []*discordgo.Member
+	if v, err := getDiscordMembers(); err != nil {
+		log.Error(err.Error())
+		discordSend(race.ChannelID, err.Error())
+		return
+	} else {
+		members = v
+	}
+
+	discordUser := getDiscordUserByID(members, racer.DiscordID)
+	if discordUser == nil {
+		msg := "Failed to find the active racer in the Discord server."
+		log.Error(msg)
+		discordSend(race.ChannelID, msg)
+		return
+	}
+
+	m := &discordgo.MessageCreate{
+		Message: &discordgo.Message{
+			ChannelID: race.ChannelID,
+			Author:    discordUser,
+		},
+	}
+
+	var thingsRemaining []string
+	if race.State == RaceStateBanningCharacters || race.State == RaceStatePickingCharacters {
+		thingsRemaining = race.CharactersRemaining
+	} else if race.State == RaceStateBanningBuilds || race.State == RaceStatePickingBuilds {
+		thingsRemaining = race.BuildsRemaining
+	}
+
+	msg := "Time is up! " + racer.Mention() + " did not " + turnGetAction(race) + " in time, so "
+	switch race.State {
+	case RaceStateBanningCharacters, RaceStateBanningBuilds, RaceStatePickingCharacters, RaceStatePickingBuilds:
+		if len(thingsRemaining) == 0 {
+			log.Error("Failed to make a random choice for race \"" + race.Name() + "\" since there is nothing left to choose from.")
+			return
+		}
+		_, index := getRandomArrayElement(thingsRemaining)
+		args := []string{strconv.Itoa(index + 1)}
+
+		// The action itself will be recorded under the racer's name, so record that the bot did it.
+		if race.State == RaceStateBanningCharacters || race.State == RaceStateBanningBuilds {
+			discordSend(race.ChannelID, msg+"a random ban was made for them.")
+			commandBanWithCause(m, args, &RaceEventCause{
+				Type:  RaceEventTypeAutoBan,
+				Value: args[0],
+			})
+		} else {
+			discordSend(race.ChannelID, msg+"a random pick was made for them.")
+			commandPickWithCause(m, args, &RaceEventCause{
+				Type:  RaceEventTypeAutoPick,
+				Value: args[0],
+			})
+		}
+
+	case RaceStateVetoCharacters, RaceStateVetoBuilds:
+		discordSend(race.ChannelID, msg+"it counts as a `!no`.")
+		commandNoWithCause(m, nil, &RaceEventCause{
+			Type: RaceEventTypeAutoNo,
+		})
+	}
+}
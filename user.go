@@ -14,6 +14,12 @@ type User struct {
 	Timezone       sql.NullString
 	StreamURL      sql.NullString
 	CasterAlwaysOk bool
+
+	// The Glicko-2 rating (see "rating.go").
+	Rating           float64
+	RatingDeviation  float64
+	RatingVolatility float64
+	RatedMatches     int
 }
 
 func (u *User) Mention() string {
@@ -80,8 +86,11 @@ func userGet(u *discordgo.User) (*User, error) {
 
 	// This Discord ID does not exist in the database, so create it.
 	user = &User{
-		DiscordID: u.ID,
-		Username:  u.Username,
+		DiscordID:        u.ID,
+		Username:         u.Username,
+		Rating:           ratingDefault,
+		RatingDeviation:  ratingDeviationDefault,
+		RatingVolatility: ratingVolatilityDefault,
 	}
 	err := modals.Users.Insert(user)
 	return user, err